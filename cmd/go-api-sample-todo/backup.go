@@ -0,0 +1,90 @@
+package main
+
+import (
+	"app/infrastructure"
+	"app/infrastructure/backup"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// passphraseEnv keeps the passphrase out of the process list and shell
+// history.
+const passphraseEnv = "BACKUP_PASSPHRASE"
+
+func runBackup(args []string) (err error) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("o", "", "write the archive to this file instead of stdout")
+	encrypt := fs.Bool("encrypt", false, "encrypt the archive with the passphrase in $"+passphraseEnv)
+	fs.Parse(args)
+
+	passphrase := ""
+	if *encrypt {
+		if passphrase = os.Getenv(passphraseEnv); passphrase == "" {
+			return errors.New("-encrypt needs $" + passphraseEnv)
+		}
+	}
+	d, err := infrastructure.NewDB()
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(*out)
+			}
+		}()
+		w = f
+	}
+	m, err := backup.Backup(d, w, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "backed up %d rows of schema version %d\n", rows(m), m.SchemaVersion)
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := fs.String("i", "", "read the archive from this file instead of stdin")
+	replace := fs.Bool("replace", false, "delete the rows of all tables before restoring")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	d, err := infrastructure.NewDB()
+	if err != nil {
+		return err
+	}
+	m, err := backup.Restore(d, r, os.Getenv(passphraseEnv), *replace)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "restored %d rows of schema version %d taken at %s\n", rows(m), m.SchemaVersion, m.CreatedAt)
+	return nil
+}
+
+func rows(m *backup.Manifest) int {
+	n := 0
+	for _, t := range m.Tables {
+		n += t.Rows
+	}
+	return n
+}
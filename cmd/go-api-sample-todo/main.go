@@ -1,45 +1,332 @@
 package main
 
 import (
+	"app/config"
+	"app/domain/event"
 	"app/handler"
+	"app/handler/davhandler"
+	"app/handler/graphqlhandler"
+	"app/handler/grpchandler"
+	"app/handler/middleware"
+	"app/handler/openapi"
+	"app/handler/wshandler"
 	"app/infrastructure"
 	"app/usecase"
+	"context"
+	"flag"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	appvalidator "app/handler/validator"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
+const eventQueueSize = 256
+
+// maxJSONBodySize bounds the bodies the idempotency middleware buffers on
+// the route groups that only take JSON.
+const maxJSONBodySize = 1 << 20
+
+// commands are run instead of the server when the first argument names
+// one of them.
+var commands = map[string]func(args []string) error{
+	"backup":       runBackup,
+	"restore":      runRestore,
+	"migrate-data": runMigrateData,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Printf("%s failed, err = %s\n", os.Args[1], err.Error())
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	grpcAddr := flag.String("grpc-addr", "", "serve the gRPC API on this address alongside HTTP, e.g. :9090")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("failed to start server. config load failed, err = %s", err.Error())
+		return
+	}
 	d, err := infrastructure.NewDB()
 	if err != nil {
 		fmt.Printf("failed to start server. db setup failed, err = %s", err.Error())
 		return
 	}
-	r := setupRouter(d)
+	todoStream := usecase.NewTodoStream(cfg.SSEReplayBuffer)
+	eventBus := infrastructure.NewEventBus(eventQueueSize)
+	w := newWorkers()
+	r, err := setupRouter(d, cfg, todoStream, eventBus, w)
+	if err != nil {
+		fmt.Printf("failed to start server. router setup failed, err = %s", err.Error())
+		return
+	}
 	if err := appvalidator.SetupValidator(); err != nil {
 		fmt.Printf("failed to start server. validator setup failed, err = %s", err.Error())
 		return
 	}
-	r.Run()
+	srv := &http.Server{
+		Addr:              httpAddr(),
+		Handler:           r,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+		MaxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+	}
+	srv.RegisterOnShutdown(r.todoEvents.Shutdown)
+	srv.RegisterOnShutdown(r.webSocket.Shutdown)
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fmt.Printf("failed to start server. grpc listen failed, err = %s", err.Error())
+			return
+		}
+		grpcServer = setupGRPCServer(d, cfg, todoStream)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				fmt.Printf("grpc server stopped, err = %s\n", err.Error())
+			}
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-served:
+		fmt.Printf("failed to start server. listen failed, err = %s", err.Error())
+		return
+	case <-ctx.Done():
+	}
+	// A second signal kills the process without waiting for the shutdown.
+	stop()
+	fmt.Printf("shutting down, waiting up to %s for in-flight requests\n", cfg.ShutdownGracePeriod)
+	shutdown(cfg.ShutdownGracePeriod, srv, grpcServer, r, w, eventBus, d)
+}
+
+// httpAddr is the address gin's Run listens on: :$PORT, or :8080.
+func httpAddr() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
+
+// shutdown stops the server in the order the work flows: the HTTP and gRPC
+// servers stop taking requests and drain the running ones, then the import
+// jobs they started finish, the background workers stop, the event bus
+// drains its async handlers and the database pool is closed. Requests and
+// import jobs share the grace period; whatever still runs after it is cut
+// off.
+func shutdown(grace time.Duration, srv *http.Server, grpcServer *grpc.Server, r *router, w *workers, eventBus event.Bus, d *gorm.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	if grpcServer != nil {
+		go func() {
+			grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Printf("failed to drain connections, err = %s\n", err.Error())
+		srv.Close()
+	}
+	if grpcServer != nil {
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			// GracefulStop waits for WatchTodos streams, which only end when
+			// the client leaves.
+			grpcServer.Stop()
+			<-grpcStopped
+		}
+	}
+	waitFor(ctx, "import jobs", r.importJobs.Wait)
+	w.stop()
+	eventBus.Close()
+	if sqlDB, err := d.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			fmt.Printf("failed to close db, err = %s\n", err.Error())
+		}
+	}
+}
+
+func waitFor(ctx context.Context, name string, wait func()) {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		fmt.Printf("stopped waiting for %s, err = %s\n", name, ctx.Err().Error())
+	}
 }
 
-func setupRouter(d *gorm.DB) *gin.Engine {
+// router is the HTTP API together with the parts of it that outlive a
+// request and are stopped on shutdown.
+type router struct {
+	*gin.Engine
+	importJobs usecase.ImportJobs
+	todoEvents handler.TodoEvents
+	webSocket  wshandler.WebSocket
+}
+
+// setupRouter also subscribes the change streams to the event bus and
+// starts relaying the outbox to the webhooks and tailing it to the bus on w,
+// so it must be called once per bus.
+func setupRouter(d *gorm.DB, cfg *config.Config, todoStream usecase.TodoStream, eventBus event.Bus, w *workers) (*router, error) {
 	r := gin.Default()
+	openapi.Register(r)
+	r.Use(middleware.Authenticate())
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+
+	todoRepository := infrastructure.NewTodo(d)
+	listRepository := infrastructure.NewList(d)
+	workspaceRepository := infrastructure.NewWorkspace(d)
+	memberRepository := infrastructure.NewMember(d)
+	invitationRepository := infrastructure.NewInvitation(d)
+	idempotencyKeyRepository := infrastructure.NewIdempotencyKey(d)
+	webhookRepository := infrastructure.NewWebhook(d)
+	webhookDeliveryRepository := infrastructure.NewWebhookDelivery(d)
+	transaction := infrastructure.NewTransaction(d)
 
-	repository := infrastructure.NewTodo(d)
-	usecase := usecase.NewTodo(repository)
-	handler := handler.NewTodo(usecase)
+	idempotency := usecase.NewIdempotency(idempotencyKeyRepository, cfg.IdempotencyKeyTTL, cfg.IdempotencyLockTimeout)
+	w.every(time.Hour, "purge idempotency keys", idempotency.DeleteExpired)
 
-	todo := r.Group("/todo")
+	webhookUsecase := usecase.NewWebhook(webhookRepository, webhookDeliveryRepository, memberRepository, infrastructure.NewWebhookSender(cfg.WebhookTimeout, cfg.WebhookAllowPrivateNetworks), cfg.WebhookMaxAttempts)
+	w.every(cfg.WebhookPollInterval, "deliver webhooks", webhookUsecase.DeliverDue)
+	// The relay queues each event for the webhooks once across instances,
+	// while every instance tails the outbox into its own change streams.
+	eventBus.Subscribe(todoStream.Handle)
+	outboxUsecase := usecase.NewOutbox(infrastructure.NewOutbox(d), webhookUsecase.Handle, eventBus, cfg.OutboxRetention)
+	w.every(cfg.OutboxPollInterval, "relay outbox", outboxUsecase.Relay)
+	w.every(cfg.OutboxPollInterval, "tail outbox", outboxUsecase.Tail)
+	w.every(time.Hour, "purge outbox", outboxUsecase.DeletePublished)
+
+	todoUsecase := usecase.NewTodo(todoRepository, infrastructure.NewTodoHistory(d), memberRepository, transaction, todoStream)
+	todoHandler := handler.NewTodo(todoUsecase)
+	todoEventsHandler := handler.NewTodoEvents(todoUsecase, cfg.SSEHeartbeatInterval)
+	todoFileHandler := handler.NewTodoFile(todoUsecase)
+	importJobs := usecase.NewImportJobs(todoUsecase, memberRepository)
+	importJobHandler := handler.NewImportJob(importJobs)
+	listUsecase := usecase.NewList(listRepository, memberRepository, transaction)
+	listHandler := handler.NewList(listUsecase)
+	workspaceHandler := handler.NewWorkspace(usecase.NewWorkspace(workspaceRepository, memberRepository, transaction))
+	invitationHandler := handler.NewInvitation(usecase.NewInvitation(invitationRepository, memberRepository, transaction))
+	webhookHandler := handler.NewWebhook(webhookUsecase)
+
+	timeout := func(group string) gin.HandlerFunc {
+		return middleware.Timeout(cfg.RequestTimeouts[group])
+	}
+	todo := r.Group("/todo", middleware.RateLimit(rateLimitStore, "todo", cfg.RateLimits["todo"]), middleware.Idempotency(idempotency, handler.MaxSourceImportSize))
+	// The event stream lasts until the client leaves, so it has no timeout.
+	todo.GET("/events", todoEventsHandler.Stream)
+	todoFiles := todo.Group("", timeout("todo_files"))
+	{
+		todoFiles.GET("/export.csv", todoFileHandler.ExportCSV)
+		todoFiles.GET("/calendar.ics", todoFileHandler.ExportICalendar)
+		todoFiles.GET("/export", todoFileHandler.Export)
+		todoFiles.POST("/import", todoFileHandler.Import)
+		todoFiles.POST("/import/jobs", importJobHandler.Start)
+	}
+	todos := todo.Group("", timeout("todo"))
+	{
+		todos.POST("", todoHandler.Create)
+		todos.GET("", todoHandler.FindAll)
+		todos.POST("/bulk", todoHandler.Bulk)
+		todos.POST("/actions/set-status", todoHandler.SetStatus)
+		todos.GET("/import/jobs/:id", importJobHandler.Find)
+		todos.GET("/:id", todoHandler.Find)
+		todos.GET("/:id/history", todoHandler.History)
+		todos.GET("/:id/diff", todoHandler.Diff)
+		todos.POST("/:id/revert", todoHandler.Revert)
+		todos.PUT("/:id", todoHandler.Update)
+		todos.PUT("/:id/list", todoHandler.Move)
+		todos.DELETE("/:id", todoHandler.Delete)
+	}
+	lists := r.Group("/lists", middleware.RateLimit(rateLimitStore, "todo", cfg.RateLimits["todo"]), middleware.Idempotency(idempotency, maxJSONBodySize), timeout("todo"))
+	{
+		lists.POST("", listHandler.Create)
+		lists.GET("", listHandler.FindAll)
+		lists.GET("/:id", listHandler.Find)
+		lists.PUT("/:id", listHandler.Update)
+		lists.DELETE("/:id", listHandler.Delete)
+	}
+	r.GET("/activity", middleware.RateLimit(rateLimitStore, "todo", cfg.RateLimits["todo"]), timeout("todo"), todoHandler.Activity)
+	workspaces := r.Group("/workspaces", middleware.RateLimit(rateLimitStore, "workspaces", cfg.RateLimits["workspaces"]), middleware.Idempotency(idempotency, maxJSONBodySize), timeout("workspaces"))
+	{
+		workspaces.POST("", workspaceHandler.Create)
+		workspaces.GET("/:id/members", workspaceHandler.FindMembers)
+		workspaces.PUT("/:id/members/:user_id", workspaceHandler.UpdateMember)
+		workspaces.DELETE("/:id/members/:user_id", workspaceHandler.DeleteMember)
+		workspaces.POST("/:id/invitations", invitationHandler.Create)
+		workspaces.GET("/:id/invitations", invitationHandler.FindAll)
+	}
+	invitations := r.Group("/invitations", middleware.RateLimit(rateLimitStore, "invitations", cfg.RateLimits["invitations"]), middleware.Idempotency(idempotency, maxJSONBodySize), timeout("invitations"))
+	{
+		invitations.POST("/:token/accept", invitationHandler.Accept)
+		invitations.POST("/:token/decline", invitationHandler.Decline)
+		invitations.POST("/:token/revoke", invitationHandler.Revoke)
+	}
+	webhooks := r.Group("/webhooks", middleware.RateLimit(rateLimitStore, "webhooks", cfg.RateLimits["webhooks"]), middleware.Idempotency(idempotency, maxJSONBodySize), timeout("webhooks"))
 	{
-		todo.POST("", handler.Create)
-		todo.GET("", handler.FindAll)
-		todo.GET("/:id", handler.Find)
-		todo.PUT("/:id", handler.Update)
-		todo.DELETE("/:id", handler.Delete)
+		webhooks.POST("", webhookHandler.Create)
+		webhooks.GET("", webhookHandler.FindAll)
+		webhooks.GET("/:id", webhookHandler.Find)
+		webhooks.PUT("/:id", webhookHandler.Update)
+		webhooks.DELETE("/:id", webhookHandler.Delete)
+		webhooks.GET("/:id/deliveries", webhookHandler.FindDeliveries)
+		webhooks.POST("/:id/test", webhookHandler.SendTest)
 	}
-	return r
+	graphqlHandler, err := graphqlhandler.NewGraphQL(todoUsecase, cfg.GraphQLMaxComplexity)
+	if err != nil {
+		return nil, err
+	}
+	r.POST("/graphql", middleware.RateLimit(rateLimitStore, "graphql", cfg.RateLimits["graphql"]), timeout("graphql"), graphqlHandler.Serve)
+	wsHandler := wshandler.NewWebSocket(todoUsecase)
+	r.GET("/ws", middleware.RateLimit(rateLimitStore, "todo", cfg.RateLimits["todo"]), wsHandler.Serve)
+	davHandler := davhandler.NewDAV(todoUsecase, listUsecase, usecase.NewTodoSource(infrastructure.NewTodoSource(d), memberRepository))
+	dav := r.Group(davhandler.Prefix+":workspace_id", middleware.RateLimit(rateLimitStore, "todo", cfg.RateLimits["todo"]), timeout("dav"))
+	{
+		dav.OPTIONS("/", davHandler.Options)
+		dav.Handle("PROPFIND", "/", davHandler.Propfind)
+		for _, calendar := range []string{"/todo/", "/lists/:list_id/"} {
+			for _, path := range []string{calendar, calendar + ":name"} {
+				dav.OPTIONS(path, davHandler.Options)
+				dav.Handle("PROPFIND", path, davHandler.Propfind)
+			}
+			dav.Handle("REPORT", calendar, davHandler.Report)
+			dav.GET(calendar+":name", davHandler.Get)
+			dav.PUT(calendar+":name", davHandler.Put)
+			dav.DELETE(calendar+":name", davHandler.Delete)
+		}
+	}
+	return &router{Engine: r, importJobs: importJobs, todoEvents: todoEventsHandler, webSocket: wsHandler}, nil
+}
+
+func setupGRPCServer(d *gorm.DB, cfg *config.Config, todoStream usecase.TodoStream) *grpc.Server {
+	todo := usecase.NewTodo(infrastructure.NewTodo(d), infrastructure.NewTodoHistory(d), infrastructure.NewMember(d), infrastructure.NewTransaction(d), todoStream)
+	return grpchandler.NewServer(grpchandler.NewTodo(todo))
 }
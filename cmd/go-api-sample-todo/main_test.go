@@ -0,0 +1,76 @@
+package main
+
+import (
+	"app/config"
+	"app/handler/davhandler"
+	"app/handler/openapi"
+	"app/infrastructure"
+	"app/usecase"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// 仕様書に含めないドキュメント自身のルート
+var undocumented = map[string]bool{
+	"GET /openapi.json":       true,
+	"GET /docs":               true,
+	"GET /docs/ui/*filepath":  true,
+	"HEAD /docs/ui/*filepath": true,
+}
+
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize mock DB: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.Dialector{
+		Config: &mysql.Config{DriverName: "mysql", Conn: db, SkipInitializeWithVersion: true},
+	}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to initialize mock DB: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r, err := setupRouter(gormDB, cfg, usecase.NewTodoStream(0), infrastructure.NewEventBus(0), newWorkers())
+	if err != nil {
+		t.Fatalf("Failed to setup router: %v", err)
+	}
+
+	param := regexp.MustCompile(`:([^/]+)`)
+	registered := map[string]bool{}
+	for _, rt := range r.Routes() {
+		key := rt.Method + " " + param.ReplaceAllString(rt.Path, "{$1}")
+		// WebDAV のメソッドは OpenAPI で表現できないため CalDAV のルートは README に記載する
+		if undocumented[rt.Method+" "+rt.Path] || strings.HasPrefix(rt.Path, davhandler.Prefix) {
+			continue
+		}
+		registered[key] = true
+	}
+	documented := map[string]bool{}
+	for path, item := range openapi.NewDocument().Paths {
+		for method := range *item {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for key := range registered {
+		if !documented[key] {
+			t.Errorf("route %s is registered but missing from the OpenAPI document", key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			t.Errorf("route %s is documented but not registered", key)
+		}
+	}
+}
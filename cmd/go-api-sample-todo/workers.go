@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// workers run the background jobs of the server until stop is called.
+type workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newWorkers() *workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workers{ctx: ctx, cancel: cancel}
+}
+
+// every runs job each interval; a zero interval disables it. A run in
+// progress when stop is called is finished rather than cancelled, so a
+// webhook delivery or an outbox batch is not cut off midway.
+func (w *workers) every(interval time.Duration, name string, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		return
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := job(context.Background()); err != nil {
+				fmt.Printf("failed to %s, err = %s\n", name, err.Error())
+			}
+		}
+	}()
+}
+
+// stop stops scheduling runs and waits for the ones in progress.
+func (w *workers) stop() {
+	w.cancel()
+	w.wg.Wait()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkers(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_停止時に実行中のジョブの完了を待つこと", func(t *testing.T) {
+		w := newWorkers()
+		started := make(chan struct{})
+		var finished atomic.Bool
+		w.every(time.Millisecond, "run", func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		})
+		<-started
+		w.stop()
+		if !finished.Load() {
+			t.Errorf("want the running job to finish before stop returns")
+		}
+	})
+	t.Run("正常系_間隔が0のジョブは実行されないこと", func(t *testing.T) {
+		w := newWorkers()
+		var runs atomic.Int32
+		w.every(0, "run", func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		time.Sleep(10 * time.Millisecond)
+		w.stop()
+		if n := runs.Load(); n != 0 {
+			t.Errorf("want = %v, got = %v", 0, n)
+		}
+	})
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	RateLimits map[string]RateLimit
+	// RequestTimeouts bounds the handling of a request per route group; a
+	// zero duration disables the timeout of the group.
+	RequestTimeouts   map[string]time.Duration
+	IdempotencyKeyTTL time.Duration
+	// IdempotencyLockTimeout is how long a key stays reserved for a request
+	// that never completed. It is twice the longest time a request may run,
+	// so that a key is not taken over while its request is still running.
+	IdempotencyLockTimeout time.Duration
+	// GraphQLMaxComplexity rejects queries whose estimated cost is higher.
+	GraphQLMaxComplexity int
+	// SSEReplayBuffer is the number of recent todo events kept for Last-Event-ID resumes.
+	SSEReplayBuffer      int
+	SSEHeartbeatInterval time.Duration
+	// WebhookMaxAttempts is the number of attempts before a delivery is dead-lettered.
+	WebhookMaxAttempts  int
+	WebhookTimeout      time.Duration
+	WebhookPollInterval time.Duration
+	// WebhookAllowPrivateNetworks lets webhooks reach loopback and private
+	// addresses, which are rejected by default.
+	WebhookAllowPrivateNetworks bool
+	// OutboxPollInterval is how often pending domain events are relayed to
+	// the webhooks and new ones tailed to the change streams of this
+	// instance; OutboxRetention is how long published ones are kept.
+	OutboxPollInterval time.Duration
+	OutboxRetention    time.Duration
+	// The Server settings bound how long a client may take to send a
+	// request and read its response, so slow clients cannot hold
+	// connections forever. WriteTimeout must be longer than the longest
+	// request timeout.
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ServerMaxHeaderBytes    int
+	// ShutdownGracePeriod is how long in-flight requests and jobs may run
+	// after SIGINT or SIGTERM before the server stops anyway.
+	ShutdownGracePeriod time.Duration
+}
+
+// RateLimit is a token bucket refilled at RequestsPerMinute that holds at most Burst tokens.
+// A zero RequestsPerMinute disables limiting for the group.
+type RateLimit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+var defaultRateLimits = map[string]RateLimit{
+	"todo":        {RequestsPerMinute: 120, Burst: 20},
+	"workspaces":  {RequestsPerMinute: 60, Burst: 10},
+	"invitations": {RequestsPerMinute: 30, Burst: 5},
+	"graphql":     {RequestsPerMinute: 120, Burst: 20},
+	"webhooks":    {RequestsPerMinute: 60, Burst: 10},
+}
+
+// defaultRequestTimeouts leave room for the exports and imports of a whole
+// workspace in todo_files and for resolving the URL of a webhook, which is
+// checked for private addresses when it is registered, in webhooks.
+var defaultRequestTimeouts = map[string]time.Duration{
+	"todo":        10 * time.Second,
+	"todo_files":  time.Minute,
+	"workspaces":  10 * time.Second,
+	"invitations": 10 * time.Second,
+	"graphql":     15 * time.Second,
+	"webhooks":    30 * time.Second,
+	"dav":         30 * time.Second,
+}
+
+func Load() (*Config, error) {
+	c := &Config{
+		RateLimits:      map[string]RateLimit{},
+		RequestTimeouts: map[string]time.Duration{},
+	}
+	for group, def := range defaultRateLimits {
+		prefix := "RATE_LIMIT_" + strings.ToUpper(group)
+		rpm, err := intEnv(prefix+"_RPM", def.RequestsPerMinute)
+		if err != nil {
+			return nil, err
+		}
+		burst, err := intEnv(prefix+"_BURST", def.Burst)
+		if err != nil {
+			return nil, err
+		}
+		c.RateLimits[group] = RateLimit{RequestsPerMinute: rpm, Burst: burst}
+	}
+	for group, def := range defaultRequestTimeouts {
+		d, err := durationEnv("REQUEST_TIMEOUT_"+strings.ToUpper(group), def)
+		if err != nil {
+			return nil, err
+		}
+		c.RequestTimeouts[group] = d
+	}
+	ttl, err := durationEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	c.IdempotencyKeyTTL = ttl
+	maxComplexity, err := intEnv("GRAPHQL_MAX_COMPLEXITY", 1000)
+	if err != nil {
+		return nil, err
+	}
+	c.GraphQLMaxComplexity = maxComplexity
+	replay, err := intEnv("SSE_REPLAY_BUFFER", 1000)
+	if err != nil {
+		return nil, err
+	}
+	c.SSEReplayBuffer = replay
+	heartbeat, err := durationEnv("SSE_HEARTBEAT_INTERVAL", 15*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.SSEHeartbeatInterval = heartbeat
+	attempts, err := intEnv("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return nil, err
+	}
+	c.WebhookMaxAttempts = attempts
+	timeout, err := durationEnv("WEBHOOK_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.WebhookTimeout = timeout
+	poll, err := durationEnv("WEBHOOK_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.WebhookPollInterval = poll
+	allowPrivate, err := boolEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	if err != nil {
+		return nil, err
+	}
+	c.WebhookAllowPrivateNetworks = allowPrivate
+	relay, err := durationEnv("OUTBOX_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.OutboxPollInterval = relay
+	retention, err := durationEnv("OUTBOX_RETENTION", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	c.OutboxRetention = retention
+	read, err := durationEnv("SERVER_READ_TIMEOUT", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	c.ServerReadTimeout = read
+	readHeader, err := durationEnv("SERVER_READ_HEADER_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.ServerReadHeaderTimeout = readHeader
+	write, err := durationEnv("SERVER_WRITE_TIMEOUT", 90*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.ServerWriteTimeout = write
+	idle, err := durationEnv("SERVER_IDLE_TIMEOUT", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	c.ServerIdleTimeout = idle
+	maxHeaderBytes, err := intEnv("SERVER_MAX_HEADER_BYTES", 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	c.ServerMaxHeaderBytes = maxHeaderBytes
+	grace, err := durationEnv("SHUTDOWN_GRACE_PERIOD", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c.ShutdownGracePeriod = grace
+	c.IdempotencyLockTimeout = 2 * c.longestRequest()
+	if c.IdempotencyLockTimeout == 0 {
+		// Requests may run forever, so keys are only released when they expire.
+		c.IdempotencyLockTimeout = c.IdempotencyKeyTTL
+	}
+	return c, nil
+}
+
+// longestRequest is the longest time a request may run. A group without a
+// request timeout is bounded by the write timeout, and 0 means no bound.
+func (c *Config) longestRequest() time.Duration {
+	var longest time.Duration
+	for _, d := range c.RequestTimeouts {
+		if d == 0 {
+			d = c.ServerWriteTimeout
+			if d == 0 {
+				return 0
+			}
+		}
+		if d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
+
+func intEnv(key string, def int) (int, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return n, nil
+}
+
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return d, nil
+}
+
+func boolEnv(key string, def bool) (bool, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return b, nil
+}
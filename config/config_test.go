@@ -0,0 +1,78 @@
+package config_test
+
+import (
+	"app/config"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("環境変数でレート制限を上書きできること", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_TODO_RPM", "10")
+		t.Setenv("RATE_LIMIT_TODO_BURST", "3")
+
+		c, err := config.Load()
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		want := config.RateLimit{RequestsPerMinute: 10, Burst: 3}
+		if c.RateLimits["todo"] != want {
+			t.Errorf("want = %v, got = %v", want, c.RateLimits["todo"])
+		}
+	})
+	t.Run("環境変数でリクエストのタイムアウトを上書きできること", func(t *testing.T) {
+		t.Setenv("REQUEST_TIMEOUT_TODO_FILES", "0")
+
+		c, err := config.Load()
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if c.RequestTimeouts["todo_files"] != 0 {
+			t.Errorf("want = %v, got = %v", 0, c.RequestTimeouts["todo_files"])
+		}
+		if c.RequestTimeouts["todo"] != 10*time.Second {
+			t.Errorf("want = %v, got = %v", 10*time.Second, c.RequestTimeouts["todo"])
+		}
+	})
+	t.Run("冪等キーの予約期限が最も長いリクエストのタイムアウトの2倍になること", func(t *testing.T) {
+		t.Setenv("REQUEST_TIMEOUT_TODO_FILES", "2m")
+
+		c, err := config.Load()
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if c.IdempotencyLockTimeout != 4*time.Minute {
+			t.Errorf("want = %v, got = %v", 4*time.Minute, c.IdempotencyLockTimeout)
+		}
+	})
+	t.Run("タイムアウトのないグループは書き込みのタイムアウトで冪等キーの予約期限が決まること", func(t *testing.T) {
+		t.Setenv("REQUEST_TIMEOUT_TODO_FILES", "0")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "5m")
+
+		c, err := config.Load()
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if c.IdempotencyLockTimeout != 10*time.Minute {
+			t.Errorf("want = %v, got = %v", 10*time.Minute, c.IdempotencyLockTimeout)
+		}
+	})
+	t.Run("環境変数でプライベートネットワークへのWebhookを許可できること", func(t *testing.T) {
+		t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+
+		c, err := config.Load()
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if !c.WebhookAllowPrivateNetworks {
+			t.Errorf("want = %v, got = %v", true, c.WebhookAllowPrivateNetworks)
+		}
+	})
+	t.Run("不正な値の場合エラーになること", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_TODO_RPM", "many")
+
+		if _, err := config.Load(); err == nil {
+			t.Errorf("want error, got = %v", err)
+		}
+	})
+}
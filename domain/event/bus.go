@@ -0,0 +1,22 @@
+package event
+
+import "context"
+
+type Handler func(ctx context.Context, e Event) error
+
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Bus dispatches events to subscribers in subscription order. Sync handlers
+// run inside Publish and their errors are returned to the publisher; async
+// handlers run on their own goroutine, outside the context of the
+// publisher, and cannot fail it.
+type Bus interface {
+	Publisher
+	Subscribe(h Handler)
+	SubscribeAsync(name string, h Handler)
+	// Close stops accepting events and waits until the async handlers have
+	// drained their queues.
+	Close()
+}
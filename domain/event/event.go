@@ -0,0 +1,91 @@
+package event
+
+import (
+	"app/domain/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Event interface {
+	Name() string
+	WorkspaceID() int
+	OccurredAt() time.Time
+}
+
+const (
+	TodoCreatedName       = "todo.created"
+	TodoUpdatedName       = "todo.updated"
+	TodoStatusChangedName = "todo.status_changed"
+	TodoDeletedName       = "todo.deleted"
+)
+
+type TodoCreated struct {
+	Actor model.Actor
+	Todo  model.Todo
+	At    time.Time
+}
+
+func (e TodoCreated) Name() string          { return TodoCreatedName }
+func (e TodoCreated) WorkspaceID() int      { return e.Todo.WorkspaceID }
+func (e TodoCreated) OccurredAt() time.Time { return e.At }
+
+type TodoUpdated struct {
+	Actor  model.Actor
+	Before model.Todo
+	After  model.Todo
+	At     time.Time
+}
+
+func (e TodoUpdated) Name() string          { return TodoUpdatedName }
+func (e TodoUpdated) WorkspaceID() int      { return e.After.WorkspaceID }
+func (e TodoUpdated) OccurredAt() time.Time { return e.At }
+
+// TodoStatusChanged is raised alongside TodoUpdated when the status differs.
+type TodoStatusChanged struct {
+	Actor model.Actor
+	Todo  model.Todo
+	From  model.TaskStatus
+	To    model.TaskStatus
+	At    time.Time
+}
+
+func (e TodoStatusChanged) Name() string          { return TodoStatusChangedName }
+func (e TodoStatusChanged) WorkspaceID() int      { return e.Todo.WorkspaceID }
+func (e TodoStatusChanged) OccurredAt() time.Time { return e.At }
+
+type TodoDeleted struct {
+	Actor model.Actor
+	Todo  model.Todo
+	At    time.Time
+}
+
+func (e TodoDeleted) Name() string          { return TodoDeletedName }
+func (e TodoDeleted) WorkspaceID() int      { return e.Todo.WorkspaceID }
+func (e TodoDeleted) OccurredAt() time.Time { return e.At }
+
+var ErrUnknownEvent = errors.New("unknown event")
+
+// Decode restores an event from the JSON stored under its name.
+func Decode(name string, payload []byte) (Event, error) {
+	switch name {
+	case TodoCreatedName:
+		return decode[TodoCreated](payload)
+	case TodoUpdatedName:
+		return decode[TodoUpdated](payload)
+	case TodoStatusChangedName:
+		return decode[TodoStatusChanged](payload)
+	case TodoDeletedName:
+		return decode[TodoDeleted](payload)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, name)
+}
+
+func decode[T Event](payload []byte) (Event, error) {
+	var e T
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	ID                  int `gorm:"primaryKey"`
+	UserID              int
+	Key                 string
+	Fingerprint         string
+	Status              IdempotencyStatus
+	ResponseCode        int
+	ResponseContentType string
+	ResponseBody        []byte
+	ExpiresAt           time.Time
+	CreatedAt           time.Time `gorm:"<-:false"`
+	UpdatedAt           time.Time `gorm:"<-:false"`
+}
+
+func NewIdempotencyKey(userID int, key string, fingerprint string, expiresAt time.Time) *IdempotencyKey {
+	return &IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      InFlight,
+		ExpiresAt:   expiresAt,
+	}
+}
+
+func (k *IdempotencyKey) Expired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+type IdempotencyStatus string
+
+const (
+	InFlight  = IdempotencyStatus("in_flight")
+	Completed = IdempotencyStatus("completed")
+)
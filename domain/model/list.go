@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// List groups todos of a workspace. A todo belongs to at most one list;
+// ListID 0 means it is in none.
+type List struct {
+	ID          int `gorm:"primaryKey"`
+	WorkspaceID int
+	Name        string
+	CreatedAt   time.Time `gorm:"<-:false"`
+	UpdatedAt   time.Time `gorm:"<-:false"`
+}
+
+func NewList(workspaceID int, name string) *List {
+	return &List{
+		WorkspaceID: workspaceID,
+		Name:        name,
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox is a domain event stored in the same transaction as the change that
+// raised it, waiting to be published by the relay.
+type Outbox struct {
+	ID          int `gorm:"primaryKey"`
+	WorkspaceID int
+	Name        string
+	Payload     json.RawMessage
+	PublishedAt *time.Time
+	CreatedAt   time.Time `gorm:"<-:false"`
+}
+
+func NewOutbox(workspaceID int, name string, payload []byte) *Outbox {
+	return &Outbox{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Payload:     payload,
+	}
+}
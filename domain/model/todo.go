@@ -3,24 +3,29 @@ package model
 import "time"
 
 type Todo struct {
-	ID        int `gorm:"primaryKey"`
-	Task      string
-	Status    TaskStatus
-	CreatedAt time.Time `gorm:"<-:false"`
-	UpdatedAt time.Time `gorm:"<-:false"`
+	ID          int `gorm:"primaryKey"`
+	WorkspaceID int
+	ListID      int
+	Task        string
+	Status      TaskStatus
+	CreatedAt   time.Time `gorm:"<-:false"`
+	UpdatedAt   time.Time `gorm:"<-:false"`
 }
 
-func NewTodo(task string) *Todo {
+func NewTodo(workspaceID int, listID int, task string) *Todo {
 	return &Todo{
-		Task:   task,
-		Status: Created,
+		WorkspaceID: workspaceID,
+		ListID:      listID,
+		Task:        task,
+		Status:      Created,
 	}
 }
-func NewUpdateTodo(id int, task string, status TaskStatus) *Todo {
+func NewUpdateTodo(id int, workspaceID int, task string, status TaskStatus) *Todo {
 	return &Todo{
-		ID:     id,
-		Task:   task,
-		Status: status,
+		ID:          id,
+		WorkspaceID: workspaceID,
+		Task:        task,
+		Status:      status,
 	}
 }
 
@@ -37,3 +42,18 @@ var TaskStatusMap = map[TaskStatus]bool{
 	Processing: true,
 	Done:       true,
 }
+
+// TodoFilter narrows down the todos of a workspace. Zero values mean "any".
+// CreatedFrom is inclusive and CreatedTo is exclusive. AfterID keeps the
+// todos with a higher ID, which pages through them without skipping or
+// repeating any when todos are added or deleted in between.
+type TodoFilter struct {
+	Status      TaskStatus
+	ListID      int
+	IDs         []int
+	AfterID     int
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Limit       int
+	Offset      int
+}
@@ -0,0 +1,221 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TodoHistory is an immutable record of one change to a todo.
+type TodoHistory struct {
+	ID          int `gorm:"primaryKey"`
+	TodoID      int
+	WorkspaceID int
+	UserID      int
+	Action      TodoAction
+	Changes     FieldChanges
+	OccurredAt  time.Time
+}
+
+// NewTodoHistory records the change from before to after. before is nil for
+// a created todo and after is nil for a deleted one.
+func NewTodoHistory(actor Actor, action TodoAction, before *Todo, after *Todo, at time.Time) *TodoHistory {
+	id := 0
+	if after != nil {
+		id = after.ID
+	} else if before != nil {
+		id = before.ID
+	}
+	return &TodoHistory{
+		TodoID:      id,
+		WorkspaceID: actor.WorkspaceID,
+		UserID:      actor.UserID,
+		Action:      action,
+		Changes:     DiffTodo(before, after),
+		OccurredAt:  at,
+	}
+}
+
+// DiffTodo returns the fields that differ between a and b. A nil todo has
+// empty fields.
+func DiffTodo(a *Todo, b *Todo) FieldChanges {
+	var old, cur Todo
+	if a != nil {
+		old = *a
+	}
+	if b != nil {
+		cur = *b
+	}
+	var changes FieldChanges
+	if old.Task != cur.Task {
+		changes = append(changes, FieldChange{Field: "task", Old: old.Task, New: cur.Task})
+	}
+	if old.Status != cur.Status {
+		changes = append(changes, FieldChange{Field: "status", Old: string(old.Status), New: string(cur.Status)})
+	}
+	if old.ListID != cur.ListID {
+		changes = append(changes, FieldChange{Field: "list_id", Old: listIDString(old.ListID), New: listIDString(cur.ListID)})
+	}
+	return changes
+}
+
+// ReplayTodoHistory rebuilds a todo from its history, oldest first. It
+// returns nil when the todo did not exist after the last entry.
+func ReplayTodoHistory(histories []*TodoHistory) *Todo {
+	var todo *Todo
+	for _, h := range histories {
+		switch h.Action {
+		case TodoActionCreated:
+			todo = &Todo{ID: h.TodoID, WorkspaceID: h.WorkspaceID, CreatedAt: h.OccurredAt}
+		case TodoActionDeleted:
+			todo = nil
+			continue
+		}
+		if todo == nil {
+			continue
+		}
+		for _, c := range h.Changes {
+			switch c.Field {
+			case "task":
+				todo.Task = c.New
+			case "status":
+				todo.Status = TaskStatus(c.New)
+			case "list_id":
+				todo.ListID, _ = strconv.Atoi(c.New)
+			}
+		}
+		todo.UpdatedAt = h.OccurredAt
+	}
+	return todo
+}
+
+// SeedTodoHistory prepends a created entry to the history of a todo that
+// was created before its changes were recorded. The entry is rebuilt by
+// undoing the history on current, the todo as it is now or nil when it no
+// longer exists. It has no ID, so it cannot be reverted to or diffed.
+func SeedTodoHistory(histories []*TodoHistory, current *Todo) []*TodoHistory {
+	if len(histories) > 0 && histories[0].Action == TodoActionCreated {
+		return histories
+	}
+	if len(histories) == 0 && current == nil {
+		return histories
+	}
+	var todo Todo
+	var createdAt time.Time
+	if current != nil {
+		todo = *current
+		createdAt = current.CreatedAt
+	} else {
+		// A deleted todo was created some time before its first change.
+		createdAt = histories[0].OccurredAt
+	}
+	for i := len(histories) - 1; i >= 0; i-- {
+		h := histories[i]
+		if h.Action == TodoActionDeleted {
+			// The values before a delete are the whole todo.
+			todo = Todo{}
+		}
+		todo.ID, todo.WorkspaceID = h.TodoID, h.WorkspaceID
+		for _, c := range h.Changes {
+			switch c.Field {
+			case "task":
+				todo.Task = c.Old
+			case "status":
+				todo.Status = TaskStatus(c.Old)
+			case "list_id":
+				todo.ListID, _ = strconv.Atoi(c.Old)
+			}
+		}
+	}
+	seed := &TodoHistory{
+		TodoID:      todo.ID,
+		WorkspaceID: todo.WorkspaceID,
+		Action:      TodoActionCreated,
+		Changes:     DiffTodo(nil, &todo),
+		OccurredAt:  createdAt,
+	}
+	return append([]*TodoHistory{seed}, histories...)
+}
+
+// listIDString leaves the value of a todo outside any list empty, like the
+// values of a todo that does not exist.
+func listIDString(id int) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+// TodoDiff is the field-level difference between two revisions of a todo,
+// where a revision is the ID of a history entry.
+type TodoDiff struct {
+	From    int
+	To      int
+	Changes FieldChanges
+}
+
+type TodoAction string
+
+const (
+	TodoActionCreated = TodoAction("created")
+	TodoActionUpdated = TodoAction("updated")
+	TodoActionDeleted = TodoAction("deleted")
+)
+
+var TodoActionMap = map[TodoAction]bool{
+	TodoActionCreated: true,
+	TodoActionUpdated: true,
+	TodoActionDeleted: true,
+}
+
+// FieldChange holds the old and new value of a changed field. Values are
+// empty before creation and after deletion.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// FieldChanges is stored as a JSON array.
+type FieldChanges []FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		c = FieldChanges{}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (c *FieldChanges) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	case nil:
+		*c = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for FieldChanges: %T", src)
+	}
+	return json.Unmarshal(b, c)
+}
+
+// ActivityFilter narrows down the history of a workspace. Zero values mean "any".
+// AfterID keeps the entries recorded after the one with that ID.
+type ActivityFilter struct {
+	UserID  int
+	AfterID int
+	Action  TodoAction
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Offset  int
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// TodoSource links a todo to the item it was imported from, so importing the
+// same export again skips it. SourceID is prefixed with the app, such as
+// "trello:<card id>". The link is kept when the todo is deleted.
+type TodoSource struct {
+	ID          int `gorm:"primaryKey"`
+	WorkspaceID int
+	SourceID    string
+	TodoID      int
+	CreatedAt   time.Time `gorm:"<-:false"`
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Webhook struct {
+	ID          int `gorm:"primaryKey"`
+	WorkspaceID int
+	URL         string
+	Events      WebhookEvents
+	Secret      string `json:"-"`
+	Active      bool
+	CreatedAt   time.Time `gorm:"<-:false"`
+	UpdatedAt   time.Time `gorm:"<-:false"`
+}
+
+func NewWebhook(workspaceID int, url string, events WebhookEvents, secret string) *Webhook {
+	return &Webhook{
+		WorkspaceID: workspaceID,
+		URL:         url,
+		Events:      events,
+		Secret:      secret,
+		Active:      true,
+	}
+}
+
+// Subscribes reports whether deliveries of the event should be sent. Ping
+// events are sent to every webhook.
+func (w *Webhook) Subscribes(e WebhookEvent) bool {
+	if !w.Active {
+		return false
+	}
+	if e == Ping {
+		return true
+	}
+	for _, s := range w.Events {
+		if s == e {
+			return true
+		}
+	}
+	return false
+}
+
+// nonPublicPrefixes are the special purpose ranges that IsGlobalUnicast and
+// IsPrivate do not cover. NAT64 and 6to4 are included because they embed an
+// IPv4 address that may be private.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// IsPublicAddress reports whether webhooks may be delivered to the address.
+// Loopback, private, link-local and other special purpose addresses are
+// rejected so that a webhook cannot reach the server's own network.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+type WebhookEvent string
+
+const (
+	TodoCreatedEvent       = WebhookEvent("todo.created")
+	TodoUpdatedEvent       = WebhookEvent("todo.updated")
+	TodoStatusChangedEvent = WebhookEvent("todo.status_changed")
+	TodoDeletedEvent       = WebhookEvent("todo.deleted")
+	Ping                   = WebhookEvent("ping")
+)
+
+var WebhookEventMap = map[WebhookEvent]bool{
+	TodoCreatedEvent:       true,
+	TodoUpdatedEvent:       true,
+	TodoStatusChangedEvent: true,
+	TodoDeletedEvent:       true,
+}
+
+// WebhookEvents is stored as a comma separated list.
+type WebhookEvents []WebhookEvent
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	s := make([]string, len(e))
+	for i, v := range e {
+		s[i] = string(v)
+	}
+	return strings.Join(s, ","), nil
+}
+
+func (e *WebhookEvents) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("unsupported type for WebhookEvents: %T", src)
+	}
+	*e = nil
+	for _, v := range strings.Split(s, ",") {
+		if v != "" {
+			*e = append(*e, WebhookEvent(v))
+		}
+	}
+	return nil
+}
+
+type WebhookDelivery struct {
+	ID        int `gorm:"primaryKey"`
+	WebhookID int
+	// EventID is the outbox message the delivery was queued for; pings
+	// have none.
+	EventID        *int
+	Event          WebhookEvent
+	Payload        json.RawMessage
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time `gorm:"<-:false"`
+	UpdatedAt      time.Time `gorm:"<-:false"`
+}
+
+func NewWebhookDelivery(webhookID int, event WebhookEvent, payload []byte, now time.Time) *WebhookDelivery {
+	return &WebhookDelivery{
+		WebhookID:     webhookID,
+		Event:         event,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+	}
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   = DeliveryStatus("pending")
+	DeliverySucceeded = DeliveryStatus("succeeded")
+	DeliveryDead      = DeliveryStatus("dead")
+)
+
+// WebhookSignature is the hex encoded HMAC-SHA256 of "<timestamp>.<body>".
+// Receivers recompute it with the shared secret and reject stale timestamps.
+func WebhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package model
+
+import "time"
+
+type Workspace struct {
+	ID        int `gorm:"primaryKey"`
+	Name      string
+	CreatedAt time.Time `gorm:"<-:false"`
+	UpdatedAt time.Time `gorm:"<-:false"`
+}
+
+func NewWorkspace(name string) *Workspace {
+	return &Workspace{
+		Name: name,
+	}
+}
+
+// Actor is the authenticated user acting inside a workspace.
+type Actor struct {
+	UserID      int
+	WorkspaceID int
+}
+
+func NewActor(userID int, workspaceID int) Actor {
+	return Actor{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+	}
+}
+
+type Member struct {
+	WorkspaceID int `gorm:"primaryKey"`
+	UserID      int `gorm:"primaryKey"`
+	Role        Role
+	CreatedAt   time.Time `gorm:"<-:false"`
+	UpdatedAt   time.Time `gorm:"<-:false"`
+}
+
+func NewMember(workspaceID int, userID int, role Role) *Member {
+	return &Member{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+	}
+}
+
+type Role string
+
+const (
+	Owner  = Role("owner")
+	Admin  = Role("admin")
+	Editor = Role("editor")
+	Viewer = Role("viewer")
+)
+
+var RoleMap = map[Role]bool{
+	Owner:  true,
+	Admin:  true,
+	Editor: true,
+	Viewer: true,
+}
+
+type Permission string
+
+const (
+	ReadTodo      = Permission("read_todo")
+	WriteTodo     = Permission("write_todo")
+	ManageMembers = Permission("manage_members")
+	ManageOwners  = Permission("manage_owners")
+	ManageHooks   = Permission("manage_webhooks")
+)
+
+var rolePermissions = map[Role]map[Permission]bool{
+	Owner: {
+		ReadTodo:      true,
+		WriteTodo:     true,
+		ManageMembers: true,
+		ManageOwners:  true,
+		ManageHooks:   true,
+	},
+	Admin: {
+		ReadTodo:      true,
+		WriteTodo:     true,
+		ManageMembers: true,
+		ManageHooks:   true,
+	},
+	Editor: {
+		ReadTodo:  true,
+		WriteTodo: true,
+	},
+	Viewer: {
+		ReadTodo: true,
+	},
+}
+
+func (r Role) Can(p Permission) bool {
+	return rolePermissions[r][p]
+}
+
+type Invitation struct {
+	ID          int `gorm:"primaryKey"`
+	WorkspaceID int
+	Token       string
+	Role        Role
+	Status      InvitationStatus
+	InvitedBy   int
+	ExpiresAt   time.Time
+	CreatedAt   time.Time `gorm:"<-:false"`
+	UpdatedAt   time.Time `gorm:"<-:false"`
+}
+
+func NewInvitation(workspaceID int, token string, role Role, invitedBy int, expiresAt time.Time) *Invitation {
+	return &Invitation{
+		WorkspaceID: workspaceID,
+		Token:       token,
+		Role:        role,
+		Status:      Pending,
+		InvitedBy:   invitedBy,
+		ExpiresAt:   expiresAt,
+	}
+}
+
+func (i *Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+type InvitationStatus string
+
+const (
+	Pending  = InvitationStatus("pending")
+	Accepted = InvitationStatus("accepted")
+	Declined = InvitationStatus("declined")
+	Revoked  = InvitationStatus("revoked")
+)
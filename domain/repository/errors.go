@@ -0,0 +1,6 @@
+package repository
+
+import "errors"
+
+// ErrDuplicate is returned when a unique constraint rejects a write.
+var ErrDuplicate = errors.New("duplicate")
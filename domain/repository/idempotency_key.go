@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"app/domain/model"
+	"context"
+	"time"
+)
+
+type IdempotencyKey interface {
+	Create(ctx context.Context, k *model.IdempotencyKey) error
+	// Complete stores the response of k and reports whether k was still
+	// reserved. A key taken over by another request after its lock timed
+	// out is a new row and is left alone.
+	Complete(ctx context.Context, k *model.IdempotencyKey) (bool, error)
+	Delete(ctx context.Context, id int) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+	Find(ctx context.Context, userID int, key string) (*model.IdempotencyKey, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"app/domain/model"
+	"context"
+)
+
+type List interface {
+	Create(ctx context.Context, l *model.List) error
+	Update(ctx context.Context, l *model.List) error
+	Delete(ctx context.Context, id int) error
+	Find(ctx context.Context, id int) (*model.List, error)
+	// FindForUpdate locks the list until the transaction ends, so that a
+	// todo is not added to a list that is being deleted.
+	FindForUpdate(ctx context.Context, id int) (*model.List, error)
+	FindAll(ctx context.Context, workspaceID int) ([]*model.List, error)
+}
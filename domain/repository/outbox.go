@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"context"
+	"time"
+)
+
+type Outbox interface {
+	// Store adds the events to the outbox. It is called in the transaction of
+	// the change that raised them.
+	Store(ctx context.Context, events ...event.Event) error
+	// Relay locks up to limit unpublished messages in id order and passes them
+	// to publish, which returns how many of them, from the first, were
+	// published. Those are marked as published in the same transaction, so
+	// another instance relaying at the same time waits for the lock instead
+	// of sending them again.
+	Relay(ctx context.Context, limit int, now time.Time, publish func(messages []*model.Outbox) (int, error)) (int, error)
+	// FindAfter returns up to limit messages with an ID above id in id
+	// order, published or not. It takes no locks.
+	FindAfter(ctx context.Context, id int, limit int) ([]*model.Outbox, error)
+	// LastID returns the highest message ID, or 0 when the outbox is empty.
+	LastID(ctx context.Context) (int, error)
+	DeletePublishedBefore(ctx context.Context, t time.Time) error
+}
@@ -1,11 +1,25 @@
 package repository
 
-import "app/domain/model"
+import (
+	"app/domain/model"
+	"context"
+)
 
 type Todo interface {
-	Create(t *model.Todo) error
-	Delete(id int) error
-	Update(t *model.Todo) error
-	Find(id int) (*model.Todo, error)
-	FindAll() ([]*model.Todo, error)
+	Create(ctx context.Context, t *model.Todo) error
+	// CreateBatch inserts the todos with multi-row inserts and sets their IDs.
+	CreateBatch(ctx context.Context, todos []*model.Todo) error
+	Delete(ctx context.Context, id int) error
+	Update(ctx context.Context, t *model.Todo) error
+	Find(ctx context.Context, id int) (*model.Todo, error)
+	// FindForUpdate, FindByIDsForUpdate and SearchForUpdate lock the todos
+	// until the transaction ends, so that a change is based on the latest
+	// version of the todo and its history.
+	FindForUpdate(ctx context.Context, id int) (*model.Todo, error)
+	FindAll(ctx context.Context, workspaceID int) ([]*model.Todo, error)
+	FindByIDs(ctx context.Context, ids []int) ([]*model.Todo, error)
+	FindByIDsForUpdate(ctx context.Context, ids []int) ([]*model.Todo, error)
+	Search(ctx context.Context, workspaceID int, f model.TodoFilter) ([]*model.Todo, error)
+	SearchForUpdate(ctx context.Context, workspaceID int, f model.TodoFilter) ([]*model.Todo, error)
+	Count(ctx context.Context, workspaceID int, f model.TodoFilter) (int64, error)
 }
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"app/domain/model"
+	"context"
+)
+
+type TodoHistory interface {
+	Create(ctx context.Context, histories ...*model.TodoHistory) error
+	// FindAll returns the history of a todo, oldest first.
+	FindAll(ctx context.Context, workspaceID int, todoID int, limit int, offset int) ([]*model.TodoHistory, error)
+	// Revisions returns the whole history of a todo, oldest first.
+	Revisions(ctx context.Context, workspaceID int, todoID int) ([]*model.TodoHistory, error)
+	// RevisionsForUpdate also locks the history of the todo until the
+	// transaction ends, so that no change is recorded in between.
+	RevisionsForUpdate(ctx context.Context, workspaceID int, todoID int) ([]*model.TodoHistory, error)
+	// Search returns the history of a workspace, newest first.
+	Search(ctx context.Context, workspaceID int, f model.ActivityFilter) ([]*model.TodoHistory, error)
+}
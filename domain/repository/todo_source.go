@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"app/domain/model"
+	"context"
+)
+
+type TodoSource interface {
+	// Create returns ErrDuplicate when a source ID is already linked in the
+	// workspace.
+	Create(ctx context.Context, sources ...*model.TodoSource) error
+	// Relink points the link to s.TodoID if it still points to from, and
+	// reports whether it did.
+	Relink(ctx context.Context, s *model.TodoSource, from int) (bool, error)
+	FindAll(ctx context.Context, workspaceID int, sourceIDs []string) ([]*model.TodoSource, error)
+	// FindByTodoIDs returns the links of the todos, also of deleted ones.
+	FindByTodoIDs(ctx context.Context, workspaceID int, todoIDs []int) ([]*model.TodoSource, error)
+}
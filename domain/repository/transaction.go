@@ -0,0 +1,25 @@
+package repository
+
+import "context"
+
+// Repositories are bound to the transaction they were handed out by.
+type Repositories struct {
+	// Transaction runs a nested transaction on a savepoint of this one.
+	Transaction Transaction
+	Todo        Todo
+	List        List
+	TodoHistory TodoHistory
+	TodoSource  TodoSource
+	Outbox      Outbox
+	Workspace   Workspace
+	Member      Member
+	Invitation  Invitation
+	Webhook     Webhook
+}
+
+type Transaction interface {
+	// Do runs fn with repositories bound to one transaction. The transaction
+	// is committed when fn returns nil and rolled back when fn returns an
+	// error or panics; the panic is propagated after the rollback.
+	Do(ctx context.Context, fn func(r Repositories) error) error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"app/domain/model"
+	"context"
+	"time"
+)
+
+type Webhook interface {
+	Create(ctx context.Context, w *model.Webhook) error
+	Update(ctx context.Context, w *model.Webhook) error
+	Delete(ctx context.Context, id int) error
+	Find(ctx context.Context, id int) (*model.Webhook, error)
+	FindAll(ctx context.Context, workspaceID int) ([]*model.Webhook, error)
+}
+
+type WebhookDelivery interface {
+	// Create returns ErrDuplicate when a delivery of the same event to the
+	// webhook was queued before.
+	Create(ctx context.Context, d *model.WebhookDelivery) error
+	Update(ctx context.Context, d *model.WebhookDelivery) error
+	// ClaimDue locks the next delivery whose attempt is due at now, moves
+	// its next attempt to until and returns it, or nil when none is due.
+	// Another instance skips the claimed delivery until then, and picks it
+	// up again if this one never reports the attempt.
+	ClaimDue(ctx context.Context, now time.Time, until time.Time) (*model.WebhookDelivery, error)
+	FindAll(ctx context.Context, webhookID int, limit int, offset int) ([]*model.WebhookDelivery, error)
+}
+
+// WebhookSender posts a signed delivery to the webhook's URL and returns the
+// status code of the response. Check reports an error when the URL does not
+// resolve to addresses the sender is allowed to deliver to.
+type WebhookSender interface {
+	Check(ctx context.Context, url string) error
+	Send(ctx context.Context, w *model.Webhook, d *model.WebhookDelivery, now time.Time) (int, error)
+}
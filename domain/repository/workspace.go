@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"app/domain/model"
+	"context"
+)
+
+type Workspace interface {
+	Create(ctx context.Context, w *model.Workspace) error
+	Find(ctx context.Context, id int) (*model.Workspace, error)
+}
+
+type Member interface {
+	Create(ctx context.Context, m *model.Member) error
+	Update(ctx context.Context, m *model.Member) error
+	Delete(ctx context.Context, workspaceID int, userID int) error
+	Find(ctx context.Context, workspaceID int, userID int) (*model.Member, error)
+	FindAll(ctx context.Context, workspaceID int) ([]*model.Member, error)
+}
+
+type Invitation interface {
+	Create(ctx context.Context, i *model.Invitation) error
+	// UpdateStatus stores the status of i only if the stored status is
+	// still from, and reports whether it did.
+	UpdateStatus(ctx context.Context, i *model.Invitation, from model.InvitationStatus) (bool, error)
+	FindByToken(ctx context.Context, token string) (*model.Invitation, error)
+	FindAll(ctx context.Context, workspaceID int) ([]*model.Invitation, error)
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"app/domain/model"
+	"app/handler/middleware"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceIDHeader selects the workspace a /todo request operates on.
+const WorkspaceIDHeader = middleware.WorkspaceIDHeader
+
+func bindActor(c *gin.Context) (model.Actor, bool) {
+	workspaceID, err := strconv.Atoi(c.GetHeader(WorkspaceIDHeader))
+	if err != nil || workspaceID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing or invalid " + WorkspaceIDHeader + " header"})
+		return model.Actor{}, false
+	}
+	return model.NewActor(middleware.UserID(c), workspaceID), true
+}
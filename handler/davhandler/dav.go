@@ -0,0 +1,837 @@
+package davhandler
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/todoformat"
+	"app/usecase"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// Prefix is the path the workspaces are served under. Each workspace is
+	// its own principal and calendar home, since there is no way to list the
+	// workspaces of a user. It holds a "todo" calendar of all its todos and
+	// a calendar per list under "lists/".
+	Prefix = "/dav/workspaces/"
+
+	maxResourceSize = 1 << 20
+	// nameSourcePrefix and uidSourcePrefix prefix the todo source IDs that
+	// keep the resource name and the VTODO UID a client created a todo with.
+	nameSourcePrefix  = "caldav:"
+	uidSourcePrefix   = "ical:"
+	maxSourceIDLength = 255
+	// maxSyncChanges is the most history entries a sync-collection report
+	// reads; a client that is further behind is told to sync from scratch.
+	maxSyncChanges  = 1000
+	syncTokenPrefix = "http://go-api-sample-todo/ns/sync/"
+
+	xmlContentType      = "application/xml; charset=utf-8"
+	calendarContentType = "text/calendar; charset=utf-8"
+)
+
+// DAV serves a CalDAV subset over the todos of a workspace: PROPFIND,
+// calendar-query, calendar-multiget and sync-collection reports, and GET,
+// PUT and DELETE of single VTODO resources. Writes go through usecase.Todo,
+// so they are authorized, recorded and published like any other change.
+// Todos created by a client keep its resource name and UID; the others are
+// named after their ID.
+type DAV interface {
+	Options(c *gin.Context)
+	Propfind(c *gin.Context)
+	Report(c *gin.Context)
+	Get(c *gin.Context)
+	Put(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type davHandler struct {
+	usecase usecase.Todo
+	lists   usecase.List
+	sources usecase.TodoSource
+}
+
+func NewDAV(u usecase.Todo, l usecase.List, s usecase.TodoSource) DAV {
+	return &davHandler{u, l, s}
+}
+
+func (h *davHandler) Options(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	c.Status(http.StatusOK)
+}
+
+func (h *davHandler) Propfind(c *gin.Context) {
+	col, ok := h.bindCollection(c)
+	if !ok {
+		return
+	}
+	actor := col.actor
+	var req propfindRequest
+	if !decode(c, &req) {
+		return
+	}
+	// The principal and the calendar are only one level apart, so a depth
+	// of infinity is served as 1.
+	depth := 1
+	if c.GetHeader("Depth") == "0" {
+		depth = 0
+	}
+	token, err := h.syncToken(c.Request.Context(), actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	var resources []resource
+	switch {
+	case c.Param("name") != "":
+		todo, ok := h.find(c, col)
+		if !ok {
+			return
+		}
+		if todo == nil {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		links, err := h.links(c.Request.Context(), actor, []*model.Todo{todo})
+		if err != nil {
+			errorResponse(c, err)
+			return
+		}
+		resources = append(resources, todoResource(col, todo, links[todo.ID]))
+	case col.list != nil || strings.HasSuffix(c.FullPath(), "/todo/"):
+		resources = append(resources, calendarResource(col, token))
+		if depth > 0 {
+			todos, links, ok := h.findAll(c, col)
+			if !ok {
+				return
+			}
+			for _, todo := range todos {
+				resources = append(resources, todoResource(col, todo, links[todo.ID]))
+			}
+		}
+	default:
+		resources = append(resources, principalResource(actor))
+		if depth > 0 {
+			lists, err := h.lists.FindAll(c.Request.Context(), actor)
+			if err != nil {
+				errorResponse(c, err)
+				return
+			}
+			resources = append(resources, calendarResource(col, token))
+			for _, list := range lists {
+				resources = append(resources, calendarResource(collection{actor, list}, token))
+			}
+		}
+	}
+
+	var res multistatus
+	for _, r := range resources {
+		switch {
+		case req.PropName != nil:
+			res.Responses = append(res.Responses, r.props.names(r.href))
+		case req.Prop != nil:
+			res.Responses = append(res.Responses, r.props.response(r.href, req.Prop.Names))
+		default:
+			res.Responses = append(res.Responses, r.props.response(r.href, nil))
+		}
+	}
+	writeXML(c, http.StatusMultiStatus, res)
+}
+
+func (h *davHandler) Report(c *gin.Context) {
+	col, ok := h.bindCollection(c)
+	if !ok {
+		return
+	}
+	var req reportRequest
+	if !decode(c, &req) {
+		return
+	}
+	var names []xml.Name
+	if req.Prop != nil {
+		names = req.Prop.Names
+	}
+
+	switch req.XMLName {
+	case xml.Name{Space: davNS, Local: "sync-collection"}:
+		h.syncCollection(c, col, req.SyncToken, names)
+	case xml.Name{Space: caldavNS, Local: "calendar-query"}:
+		todos, links, ok := h.findAll(c, col)
+		if !ok {
+			return
+		}
+		var res multistatus
+		if req.Filter == nil || req.Filter.CompFilter.matchesTodo() {
+			for _, todo := range todos {
+				r := todoResource(col, todo, links[todo.ID])
+				res.Responses = append(res.Responses, r.props.response(r.href, names))
+			}
+		}
+		writeXML(c, http.StatusMultiStatus, res)
+	case xml.Name{Space: caldavNS, Local: "calendar-multiget"}:
+		h.multiget(c, col, req.Hrefs, names)
+	default:
+		preconditionFailed(c, http.StatusForbidden, xml.Name{Space: davNS, Local: "supported-report"})
+	}
+}
+
+func (h *davHandler) multiget(c *gin.Context, col collection, hrefs []string, names []xml.Name) {
+	actor := col.actor
+	resourceNames := make([]string, len(hrefs))
+	for i, href := range hrefs {
+		resourceNames[i] = hrefName(col, href)
+	}
+	ids, err := h.resolve(c.Request.Context(), actor, resourceNames)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	todos, err := h.usecase.FindByIDs(c.Request.Context(), actor, ids)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	links, err := h.links(c.Request.Context(), actor, todos)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	found := map[int]*model.Todo{}
+	for _, todo := range todos {
+		if col.contains(todo) {
+			found[todo.ID] = todo
+		}
+	}
+	var res multistatus
+	for i, href := range hrefs {
+		todo, ok := found[ids[i]]
+		if !ok {
+			res.Responses = append(res.Responses, response{Href: href, Status: statusLine(http.StatusNotFound)})
+			continue
+		}
+		res.Responses = append(res.Responses, todoResource(col, todo, links[todo.ID]).props.response(href, names))
+	}
+	writeXML(c, http.StatusMultiStatus, res)
+}
+
+// syncCollection reports the todos changed since the token from the
+// history of the workspace, and the deleted ones with a 404 status. Without
+// a token every todo is reported. The calendar of a list reports the todos
+// of other lists as deleted, since they may have been moved out of it.
+func (h *davHandler) syncCollection(c *gin.Context, col collection, token string, names []xml.Name) {
+	actor := col.actor
+	// The new token is read first, so changes made while the report is
+	// built are reported again by the next sync.
+	current, err := h.syncToken(c.Request.Context(), actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	res := multistatus{SyncToken: syncTokenPrefix + strconv.Itoa(current)}
+	if token == "" {
+		todos, links, ok := h.findAll(c, col)
+		if !ok {
+			return
+		}
+		for _, todo := range todos {
+			r := todoResource(col, todo, links[todo.ID])
+			res.Responses = append(res.Responses, r.props.response(r.href, names))
+		}
+		writeXML(c, http.StatusMultiStatus, res)
+		return
+	}
+
+	since, err := strconv.Atoi(strings.TrimPrefix(token, syncTokenPrefix))
+	if err != nil || !strings.HasPrefix(token, syncTokenPrefix) || since < 0 {
+		preconditionFailed(c, http.StatusForbidden, xml.Name{Space: davNS, Local: "valid-sync-token"})
+		return
+	}
+	histories, err := h.usecase.Activity(c.Request.Context(), actor, model.ActivityFilter{AfterID: since, Limit: maxSyncChanges})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	if len(histories) == maxSyncChanges {
+		preconditionFailed(c, http.StatusForbidden, xml.Name{Space: davNS, Local: "valid-sync-token"})
+		return
+	}
+	if len(histories) > 0 && histories[0].ID > current {
+		res.SyncToken = syncTokenPrefix + strconv.Itoa(histories[0].ID)
+	}
+	var ids []int
+	seen := map[int]bool{}
+	for _, history := range histories {
+		if !seen[history.TodoID] {
+			seen[history.TodoID] = true
+			ids = append(ids, history.TodoID)
+		}
+	}
+	if len(ids) == 0 {
+		writeXML(c, http.StatusMultiStatus, res)
+		return
+	}
+	todos, err := h.usecase.FindByIDs(c.Request.Context(), actor, ids)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	// The links of deleted todos are kept, so they are reported under the
+	// name the client knows them by.
+	sources, err := h.sources.FindByTodoIDs(c.Request.Context(), actor, ids)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	links := linksOf(sources)
+	found := map[int]*model.Todo{}
+	for _, todo := range todos {
+		if col.contains(todo) {
+			found[todo.ID] = todo
+		}
+	}
+	for _, id := range ids {
+		todo, ok := found[id]
+		if !ok {
+			res.Responses = append(res.Responses, response{Href: todoPath(col, id, links[id]), Status: statusLine(http.StatusNotFound)})
+			continue
+		}
+		r := todoResource(col, todo, links[todo.ID])
+		res.Responses = append(res.Responses, r.props.response(r.href, names))
+	}
+	writeXML(c, http.StatusMultiStatus, res)
+}
+
+func (h *davHandler) Get(c *gin.Context) {
+	col, ok := h.bindCollection(c)
+	if !ok {
+		return
+	}
+	actor := col.actor
+	todo, ok := h.find(c, col)
+	if !ok {
+		return
+	}
+	if todo == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	links, err := h.links(c.Request.Context(), actor, []*model.Todo{todo})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.Header("ETag", etag(todo))
+	c.Data(http.StatusOK, calendarContentType, calendar(todo, links[todo.ID]))
+}
+
+// Put creates a todo or replaces the task and status of an existing one.
+// A new todo keeps the resource name and the UID chosen by the client, so
+// that later requests to the name reach it. A name of the form "{id}.ics"
+// is the server's: the todo is stored under its new ID, which the Location
+// header points to. No ETag is returned since the stored calendar differs
+// from the one that was sent.
+func (h *davHandler) Put(c *gin.Context) {
+	col, ok := h.bindCollection(c)
+	if !ok {
+		return
+	}
+	actor := col.actor
+	records, err := todoformat.ReadICalendar(http.MaxBytesReader(c.Writer, c.Request.Body, maxResourceSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		preconditionFailed(c, http.StatusForbidden, xml.Name{Space: caldavNS, Local: "valid-calendar-data"})
+		return
+	}
+	if len(records) != 1 {
+		preconditionFailed(c, http.StatusForbidden, xml.Name{Space: caldavNS, Local: "supported-calendar-component"})
+		return
+	}
+	req := handler.UpdateRequestBodyParam{Task: records[0].Task, Status: records[0].Status}
+	if req.Status == "" {
+		req.Status = model.Created
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		preconditionFailed(c, http.StatusForbidden, xml.Name{Space: caldavNS, Local: "valid-calendar-data"})
+		return
+	}
+
+	current, ok := h.find(c, col)
+	if !ok {
+		return
+	}
+	if current != nil {
+		// The preconditions are checked again against the locked todo, so
+		// that two clients sending the same ETag cannot both write.
+		err := h.usecase.UpdateIf(c.Request.Context(), actor, current.ID, req.Task, req.Status, func(t *model.Todo) bool {
+			return preconditions(c, t)
+		})
+		if err != nil {
+			errorResponse(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+		return
+	}
+	if !preconditions(c, nil) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	var sourceIDs []string
+	name := c.Param("name")
+	_, named := resourceID(name)
+	if !named {
+		if !validName(name) {
+			c.String(http.StatusForbidden, "resource names must end in .ics and be at most %d bytes", maxSourceIDLength-len(nameSourcePrefix))
+			return
+		}
+		sourceIDs = append(sourceIDs, nameSourcePrefix+name)
+	}
+	if uid := records[0].UID; uid != "" {
+		if len(uidSourcePrefix+uid) > maxSourceIDLength {
+			preconditionFailed(c, http.StatusForbidden, xml.Name{Space: caldavNS, Local: "valid-calendar-data"})
+			return
+		}
+		existing, err := h.findByUID(c.Request.Context(), actor, uid)
+		if err != nil {
+			errorResponse(c, err)
+			return
+		}
+		if existing != nil {
+			preconditionFailed(c, http.StatusForbidden, xml.Name{Space: caldavNS, Local: "no-uid-conflict"})
+			return
+		}
+		if _, ok := todoformat.TodoIDFromUID(uid); !ok {
+			sourceIDs = append(sourceIDs, uidSourcePrefix+uid)
+		}
+	}
+	todo := &model.Todo{ListID: col.listID(), Task: req.Task, Status: req.Status}
+	if err := h.usecase.CreateFrom(c.Request.Context(), actor, todo, sourceIDs); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	if named {
+		c.Header("Location", todoPath(col, todo.ID, link{}))
+	}
+	c.Status(http.StatusCreated)
+}
+
+func (h *davHandler) Delete(c *gin.Context) {
+	col, ok := h.bindCollection(c)
+	if !ok {
+		return
+	}
+	actor := col.actor
+	todo, ok := h.find(c, col)
+	if !ok {
+		return
+	}
+	if todo == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	err := h.usecase.DeleteIf(c.Request.Context(), actor, todo.ID, func(t *model.Todo) bool {
+		return preconditions(c, t)
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindCollection returns the calendar of the request, which is the "todo"
+// calendar for the requests to the principal.
+func (h *davHandler) bindCollection(c *gin.Context) (collection, bool) {
+	actor, ok := bindActor(c)
+	if !ok {
+		return collection{}, false
+	}
+	if c.Param("list_id") == "" {
+		return collection{actor: actor}, true
+	}
+	listID, err := strconv.Atoi(c.Param("list_id"))
+	if err != nil || listID <= 0 {
+		c.Status(http.StatusNotFound)
+		return collection{}, false
+	}
+	list, err := h.lists.Find(c.Request.Context(), actor, listID)
+	if err != nil {
+		errorResponse(c, err)
+		return collection{}, false
+	}
+	return collection{actor, list}, true
+}
+
+// find returns the todo of the requested resource, or nil when the name is
+// not one of a todo of the calendar.
+func (h *davHandler) find(c *gin.Context, col collection) (*model.Todo, bool) {
+	ids, err := h.resolve(c.Request.Context(), col.actor, []string{c.Param("name")})
+	if err != nil {
+		errorResponse(c, err)
+		return nil, false
+	}
+	if ids[0] == 0 {
+		return nil, true
+	}
+	todo, err := h.usecase.Find(c.Request.Context(), col.actor, ids[0])
+	if err != nil {
+		errorResponse(c, err)
+		return nil, false
+	}
+	if todo == nil || !col.contains(todo) {
+		return nil, true
+	}
+	return todo, true
+}
+
+// findAll returns the todos of the calendar with their links.
+func (h *davHandler) findAll(c *gin.Context, col collection) ([]*model.Todo, map[int]link, bool) {
+	var todos []*model.Todo
+	var err error
+	if col.list == nil {
+		todos, err = h.usecase.FindAll(c.Request.Context(), col.actor)
+	} else {
+		todos, err = h.usecase.Search(c.Request.Context(), col.actor, model.TodoFilter{ListID: col.list.ID})
+	}
+	if err != nil {
+		errorResponse(c, err)
+		return nil, nil, false
+	}
+	links, err := h.links(c.Request.Context(), col.actor, todos)
+	if err != nil {
+		errorResponse(c, err)
+		return nil, nil, false
+	}
+	return todos, links, true
+}
+
+// findByUID returns the existing todo with the VTODO UID, or nil.
+func (h *davHandler) findByUID(ctx context.Context, actor model.Actor, uid string) (*model.Todo, error) {
+	id, ok := todoformat.TodoIDFromUID(uid)
+	if !ok {
+		sources, err := h.sources.Find(ctx, actor, []string{uidSourcePrefix + uid})
+		if err != nil || len(sources) == 0 {
+			return nil, err
+		}
+		id = sources[0].TodoID
+	}
+	return h.usecase.Find(ctx, actor, id)
+}
+
+// resolve returns the todo IDs of the resource names, or 0 for a name that
+// is no todo's. Names of the form "{id}.ics" are the todo ID; the others
+// are looked up in the links.
+func (h *davHandler) resolve(ctx context.Context, actor model.Actor, names []string) ([]int, error) {
+	ids := make([]int, len(names))
+	var sourceIDs []string
+	for i, name := range names {
+		if id, ok := resourceID(name); ok {
+			ids[i] = id
+			continue
+		}
+		if validName(name) {
+			sourceIDs = append(sourceIDs, nameSourcePrefix+name)
+		}
+	}
+	if len(sourceIDs) == 0 {
+		return ids, nil
+	}
+	sources, err := h.sources.Find(ctx, actor, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	linked := map[string]int{}
+	for _, s := range sources {
+		linked[strings.TrimPrefix(s.SourceID, nameSourcePrefix)] = s.TodoID
+	}
+	for i, name := range names {
+		if ids[i] == 0 {
+			ids[i] = linked[name]
+		}
+	}
+	return ids, nil
+}
+
+// link is the resource name and UID a client created a todo with; both are
+// empty for the other todos.
+type link struct {
+	name string
+	uid  string
+}
+
+func (h *davHandler) links(ctx context.Context, actor model.Actor, todos []*model.Todo) (map[int]link, error) {
+	ids := make([]int, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	sources, err := h.sources.FindByTodoIDs(ctx, actor, ids)
+	if err != nil {
+		return nil, err
+	}
+	return linksOf(sources), nil
+}
+
+func linksOf(sources []*model.TodoSource) map[int]link {
+	links := map[int]link{}
+	for _, s := range sources {
+		l := links[s.TodoID]
+		if name, ok := strings.CutPrefix(s.SourceID, nameSourcePrefix); ok {
+			l.name = name
+		} else if uid, ok := strings.CutPrefix(s.SourceID, uidSourcePrefix); ok {
+			l.uid = uid
+		}
+		links[s.TodoID] = l
+	}
+	return links
+}
+
+// syncToken is the ID of the latest history entry of the workspace, which
+// changes with every write to its todos.
+func (h *davHandler) syncToken(ctx context.Context, actor model.Actor) (int, error) {
+	histories, err := h.usecase.Activity(ctx, actor, model.ActivityFilter{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	if len(histories) == 0 {
+		return 0, nil
+	}
+	return histories[0].ID, nil
+}
+
+type resource struct {
+	href  string
+	props properties
+}
+
+func principalResource(actor model.Actor) resource {
+	home := workspacePath(actor)
+	return resource{home, properties{
+		propResourceType: `<collection xmlns="DAV:"/><principal xmlns="DAV:"/>`,
+		propDisplayName:  text(fmt.Sprintf("Workspace %d", actor.WorkspaceID)),
+		propPrincipal:    href(home),
+		propPrincipalURL: href(home),
+		propHomeSet:      href(home),
+	}}
+}
+
+func calendarResource(col collection, token int) resource {
+	name := "Todo"
+	if col.list != nil {
+		name = text(col.list.Name)
+	}
+	return resource{col.path(), properties{
+		propResourceType: `<collection xmlns="DAV:"/><calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`,
+		propDisplayName:  name,
+		propPrincipal:    href(workspacePath(col.actor)),
+		propComponentSet: `<comp xmlns="urn:ietf:params:xml:ns:caldav" name="VTODO"/>`,
+		propSupportedReport: `<supported-report xmlns="DAV:"><report><sync-collection/></report></supported-report>` +
+			`<supported-report xmlns="DAV:"><report><calendar-query xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>` +
+			`<supported-report xmlns="DAV:"><report><calendar-multiget xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>`,
+		propSyncToken: text(syncTokenPrefix + strconv.Itoa(token)),
+		propCTag:      strconv.Itoa(token),
+	}}
+}
+
+func todoResource(col collection, todo *model.Todo, l link) resource {
+	return resource{todoPath(col, todo.ID, l), properties{
+		propResourceType: "",
+		propETag:         text(etag(todo)),
+		propContentType:  text(calendarContentType + "; component=VTODO"),
+		propCalendarData: text(string(calendar(todo, l))),
+	}}
+}
+
+// calendar is the resource of a todo. Its DTSTAMP is the last modification,
+// so the body only changes along with the ETag.
+func calendar(todo *model.Todo, l link) []byte {
+	var buf bytes.Buffer
+	enc := todoformat.NewICalendarEncoderWithUID(&buf, todo.UpdatedAt, func(*model.Todo) string { return l.uid })
+	_ = enc.Encode(todo)
+	_ = enc.Close()
+	return buf.Bytes()
+}
+
+// etag is derived from the content as well as the modification time, which
+// the database only keeps to the second.
+func etag(todo *model.Todo) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%s\x00%s\x00%d", todo.ID, todo.Task, todo.Status, todo.UpdatedAt.UnixNano())))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// preconditions checks If-Match and If-None-Match against the current todo,
+// which is nil when the resource does not exist.
+func preconditions(c *gin.Context, current *model.Todo) bool {
+	if m := c.GetHeader("If-Match"); m != "" {
+		if current == nil {
+			return false
+		}
+		if m != "*" && !matchETag(m, etag(current)) {
+			return false
+		}
+	}
+	if c.GetHeader("If-None-Match") == "*" && current != nil {
+		return false
+	}
+	return true
+}
+
+func matchETag(header string, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func workspacePath(actor model.Actor) string {
+	return Prefix + strconv.Itoa(actor.WorkspaceID) + "/"
+}
+
+// collection is a calendar of a workspace: the "todo" calendar of all its
+// todos, or the calendar of a list.
+type collection struct {
+	actor model.Actor
+	list  *model.List
+}
+
+func (col collection) path() string {
+	if col.list == nil {
+		return workspacePath(col.actor) + "todo/"
+	}
+	return workspacePath(col.actor) + "lists/" + strconv.Itoa(col.list.ID) + "/"
+}
+
+func (col collection) listID() int {
+	if col.list == nil {
+		return 0
+	}
+	return col.list.ID
+}
+
+func (col collection) contains(todo *model.Todo) bool {
+	return col.list == nil || todo.ListID == col.list.ID
+}
+
+func todoPath(col collection, id int, l link) string {
+	if l.name != "" {
+		return col.path() + l.name
+	}
+	return col.path() + strconv.Itoa(id) + ".ics"
+}
+
+func resourceID(name string) (int, bool) {
+	s, ok := strings.CutSuffix(name, ".ics")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// validName reports whether a client may give a todo the resource name.
+func validName(name string) bool {
+	return strings.HasSuffix(name, ".ics") && len(nameSourcePrefix+name) <= maxSourceIDLength
+}
+
+// hrefName returns the resource name of an href of the calendar, which may
+// be a path or a full URL, or "" for other hrefs.
+func hrefName(col collection, href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	dir, name := path.Split(u.Path)
+	if dir != col.path() {
+		return ""
+	}
+	return name
+}
+
+func bindActor(c *gin.Context) (model.Actor, bool) {
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil || workspaceID <= 0 {
+		c.Status(http.StatusNotFound)
+		return model.Actor{}, false
+	}
+	return model.NewActor(middleware.UserID(c), workspaceID), true
+}
+
+// decode reads an optional XML body; an empty one leaves v as it is.
+func decode(c *gin.Context, v any) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxResourceSize))
+	if err != nil {
+		c.Status(http.StatusRequestEntityTooLarge)
+		return false
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return true
+	}
+	if err := xml.Unmarshal(body, v); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func writeXML(c *gin.Context, code int, v any) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.Data(code, xmlContentType, append([]byte(xml.Header), body...))
+}
+
+// preconditionFailed reports the WebDAV precondition that was not met.
+func preconditionFailed(c *gin.Context, code int, condition xml.Name) {
+	writeXML(c, code, struct {
+		XMLName   xml.Name `xml:"DAV: error"`
+		Condition property
+	}{Condition: property{XMLName: condition}})
+}
+
+func errorResponse(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		code = http.StatusForbidden
+	case errors.Is(err, usecase.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, usecase.ErrConflict):
+		code = http.StatusConflict
+	case errors.Is(err, usecase.ErrPreconditionFailed):
+		code = http.StatusPreconditionFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		code = http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		code = http.StatusServiceUnavailable
+	}
+	c.String(code, http.StatusText(code))
+}
@@ -0,0 +1,727 @@
+package davhandler_test
+
+import (
+	"app/domain/model"
+	"app/handler/davhandler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+)
+
+// mockTodo はワークスペース1のタスクだけを持ち、それ以外のワークスペースは権限エラーにする
+type mockTodo struct {
+	usecase.Todo
+	mu        sync.Mutex
+	todos     map[int]*model.Todo
+	histories []*model.TodoHistory
+	sources   *mockTodoSource
+	updated   []*model.Todo
+	deleted   []int
+	linked    []string
+	created   *model.Todo
+	// locked は読み込み後に他のクライアントが書き換えた、ロック時点のタスク
+	locked map[int]*model.Todo
+}
+
+// mockList はワークスペース1のリスト5だけを持つ
+type mockList struct {
+	usecase.List
+}
+
+func (m *mockList) Find(ctx context.Context, actor model.Actor, id int) (*model.List, error) {
+	if id != 5 {
+		return nil, usecase.ErrNotFound
+	}
+	return &model.List{ID: 5, WorkspaceID: 1, Name: "groceries"}, nil
+}
+func (m *mockList) FindAll(ctx context.Context, actor model.Actor) ([]*model.List, error) {
+	return []*model.List{{ID: 5, WorkspaceID: 1, Name: "groceries"}}, nil
+}
+
+// mockTodoSource はクライアントが作成したタスク2のリソース名とUIDを持つ
+type mockTodoSource struct {
+	usecase.TodoSource
+	sources []*model.TodoSource
+}
+
+func (m *mockTodoSource) Find(ctx context.Context, actor model.Actor, sourceIDs []string) ([]*model.TodoSource, error) {
+	var res []*model.TodoSource
+	for _, s := range m.sources {
+		for _, id := range sourceIDs {
+			if s.SourceID == id {
+				res = append(res, s)
+			}
+		}
+	}
+	return res, nil
+}
+func (m *mockTodoSource) FindByTodoIDs(ctx context.Context, actor model.Actor, todoIDs []int) ([]*model.TodoSource, error) {
+	var res []*model.TodoSource
+	for _, s := range m.sources {
+		for _, id := range todoIDs {
+			if s.TodoID == id {
+				res = append(res, s)
+			}
+		}
+	}
+	return res, nil
+}
+
+func newMockTodo() *mockTodo {
+	updatedAt := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	return &mockTodo{
+		todos: map[int]*model.Todo{
+			1: {ID: 1, WorkspaceID: 1, ListID: 5, Task: "task1", Status: model.Created, UpdatedAt: updatedAt},
+			2: {ID: 2, WorkspaceID: 1, Task: "task2", Status: model.Done, UpdatedAt: updatedAt},
+		},
+		// 新しい順
+		histories: []*model.TodoHistory{
+			{ID: 5, TodoID: 3, Action: model.TodoActionDeleted},
+			{ID: 4, TodoID: 2, Action: model.TodoActionUpdated},
+			{ID: 3, TodoID: 3, Action: model.TodoActionCreated},
+			{ID: 2, TodoID: 2, Action: model.TodoActionCreated},
+			{ID: 1, TodoID: 1, Action: model.TodoActionCreated},
+		},
+		sources: &mockTodoSource{sources: []*model.TodoSource{
+			{WorkspaceID: 1, SourceID: "caldav:b.ics", TodoID: 2},
+			{WorkspaceID: 1, SourceID: "ical:b@example.com", TodoID: 2},
+			// 削除されたタスクのリンクも残る
+			{WorkspaceID: 1, SourceID: "caldav:c.ics", TodoID: 3},
+		}},
+	}
+}
+
+func (m *mockTodo) Find(ctx context.Context, actor model.Actor, id int) (*model.Todo, error) {
+	if actor.WorkspaceID != 1 {
+		return nil, usecase.ErrForbidden
+	}
+	return m.todos[id], nil
+}
+func (m *mockTodo) FindAll(ctx context.Context, actor model.Actor) ([]*model.Todo, error) {
+	if actor.WorkspaceID != 1 {
+		return nil, usecase.ErrForbidden
+	}
+	return []*model.Todo{m.todos[1], m.todos[2]}, nil
+}
+func (m *mockTodo) Search(ctx context.Context, actor model.Actor, f model.TodoFilter) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	for _, todo := range []*model.Todo{m.todos[1], m.todos[2]} {
+		if todo.ListID == f.ListID {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+func (m *mockTodo) FindByIDs(ctx context.Context, actor model.Actor, ids []int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	for _, id := range ids {
+		if todo, ok := m.todos[id]; ok {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+func (m *mockTodo) Activity(ctx context.Context, actor model.Actor, f model.ActivityFilter) ([]*model.TodoHistory, error) {
+	if actor.WorkspaceID != 1 {
+		return nil, usecase.ErrForbidden
+	}
+	var histories []*model.TodoHistory
+	for _, history := range m.histories {
+		if history.ID > f.AfterID && (f.Limit == 0 || len(histories) < f.Limit) {
+			histories = append(histories, history)
+		}
+	}
+	return histories, nil
+}
+
+// lock は更新・削除のトランザクションでロックしたタスクを返す
+func (m *mockTodo) lock(id int) *model.Todo {
+	if todo, ok := m.locked[id]; ok {
+		return todo
+	}
+	return m.todos[id]
+}
+func (m *mockTodo) UpdateIf(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus, match func(current *model.Todo) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !match(m.lock(id)) {
+		return usecase.ErrPreconditionFailed
+	}
+	m.updated = append(m.updated, &model.Todo{ID: id, Task: task, Status: status})
+	return nil
+}
+func (m *mockTodo) CreateFrom(ctx context.Context, actor model.Actor, todo *model.Todo, sourceIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	todo.ID = 10
+	m.created = todo
+	m.linked = sourceIDs
+	return nil
+}
+func (m *mockTodo) DeleteIf(ctx context.Context, actor model.Actor, id int, match func(current *model.Todo) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !match(m.lock(id)) {
+		return usecase.ErrPreconditionFailed
+	}
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Status    string `xml:"DAV: status"`
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				Inner string `xml:",innerxml"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+	SyncToken string `xml:"DAV: sync-token"`
+}
+
+// summary は href ごとにステータスと見つかったプロパティの有無を並べる
+func (ms multistatus) summary() []string {
+	var lines []string
+	for _, r := range ms.Responses {
+		line := r.Href
+		if r.Status != "" {
+			line += " " + r.Status
+		}
+		for _, ps := range r.Propstats {
+			line += " " + ps.Status
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func serve(t *testing.T, m *mockTodo, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	validator.SetupValidator()
+	h := davhandler.NewDAV(m, &mockList{}, m.sources)
+	r := gin.New()
+	r.Use(middleware.Authenticate())
+	dav := r.Group(davhandler.Prefix + ":workspace_id")
+	dav.Handle("PROPFIND", "/", h.Propfind)
+	for _, calendar := range []string{"/todo/", "/lists/:list_id/"} {
+		for _, path := range []string{calendar, calendar + ":name"} {
+			dav.OPTIONS(path, h.Options)
+			dav.Handle("PROPFIND", path, h.Propfind)
+		}
+		dav.Handle("REPORT", calendar, h.Report)
+		dav.GET(calendar+":name", h.Get)
+		dav.PUT(calendar+":name", h.Put)
+		dav.DELETE(calendar+":name", h.Delete)
+	}
+
+	req.Header.Set(middleware.UserIDHeader, "1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) multistatus {
+	t.Helper()
+	var ms multistatus
+	if err := xml.Unmarshal(rec.Body.Bytes(), &ms); err != nil {
+		t.Fatalf("failed to decode response: %v\n%s", err, rec.Body.String())
+	}
+	return ms
+}
+
+func TestPropfind(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		path             string
+		depth            string
+		body             string
+		want_status_code int
+		want_responses   []string
+		want_contains    string
+	}{
+		{
+			name:             "正常系_ワークスペースがプリンシパル兼カレンダーホームとして返ること",
+			path:             "/dav/workspaces/1/",
+			depth:            "0",
+			body:             `<propfind xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><current-user-principal/><C:calendar-home-set/></prop></propfind>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses:   []string{"/dav/workspaces/1/ HTTP/1.1 200 OK"},
+			want_contains:    `<calendar-home-set xmlns="urn:ietf:params:xml:ns:caldav"><href xmlns="DAV:">/dav/workspaces/1/</href></calendar-home-set>`,
+		},
+		{
+			name:             "正常系_プリンシパルの配下にタスクとリストのカレンダーが返ること",
+			path:             "/dav/workspaces/1/",
+			depth:            "1",
+			body:             `<propfind xmlns="DAV:"><prop><displayname/></prop></propfind>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/ HTTP/1.1 200 OK",
+				"/dav/workspaces/1/lists/5/ HTTP/1.1 200 OK",
+				"/dav/workspaces/1/todo/ HTTP/1.1 200 OK",
+			},
+			want_contains: `<displayname xmlns="DAV:">groceries</displayname>`,
+		},
+		{
+			name:             "正常系_リストのカレンダーにリストのタスクだけが返ること",
+			path:             "/dav/workspaces/1/lists/5/",
+			depth:            "1",
+			body:             `<propfind xmlns="DAV:"><prop><getetag/></prop></propfind>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/lists/5/ HTTP/1.1 404 Not Found",
+				"/dav/workspaces/1/lists/5/1.ics HTTP/1.1 200 OK",
+			},
+		},
+		{
+			name:             "異常系_他のリストのタスクの場合",
+			path:             "/dav/workspaces/1/lists/5/b.ics",
+			want_status_code: http.StatusNotFound,
+		},
+		{
+			name:             "異常系_存在しないリストの場合",
+			path:             "/dav/workspaces/1/lists/6/",
+			want_status_code: http.StatusNotFound,
+		},
+		{
+			name:             "正常系_カレンダーと配下のタスクが返り未知のプロパティが404になること",
+			path:             "/dav/workspaces/1/todo/",
+			depth:            "1",
+			body:             `<propfind xmlns="DAV:"><prop><getetag/><sync-token/><unknown xmlns="urn:example"/></prop></propfind>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/todo/ HTTP/1.1 200 OK HTTP/1.1 404 Not Found",
+				"/dav/workspaces/1/todo/1.ics HTTP/1.1 200 OK HTTP/1.1 404 Not Found",
+				"/dav/workspaces/1/todo/b.ics HTTP/1.1 200 OK HTTP/1.1 404 Not Found",
+			},
+			want_contains: `<sync-token xmlns="DAV:">http://go-api-sample-todo/ns/sync/5</sync-token>`,
+		},
+		{
+			name:             "正常系_本文がない場合calendar-data以外のすべてのプロパティが返ること",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			depth:            "0",
+			want_status_code: http.StatusMultiStatus,
+			want_responses:   []string{"/dav/workspaces/1/todo/1.ics HTTP/1.1 200 OK"},
+			want_contains:    `<getcontenttype xmlns="DAV:">text/calendar; charset=utf-8; component=VTODO</getcontenttype>`,
+		},
+		{
+			name:             "正常系_クライアントが付けたリソース名で返ること",
+			path:             "/dav/workspaces/1/todo/b.ics",
+			depth:            "0",
+			want_status_code: http.StatusMultiStatus,
+			want_responses:   []string{"/dav/workspaces/1/todo/b.ics HTTP/1.1 200 OK"},
+		},
+		{
+			name:             "異常系_存在しないタスクの場合",
+			path:             "/dav/workspaces/1/todo/3.ics",
+			want_status_code: http.StatusNotFound,
+		},
+		{
+			name:             "異常系_権限がない場合",
+			path:             "/dav/workspaces/2/todo/",
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest("PROPFIND", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Depth", tt.depth)
+			rec := serve(t, newMockTodo(), req)
+
+			if tt.want_status_code != rec.Code {
+				t.Fatalf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusMultiStatus {
+				return
+			}
+			if got := decode(t, rec).summary(); !cmp.Equal(got, tt.want_responses) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.want_responses))
+			}
+			if !strings.Contains(rec.Body.String(), tt.want_contains) {
+				t.Errorf("%s does not contain %s", rec.Body.String(), tt.want_contains)
+			}
+			if strings.Contains(rec.Body.String(), "calendar-data") {
+				t.Errorf("calendar-data must only be returned on request")
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		path             string
+		body             string
+		want_status_code int
+		want_responses   []string
+		want_sync_token  string
+	}{
+		{
+			name:             "正常系_トークンがない場合すべてのタスクが返ること",
+			body:             `<sync-collection xmlns="DAV:"><sync-token/><sync-level>1</sync-level><prop><getetag/></prop></sync-collection>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/todo/1.ics HTTP/1.1 200 OK",
+				"/dav/workspaces/1/todo/b.ics HTTP/1.1 200 OK",
+			},
+			want_sync_token: "http://go-api-sample-todo/ns/sync/5",
+		},
+		{
+			name:             "正常系_トークン以降の変更と削除が返ること",
+			body:             `<sync-collection xmlns="DAV:"><sync-token>http://go-api-sample-todo/ns/sync/2</sync-token><prop><getetag/></prop></sync-collection>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/todo/b.ics HTTP/1.1 200 OK",
+				"/dav/workspaces/1/todo/c.ics HTTP/1.1 404 Not Found",
+			},
+			want_sync_token: "http://go-api-sample-todo/ns/sync/5",
+		},
+		{
+			name:             "正常系_変更がない場合空で返ること",
+			body:             `<sync-collection xmlns="DAV:"><sync-token>http://go-api-sample-todo/ns/sync/5</sync-token><prop><getetag/></prop></sync-collection>`,
+			want_status_code: http.StatusMultiStatus,
+			want_sync_token:  "http://go-api-sample-todo/ns/sync/5",
+		},
+		{
+			name: "正常系_multigetで指定したタスクが返ること",
+			body: `<C:calendar-multiget xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><getetag/><C:calendar-data/></prop>` +
+				`<href>/dav/workspaces/1/todo/2.ics</href><href>/dav/workspaces/1/todo/b.ics</href><href>/dav/workspaces/1/todo/c.ics</href>` +
+				`<href>http://localhost/dav/workspaces/1/todo/3.ics</href></C:calendar-multiget>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/todo/2.ics HTTP/1.1 200 OK",
+				"/dav/workspaces/1/todo/b.ics HTTP/1.1 200 OK",
+				"/dav/workspaces/1/todo/c.ics HTTP/1.1 404 Not Found",
+				"http://localhost/dav/workspaces/1/todo/3.ics HTTP/1.1 404 Not Found",
+			},
+		},
+		{
+			name: "正常系_calendar-queryでVTODOが返ること",
+			body: `<C:calendar-query xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><getetag/></prop>` +
+				`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO"/></C:comp-filter></C:filter></C:calendar-query>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/todo/1.ics HTTP/1.1 200 OK",
+				"/dav/workspaces/1/todo/b.ics HTTP/1.1 200 OK",
+			},
+		},
+		{
+			name: "正常系_calendar-queryでVEVENTを指定した場合空で返ること",
+			body: `<C:calendar-query xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><getetag/></prop>` +
+				`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"/></C:comp-filter></C:filter></C:calendar-query>`,
+			want_status_code: http.StatusMultiStatus,
+		},
+		{
+			name:             "正常系_リストのカレンダーでは他のリストに移ったタスクが削除として返ること",
+			path:             "/dav/workspaces/1/lists/5/",
+			body:             `<sync-collection xmlns="DAV:"><sync-token>http://go-api-sample-todo/ns/sync/2</sync-token><prop><getetag/></prop></sync-collection>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/lists/5/b.ics HTTP/1.1 404 Not Found",
+				"/dav/workspaces/1/lists/5/c.ics HTTP/1.1 404 Not Found",
+			},
+			want_sync_token: "http://go-api-sample-todo/ns/sync/5",
+		},
+		{
+			name: "正常系_リストのカレンダーのmultigetでリストのタスクだけが返ること",
+			path: "/dav/workspaces/1/lists/5/",
+			body: `<C:calendar-multiget xmlns="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><prop><getetag/></prop>` +
+				`<href>/dav/workspaces/1/lists/5/1.ics</href><href>/dav/workspaces/1/lists/5/b.ics</href><href>/dav/workspaces/1/todo/1.ics</href></C:calendar-multiget>`,
+			want_status_code: http.StatusMultiStatus,
+			want_responses: []string{
+				"/dav/workspaces/1/lists/5/1.ics HTTP/1.1 200 OK",
+				"/dav/workspaces/1/lists/5/b.ics HTTP/1.1 404 Not Found",
+				"/dav/workspaces/1/todo/1.ics HTTP/1.1 404 Not Found",
+			},
+		},
+		{
+			name:             "異常系_不正なトークンの場合",
+			body:             `<sync-collection xmlns="DAV:"><sync-token>urn:example:1</sync-token></sync-collection>`,
+			want_status_code: http.StatusForbidden,
+		},
+		{
+			name:             "異常系_未対応のレポートの場合",
+			body:             `<expand-property xmlns="DAV:"/>`,
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := tt.path
+			if path == "" {
+				path = "/dav/workspaces/1/todo/"
+			}
+			req := httptest.NewRequest("REPORT", path, strings.NewReader(tt.body))
+			rec := serve(t, newMockTodo(), req)
+
+			if tt.want_status_code != rec.Code {
+				t.Fatalf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusMultiStatus {
+				return
+			}
+			ms := decode(t, rec)
+			if got := ms.summary(); !cmp.Equal(got, tt.want_responses) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.want_responses))
+			}
+			if ms.SyncToken != tt.want_sync_token {
+				t.Errorf("want = %v, got = %v", tt.want_sync_token, ms.SyncToken)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+	m := newMockTodo()
+	rec := serve(t, m, httptest.NewRequest("GET", "/dav/workspaces/1/todo/1.ics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want = %v, got = %v", http.StatusOK, rec.Code)
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Errorf("ETag is missing")
+	}
+	// DTSTAMP は更新日時なので同じ内容であれば毎回同じ本文が返る
+	if !strings.Contains(rec.Body.String(), "UID:todo-1@go-api-sample-todo\r\nDTSTAMP:20230102T000000Z\r\n") {
+		t.Errorf("unexpected body: %s", rec.Body.String())
+	}
+
+	// クライアントが付けた名前とUIDで返る
+	rec = serve(t, m, httptest.NewRequest("GET", "/dav/workspaces/1/todo/b.ics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("want = %v, got = %v", http.StatusOK, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "UID:b@example.com\r\n") || !strings.Contains(rec.Body.String(), "SUMMARY:task2\r\n") {
+		t.Errorf("unexpected body: %s", rec.Body.String())
+	}
+
+	for _, path := range []string{"/dav/workspaces/1/todo/3.ics", "/dav/workspaces/1/todo/c.ics", "/dav/workspaces/1/todo/unknown.ics"} {
+		rec = serve(t, m, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: want = %v, got = %v", path, http.StatusNotFound, rec.Code)
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	t.Parallel()
+	vtodoWithUID := func(uid string, summary string, status string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:" + uid + "\r\nSUMMARY:" + summary +
+			"\r\nSTATUS:" + status + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+	vtodo := func(summary string, status string) string {
+		return vtodoWithUID("x@example.com", summary, status)
+	}
+	etag := serve(t, newMockTodo(), httptest.NewRequest("GET", "/dav/workspaces/1/todo/1.ics", nil)).Header().Get("ETag")
+	etag2 := serve(t, newMockTodo(), httptest.NewRequest("GET", "/dav/workspaces/1/todo/b.ics", nil)).Header().Get("ETag")
+	tests := []struct {
+		name             string
+		path             string
+		body             string
+		header           map[string]string
+		want_status_code int
+		want_location    string
+		want_updated     []*model.Todo
+		want_linked      []string
+		want_list_id     int
+		locked           map[int]*model.Todo
+	}{
+		{
+			name:             "正常系_新しいタスクがクライアントのリソース名とUIDで登録されること",
+			path:             "/dav/workspaces/1/todo/0b5a6c1e.ics",
+			body:             vtodo("new", "NEEDS-ACTION"),
+			header:           map[string]string{"If-None-Match": "*"},
+			want_status_code: http.StatusCreated,
+			want_linked:      []string{"caldav:0b5a6c1e.ics", "ical:x@example.com"},
+		},
+		{
+			name:             "正常系_削除されたタスクの名前で新しいタスクが登録されること",
+			path:             "/dav/workspaces/1/todo/c.ics",
+			body:             vtodo("new", "NEEDS-ACTION"),
+			header:           map[string]string{"If-None-Match": "*"},
+			want_status_code: http.StatusCreated,
+			want_linked:      []string{"caldav:c.ics", "ical:x@example.com"},
+		},
+		{
+			name:             "正常系_リストのカレンダーに登録したタスクがリストに入ること",
+			path:             "/dav/workspaces/1/lists/5/0b5a6c1e.ics",
+			body:             vtodo("new", "NEEDS-ACTION"),
+			want_status_code: http.StatusCreated,
+			want_linked:      []string{"caldav:0b5a6c1e.ics", "ical:x@example.com"},
+			want_list_id:     5,
+		},
+		{
+			name:             "正常系_IDの形式の名前の場合新しいIDの場所が返ること",
+			path:             "/dav/workspaces/1/todo/20.ics",
+			body:             vtodo("new", "NEEDS-ACTION"),
+			want_status_code: http.StatusCreated,
+			want_location:    "/dav/workspaces/1/todo/10.ics",
+			want_linked:      []string{"ical:x@example.com"},
+		},
+		{
+			name:             "正常系_ETagが一致する場合更新されること",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             vtodo("changed", "COMPLETED"),
+			header:           map[string]string{"If-Match": etag},
+			want_status_code: http.StatusNoContent,
+			want_updated:     []*model.Todo{{ID: 1, Task: "changed", Status: model.Done}},
+		},
+		{
+			name:             "正常系_クライアントのリソース名で繰り返し更新できること",
+			path:             "/dav/workspaces/1/todo/b.ics",
+			body:             vtodoWithUID("b@example.com", "changed", "COMPLETED"),
+			header:           map[string]string{"If-Match": etag2},
+			want_status_code: http.StatusNoContent,
+			want_updated:     []*model.Todo{{ID: 2, Task: "changed", Status: model.Done}},
+		},
+		{
+			name:             "異常系_UIDが既存のタスクと重複する場合",
+			path:             "/dav/workspaces/1/todo/other.ics",
+			body:             vtodoWithUID("b@example.com", "new", "NEEDS-ACTION"),
+			want_status_code: http.StatusForbidden,
+		},
+		{
+			name:             "異常系_UIDがサーバーの付けた既存のタスクのUIDと重複する場合",
+			path:             "/dav/workspaces/1/todo/other.ics",
+			body:             vtodoWithUID("todo-1@go-api-sample-todo", "new", "NEEDS-ACTION"),
+			want_status_code: http.StatusForbidden,
+		},
+		{
+			name:             "異常系_リソース名が.icsで終わらない場合",
+			path:             "/dav/workspaces/1/todo/new",
+			body:             vtodo("new", "NEEDS-ACTION"),
+			want_status_code: http.StatusForbidden,
+		},
+		{
+			name:             "異常系_ETagが一致しない場合",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             vtodo("changed", "COMPLETED"),
+			header:           map[string]string{"If-Match": `"stale"`},
+			want_status_code: http.StatusPreconditionFailed,
+		},
+		{
+			name:             "異常系_読み込み後に他のクライアントが更新した場合",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             vtodo("changed", "COMPLETED"),
+			header:           map[string]string{"If-Match": etag},
+			want_status_code: http.StatusPreconditionFailed,
+			locked:           map[int]*model.Todo{1: {ID: 1, WorkspaceID: 1, ListID: 5, Task: "other", Status: model.Created, UpdatedAt: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)}},
+		},
+		{
+			name:             "異常系_読み込み後に他のクライアントが削除した場合",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             vtodo("changed", "COMPLETED"),
+			header:           map[string]string{"If-Match": etag},
+			want_status_code: http.StatusPreconditionFailed,
+			locked:           map[int]*model.Todo{1: nil},
+		},
+		{
+			name:             "異常系_既存のタスクにIf-None-Matchを指定した場合",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             vtodo("changed", "COMPLETED"),
+			header:           map[string]string{"If-None-Match": "*"},
+			want_status_code: http.StatusPreconditionFailed,
+		},
+		{
+			name:             "異常系_対応しないステータスの場合",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             vtodo("changed", "CANCELLED"),
+			want_status_code: http.StatusForbidden,
+		},
+		{
+			name:             "異常系_VTODOがない場合",
+			path:             "/dav/workspaces/1/todo/1.ics",
+			body:             "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n",
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := newMockTodo()
+			m.locked = tt.locked
+			req := httptest.NewRequest("PUT", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/calendar")
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := serve(t, m, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Fatalf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if got := rec.Header().Get("Location"); got != tt.want_location {
+				t.Errorf("want = %v, got = %v", tt.want_location, got)
+			}
+			if !cmp.Equal(m.updated, tt.want_updated) {
+				t.Errorf("diff %s", cmp.Diff(m.updated, tt.want_updated))
+			}
+			if !cmp.Equal(m.linked, tt.want_linked) {
+				t.Errorf("diff %s", cmp.Diff(m.linked, tt.want_linked))
+			}
+			if m.created != nil && m.created.ListID != tt.want_list_id {
+				t.Errorf("want = %v, got = %v", tt.want_list_id, m.created.ListID)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+	m := newMockTodo()
+	req := httptest.NewRequest("DELETE", "/dav/workspaces/1/todo/1.ics", nil)
+	req.Header.Set("If-Match", `"stale"`)
+	if rec := serve(t, m, req); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("want = %v, got = %v", http.StatusPreconditionFailed, rec.Code)
+	}
+	if rec := serve(t, m, httptest.NewRequest("DELETE", "/dav/workspaces/1/todo/1.ics", nil)); rec.Code != http.StatusNoContent {
+		t.Errorf("want = %v, got = %v", http.StatusNoContent, rec.Code)
+	}
+	if rec := serve(t, m, httptest.NewRequest("DELETE", "/dav/workspaces/1/todo/b.ics", nil)); rec.Code != http.StatusNoContent {
+		t.Errorf("want = %v, got = %v", http.StatusNoContent, rec.Code)
+	}
+	if rec := serve(t, m, httptest.NewRequest("DELETE", "/dav/workspaces/1/todo/3.ics", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("want = %v, got = %v", http.StatusNotFound, rec.Code)
+	}
+	if !cmp.Equal(m.deleted, []int{1, 2}) {
+		t.Errorf("diff %s", cmp.Diff(m.deleted, []int{1, 2}))
+	}
+
+	// 読み込み後に他のクライアントが更新した場合、同じETagでは削除できない
+	m = newMockTodo()
+	etag := serve(t, m, httptest.NewRequest("GET", "/dav/workspaces/1/todo/1.ics", nil)).Header().Get("ETag")
+	changed := *m.todos[1]
+	changed.Task = "other"
+	m.locked = map[int]*model.Todo{1: &changed}
+	req = httptest.NewRequest("DELETE", "/dav/workspaces/1/todo/1.ics", nil)
+	req.Header.Set("If-Match", etag)
+	if rec := serve(t, m, req); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("want = %v, got = %v", http.StatusPreconditionFailed, rec.Code)
+	}
+	if len(m.deleted) != 0 {
+		t.Errorf("want nothing deleted, got = %v", m.deleted)
+	}
+}
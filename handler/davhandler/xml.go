@@ -0,0 +1,179 @@
+package davhandler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	davNS    = "DAV:"
+	caldavNS = "urn:ietf:params:xml:ns:caldav"
+	// csNS is the namespace of the calendarserver extensions; clients still
+	// poll getctag before they try sync-collection.
+	csNS = "http://calendarserver.org/ns/"
+)
+
+var (
+	propResourceType    = xml.Name{Space: davNS, Local: "resourcetype"}
+	propDisplayName     = xml.Name{Space: davNS, Local: "displayname"}
+	propPrincipal       = xml.Name{Space: davNS, Local: "current-user-principal"}
+	propPrincipalURL    = xml.Name{Space: davNS, Local: "principal-URL"}
+	propSupportedReport = xml.Name{Space: davNS, Local: "supported-report-set"}
+	propSyncToken       = xml.Name{Space: davNS, Local: "sync-token"}
+	propETag            = xml.Name{Space: davNS, Local: "getetag"}
+	propContentType     = xml.Name{Space: davNS, Local: "getcontenttype"}
+	propHomeSet         = xml.Name{Space: caldavNS, Local: "calendar-home-set"}
+	propComponentSet    = xml.Name{Space: caldavNS, Local: "supported-calendar-component-set"}
+	propCalendarData    = xml.Name{Space: caldavNS, Local: "calendar-data"}
+	propCTag            = xml.Name{Space: csNS, Local: "getctag"}
+)
+
+// propNames collects the names of the children of a prop element.
+type propNames struct {
+	Names []xml.Name
+}
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			p.Names = append(p.Names, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type propfindRequest struct {
+	XMLName  xml.Name   `xml:"DAV: propfind"`
+	AllProp  *struct{}  `xml:"DAV: allprop"`
+	PropName *struct{}  `xml:"DAV: propname"`
+	Prop     *propNames `xml:"DAV: prop"`
+}
+
+// reportRequest holds the elements of the supported reports; which ones are
+// used depends on the name of the root element.
+type reportRequest struct {
+	XMLName   xml.Name
+	Prop      *propNames `xml:"DAV: prop"`
+	Hrefs     []string   `xml:"DAV: href"`
+	SyncToken string     `xml:"DAV: sync-token"`
+	Filter    *struct {
+		CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type compFilter struct {
+	Name        string       `xml:"name,attr"`
+	CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// matchesTodo tells whether a VTODO passes the filter. Property and time
+// range filters are not supported and match everything, since todos have
+// no dates a client could filter on.
+func (f compFilter) matchesTodo() bool {
+	if !strings.EqualFold(f.Name, "VCALENDAR") {
+		return false
+	}
+	for _, c := range f.CompFilters {
+		if !strings.EqualFold(c.Name, "VTODO") {
+			return false
+		}
+	}
+	return true
+}
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []response `xml:"response"`
+	SyncToken string     `xml:"sync-token,omitempty"`
+}
+
+type response struct {
+	Href      string     `xml:"href"`
+	Status    string     `xml:"status,omitempty"`
+	Propstats []propstat `xml:"propstat"`
+}
+
+type propstat struct {
+	Prop   []property `xml:"prop>x"`
+	Status string     `xml:"status"`
+}
+
+// property is a property with its value as already escaped XML.
+type property struct {
+	XMLName xml.Name
+	Value   string `xml:",innerxml"`
+}
+
+// properties maps the names of the properties of a resource to their value
+// as XML.
+type properties map[xml.Name]string
+
+// response answers a request for the given properties with a 200 propstat
+// for the known ones and a 404 propstat for the others. Without names every
+// property except calendar-data is returned, as for allprop.
+func (p properties) response(href string, names []xml.Name) response {
+	if names == nil {
+		for name := range p {
+			if name != propCalendarData {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return names[i].Space+names[i].Local < names[j].Space+names[j].Local
+		})
+	}
+	var found, missing []property
+	for _, name := range names {
+		if value, ok := p[name]; ok {
+			found = append(found, property{name, value})
+		} else {
+			missing = append(missing, property{XMLName: name})
+		}
+	}
+	res := response{Href: href}
+	if len(found) > 0 {
+		res.Propstats = append(res.Propstats, propstat{found, statusLine(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		res.Propstats = append(res.Propstats, propstat{missing, statusLine(http.StatusNotFound)})
+	}
+	return res
+}
+
+// names lists the properties without their value, for propname.
+func (p properties) names(href string) response {
+	var props []property
+	for name := range p {
+		props = append(props, property{XMLName: name})
+	}
+	sort.Slice(props, func(i, j int) bool {
+		return props[i].XMLName.Space+props[i].XMLName.Local < props[j].XMLName.Space+props[j].XMLName.Local
+	})
+	return response{Href: href, Propstats: []propstat{{props, statusLine(http.StatusOK)}}}
+}
+
+func statusLine(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+func text(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func href(path string) string {
+	return `<href xmlns="DAV:">` + text(path) + `</href>`
+}
@@ -0,0 +1,117 @@
+package graphqlhandler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// complexity estimates the cost of an operation before it is executed. Every
+// field costs 1, a mutation field costs mutationCost, and the selections below
+// a list field are multiplied by the number of items it may return.
+func complexity(doc *ast.Document, operationName string, variables map[string]interface{}) (int, error) {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || d.Name != nil && d.Name.Value == operationName {
+				if op != nil && operationName == "" {
+					return 0, errors.New("operationName is required when the document has several operations")
+				}
+				op = d
+			}
+		}
+	}
+	if op == nil {
+		return 0, errors.New("operation not found")
+	}
+	c := &calculator{fragments: fragments, variables: variables, visiting: map[string]bool{}}
+	return c.selectionSet(op.SelectionSet), nil
+}
+
+type calculator struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	visiting  map[string]bool
+}
+
+func (c *calculator) selectionSet(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+	total := 0
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			total += c.cost(s) + c.multiplier(s)*c.selectionSet(s.SelectionSet)
+		case *ast.InlineFragment:
+			total += c.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			if f, ok := c.fragments[name]; ok && !c.visiting[name] {
+				c.visiting[name] = true
+				total += c.selectionSet(f.SelectionSet)
+				c.visiting[name] = false
+			}
+		}
+	}
+	return total
+}
+
+// mutationCost is the cost of a mutation field. A mutation locks and writes
+// the todo, its history and its event in a transaction of its own, which
+// costs about as much as reading a page of todos, so a request cannot run a
+// mutation for every unit of the limit through aliases.
+const mutationCost = defaultLimit
+
+func (c *calculator) cost(f *ast.Field) int {
+	switch f.Name.Value {
+	case "createTodo", "updateTodo", "deleteTodo":
+		return mutationCost
+	}
+	return 1
+}
+
+func (c *calculator) multiplier(f *ast.Field) int {
+	switch f.Name.Value {
+	case "todos":
+		n, ok := c.intArgument(f, "limit")
+		if !ok {
+			return defaultLimit
+		}
+		// An out of range limit is rejected when the field is resolved, but
+		// a negative one must not lower the cost of the other fields here.
+		if n < 1 || n > maxLimit {
+			return maxLimit
+		}
+		return n
+	case "todoCountsByStatus":
+		return len(sortedStatuses())
+	}
+	return 1
+}
+
+func (c *calculator) intArgument(f *ast.Field, name string) (int, bool) {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != name {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(v.Value)
+			return n, err == nil
+		case *ast.Variable:
+			switch n := c.variables[v.Name.Value].(type) {
+			case float64:
+				return int(n), true
+			case int:
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
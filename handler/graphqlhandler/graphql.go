@@ -0,0 +1,110 @@
+package graphqlhandler
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/usecase"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type GraphQL interface {
+	Serve(c *gin.Context)
+}
+
+type graphqlHandler struct {
+	schema        graphql.Schema
+	usecase       usecase.Todo
+	maxComplexity int
+}
+
+func NewGraphQL(u usecase.Todo, maxComplexity int) (GraphQL, error) {
+	schema, err := NewSchema()
+	if err != nil {
+		return nil, err
+	}
+	return &graphqlHandler{schema: schema, usecase: u, maxComplexity: maxComplexity}, nil
+}
+
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Response struct {
+	Data   interface{}                `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+func (g *graphqlHandler) Serve(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	workspaceID, err := strconv.Atoi(c.GetHeader(handler.WorkspaceIDHeader))
+	if err != nil || workspaceID <= 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("missing or invalid "+handler.WorkspaceIDHeader+" header"))
+		return
+	}
+	actor := model.NewActor(middleware.UserID(c), workspaceID)
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	cost, err := complexity(doc, req.OperationName, req.Variables)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err.Error()))
+		return
+	}
+	if cost > g.maxComplexity {
+		c.JSON(http.StatusBadRequest, errorResponse("query complexity "+strconv.Itoa(cost)+" exceeds the limit of "+strconv.Itoa(g.maxComplexity)))
+		return
+	}
+
+	ctx := withRequest(c.Request.Context(), &request{
+		actor:   actor,
+		usecase: g.usecase,
+		loader:  newTodoLoader(g.usecase, actor),
+	})
+	res := graphql.Do(graphql.Params{
+		Schema:         g.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+	c.JSON(status(ctx, res), Response{Data: res.Data, Errors: res.Errors})
+}
+
+// status reports the errors of a request whose context ended as a timeout
+// or cancellation rather than as a successful GraphQL response.
+func status(ctx context.Context, res *graphql.Result) int {
+	if len(res.Errors) == 0 {
+		return http.StatusOK
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusOK
+	}
+}
+
+func errorResponse(message string) Response {
+	return Response{Errors: []gqlerrors.FormattedError{{Message: message}}}
+}
@@ -0,0 +1,181 @@
+package graphqlhandler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/graphqlhandler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockTodo struct {
+	usecase.Todo
+	findByIDsCalls int32
+}
+
+func (m *mockTodo) FindByIDs(ctx context.Context, actor model.Actor, ids []int) ([]*model.Todo, error) {
+	atomic.AddInt32(&m.findByIDsCalls, 1)
+	var res []*model.Todo
+	for _, id := range ids {
+		res = append(res, &model.Todo{ID: id, WorkspaceID: actor.WorkspaceID, Task: "task", Status: model.Created})
+	}
+	return res, nil
+}
+func (m *mockTodo) Search(ctx context.Context, actor model.Actor, f model.TodoFilter) ([]*model.Todo, error) {
+	return []*model.Todo{{ID: 1, WorkspaceID: actor.WorkspaceID, Task: "task", Status: f.Status}}, nil
+}
+func (m *mockTodo) Count(ctx context.Context, actor model.Actor, f model.TodoFilter) (int64, error) {
+	return 1, nil
+}
+func (m *mockTodo) Create(ctx context.Context, actor model.Actor, task string, listID int) error {
+	return nil
+}
+func (m *mockTodo) Update(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus) error {
+	return nil
+}
+
+func serve(t *testing.T, u usecase.Todo, maxComplexity int, query string) (int, graphqlhandler.Response) {
+	t.Helper()
+	validator.SetupValidator()
+	g, err := graphqlhandler.NewGraphQL(u, maxComplexity)
+	if err != nil {
+		t.Fatalf("Failed to build schema: %v", err)
+	}
+	r := gin.New()
+	r.Use(middleware.Authenticate())
+	r.POST("/graphql", g.Serve)
+
+	body, _ := json.Marshal(graphqlhandler.Request{Query: query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewBuffer(body))
+	req.Header.Set(middleware.UserIDHeader, "1")
+	req.Header.Set(handler.WorkspaceIDHeader, "1")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	var res graphqlhandler.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return rec.Code, res
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		maxComplexity    int
+		want_status_code int
+		want_errors      bool
+	}{
+		{
+			name:             "正常系_タスク一覧と件数を一度に取得できること",
+			query:            `{ todos(filter: {status: DONE}, limit: 10) { id task status } todoCount todoCountsByStatus { status count } }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "正常系_タスクの登録ができること",
+			query:            `mutation { createTodo(task: "test") }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "異常系_クエリの複雑さが上限を超えた場合エラーになること",
+			query:            `{ todos(limit: 500) { id task status createdAt updatedAt } }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusBadRequest,
+			want_errors:      true,
+		},
+		{
+			name:             "異常系_負のlimitで他のフィールドの複雑さを相殺できないこと",
+			query:            `{ todos(limit: -1000) { id task status createdAt updatedAt } a: todoCountsByStatus { status count } b: todoCountsByStatus { status count } }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusBadRequest,
+			want_errors:      true,
+		},
+		{
+			name:             "異常系_エイリアスで多数のミューテーションを送った場合複雑さが上限を超えること",
+			query:            `mutation { a: deleteTodo(id: 1) b: deleteTodo(id: 2) c: deleteTodo(id: 3) }`,
+			maxComplexity:    100,
+			want_status_code: http.StatusBadRequest,
+			want_errors:      true,
+		},
+		{
+			name:             "異常系_構文エラーの場合エラーになること",
+			query:            `{ todos {`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusBadRequest,
+			want_errors:      true,
+		},
+		{
+			name:             "異常系_タスクが60文字を超える場合バリデーションエラーになること",
+			query:            `mutation { createTodo(task: "1234567890123456789012345678901234567890123456789012345678901") }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusOK,
+			want_errors:      true,
+		},
+		{
+			name:             "正常系_タスクを更新できること",
+			query:            `mutation { updateTodo(id: 1, task: "task", status: DONE) }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "異常系_更新するタスクが60文字を超える場合バリデーションエラーになること",
+			query:            `mutation { updateTodo(id: 1, task: "1234567890123456789012345678901234567890123456789012345678901", status: DONE) }`,
+			maxComplexity:    1000,
+			want_status_code: http.StatusOK,
+			want_errors:      true,
+		},
+		{
+			name:             "異常系_範囲外のlimitを指定した場合エラーになること",
+			query:            `{ todos(limit: 501) { id } }`,
+			maxComplexity:    100000,
+			want_status_code: http.StatusOK,
+			want_errors:      true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			code, res := serve(t, &mockTodo{}, tt.maxComplexity, tt.query)
+			if tt.want_status_code != code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, code)
+			}
+			if tt.want_errors != (len(res.Errors) > 0) {
+				t.Errorf("want errors = %v, got = %v", tt.want_errors, res.Errors)
+			}
+		})
+	}
+}
+
+func TestServeBatchesTodoLookups(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_同一リクエスト内のtodo検索がまとめて実行されること", func(t *testing.T) {
+		u := &mockTodo{}
+		code, res := serve(t, u, 1000, `{ a: todo(id: 1) { id } b: todo(id: 2) { id } c: todo(id: 3) { task } }`)
+		if code != http.StatusOK || len(res.Errors) > 0 {
+			t.Fatalf("unexpected response: %v %v", code, res.Errors)
+		}
+		if got := atomic.LoadInt32(&u.findByIDsCalls); got != 1 {
+			t.Errorf("want = %v, got = %v", 1, got)
+		}
+		data := res.Data.(map[string]interface{})
+		if data["b"].(map[string]interface{})["id"].(float64) != 2 {
+			t.Errorf("unexpected data: %v", data)
+		}
+	})
+}
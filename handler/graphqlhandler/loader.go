@@ -0,0 +1,69 @@
+package graphqlhandler
+
+import (
+	"app/domain/model"
+	"app/usecase"
+	"context"
+	"sync"
+)
+
+// todoLoader batches the todo(id:) lookups of one request into a single
+// FindByIDs call instead of querying the repository once per field.
+type todoLoader struct {
+	usecase usecase.Todo
+	actor   model.Actor
+
+	mu      sync.Mutex
+	pending []int
+	cache   map[int]*model.Todo
+	err     error
+}
+
+func newTodoLoader(u usecase.Todo, actor model.Actor) *todoLoader {
+	return &todoLoader{
+		usecase: u,
+		actor:   actor,
+		cache:   map[int]*model.Todo{},
+	}
+}
+
+// Load registers the id and returns a thunk; graphql-go resolves all thunks of
+// a level after every sibling field has been visited.
+func (l *todoLoader) Load(ctx context.Context, id int) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.cache[id]; !ok {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if len(l.pending) > 0 {
+			l.flush(ctx)
+		}
+		if l.err != nil {
+			return nil, l.err
+		}
+		if t := l.cache[id]; t != nil {
+			return t, nil
+		}
+		return nil, nil
+	}
+}
+
+func (l *todoLoader) flush(ctx context.Context) {
+	ids := l.pending
+	l.pending = nil
+	todos, err := l.usecase.FindByIDs(ctx, l.actor, ids)
+	if err != nil {
+		l.err = err
+		return
+	}
+	for _, id := range ids {
+		l.cache[id] = nil
+	}
+	for _, t := range todos {
+		l.cache[t.ID] = t
+	}
+}
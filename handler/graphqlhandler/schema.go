@@ -0,0 +1,232 @@
+package graphqlhandler
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/usecase"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type requestKey struct{}
+
+type request struct {
+	actor   model.Actor
+	usecase usecase.Todo
+	loader  *todoLoader
+}
+
+func withRequest(ctx context.Context, r *request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// taskStatusEnum is derived from model.TaskStatusMap so new statuses show up automatically.
+func taskStatusEnum() *graphql.Enum {
+	values := graphql.EnumValueConfigMap{}
+	for s := range model.TaskStatusMap {
+		values[strings.ToUpper(string(s))] = &graphql.EnumValueConfig{Value: s}
+	}
+	return graphql.NewEnum(graphql.EnumConfig{
+		Name:   "TaskStatus",
+		Values: values,
+	})
+}
+
+func NewSchema() (graphql.Schema, error) {
+	status := taskStatusEnum()
+
+	todo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: todoField(func(t *model.Todo) interface{} { return t.ID })},
+			"workspaceId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: todoField(func(t *model.Todo) interface{} { return t.WorkspaceID })},
+			"task":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: todoField(func(t *model.Todo) interface{} { return t.Task })},
+			"status":      &graphql.Field{Type: graphql.NewNonNull(status), Resolve: todoField(func(t *model.Todo) interface{} { return t.Status })},
+			"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: todoField(func(t *model.Todo) interface{} { return t.CreatedAt })},
+			"updatedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: todoField(func(t *model.Todo) interface{} { return t.UpdatedAt })},
+		},
+	})
+
+	statusCount := graphql.NewObject(graphql.ObjectConfig{
+		Name: "StatusCount",
+		Fields: graphql.Fields{
+			"status": &graphql.Field{Type: graphql.NewNonNull(status)},
+			"count":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	filter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "TodoFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"status": &graphql.InputObjectFieldConfig{Type: status},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todo": &graphql.Field{
+				Type: todo,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return requestFrom(p.Context).loader.Load(p.Context, p.Args["id"].(int)), nil
+				},
+			},
+			"todos": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todo))),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filter},
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					f, err := todoFilter(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					r := requestFrom(p.Context)
+					return r.usecase.Search(p.Context, r.actor, f)
+				},
+			},
+			"todoCount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filter},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					f, err := todoFilter(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					f.Limit, f.Offset = 0, 0
+					r := requestFrom(p.Context)
+					count, err := r.usecase.Count(p.Context, r.actor, f)
+					return int(count), err
+				},
+			},
+			"todoCountsByStatus": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(statusCount))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r := requestFrom(p.Context)
+					var res []map[string]interface{}
+					for _, s := range sortedStatuses() {
+						count, err := r.usecase.Count(p.Context, r.actor, model.TodoFilter{Status: s})
+						if err != nil {
+							return nil, err
+						}
+						res = append(res, map[string]interface{}{"status": s, "count": int(count)})
+					}
+					return res, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"task": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := handler.CreateRequestParam{Task: p.Args["task"].(string)}
+					if err := binding.Validator.ValidateStruct(&req); err != nil {
+						return nil, err
+					}
+					r := requestFrom(p.Context)
+					return true, r.usecase.Create(p.Context, r.actor, req.Task, req.ListID)
+				},
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"task":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"status": &graphql.ArgumentConfig{Type: graphql.NewNonNull(status)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					req := handler.UpdateRequestBodyParam{Task: p.Args["task"].(string), Status: p.Args["status"].(model.TaskStatus)}
+					if err := binding.Validator.ValidateStruct(&req); err != nil {
+						return nil, err
+					}
+					r := requestFrom(p.Context)
+					return true, r.usecase.Update(p.Context, r.actor, p.Args["id"].(int), req.Task, req.Status)
+				},
+			},
+			"deleteTodo": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					r := requestFrom(p.Context)
+					return true, r.usecase.Delete(p.Context, r.actor, p.Args["id"].(int))
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func todoField(f func(t *model.Todo) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		t, ok := p.Source.(*model.Todo)
+		if !ok {
+			return nil, nil
+		}
+		return f(t), nil
+	}
+}
+
+func todoFilter(args map[string]interface{}) (model.TodoFilter, error) {
+	f := model.TodoFilter{}
+	if filter, ok := args["filter"].(map[string]interface{}); ok {
+		if s, ok := filter["status"].(model.TaskStatus); ok {
+			f.Status = s
+		}
+	}
+	if limit, ok := args["limit"].(int); ok {
+		if limit < 1 || limit > maxLimit {
+			return f, errors.New("limit must be between 1 and 500")
+		}
+		f.Limit = limit
+	}
+	if offset, ok := args["offset"].(int); ok {
+		if offset < 0 {
+			return f, errors.New("offset must not be negative")
+		}
+		f.Offset = offset
+	}
+	return f, nil
+}
+
+func sortedStatuses() []model.TaskStatus {
+	res := make([]model.TaskStatus, 0, len(model.TaskStatusMap))
+	for s := range model.TaskStatusMap {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
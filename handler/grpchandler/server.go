@@ -0,0 +1,21 @@
+package grpchandler
+
+import (
+	todov1 "app/proto/todo/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func NewServer(todo todov1.TodoServiceServer) *grpc.Server {
+	s := grpc.NewServer()
+	todov1.RegisterTodoServiceServer(s, todo)
+
+	h := health.NewServer()
+	h.SetServingStatus(todov1.TodoService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, h)
+	reflection.Register(s)
+	return s
+}
@@ -0,0 +1,275 @@
+package grpchandler
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/usecase"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	todov1 "app/proto/todo/v1"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	UserIDMetadata      = "x-user-id"
+	WorkspaceIDMetadata = "x-workspace-id"
+
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+var statusToProto = map[model.TaskStatus]todov1.TaskStatus{
+	model.Created:    todov1.TaskStatus_TASK_STATUS_CREATED,
+	model.Processing: todov1.TaskStatus_TASK_STATUS_PROCESSING,
+	model.Done:       todov1.TaskStatus_TASK_STATUS_DONE,
+}
+
+var statusFromProto = map[todov1.TaskStatus]model.TaskStatus{
+	todov1.TaskStatus_TASK_STATUS_CREATED:    model.Created,
+	todov1.TaskStatus_TASK_STATUS_PROCESSING: model.Processing,
+	todov1.TaskStatus_TASK_STATUS_DONE:       model.Done,
+}
+
+type todoServer struct {
+	todov1.UnimplementedTodoServiceServer
+	usecase usecase.Todo
+}
+
+// NewTodo serves usecase.Todo over gRPC. Requests are validated with the
+// binding rules of the HTTP handler.
+func NewTodo(u usecase.Todo) todov1.TodoServiceServer {
+	return &todoServer{usecase: u}
+}
+
+func (s *todoServer) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest) (*todov1.CreateTodoResponse, error) {
+	actor, err := actorFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(&handler.CreateRequestParam{Task: req.GetTask()}); err != nil {
+		return nil, err
+	}
+	if err := s.usecase.Create(ctx, actor, req.GetTask(), 0); err != nil {
+		return nil, toStatus(err)
+	}
+	return &todov1.CreateTodoResponse{}, nil
+}
+
+func (s *todoServer) GetTodo(ctx context.Context, req *todov1.GetTodoRequest) (*todov1.Todo, error) {
+	actor, err := actorFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	res, err := s.usecase.Find(ctx, actor, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if res == nil {
+		return nil, status.Error(codes.NotFound, "todo not found")
+	}
+	return toProto(res), nil
+}
+
+func (s *todoServer) ListTodos(ctx context.Context, req *todov1.ListTodosRequest) (*todov1.ListTodosResponse, error) {
+	actor, err := actorFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	// One more todo than the page tells whether there is a next page.
+	todos, err := s.usecase.Search(ctx, actor, model.TodoFilter{Limit: size + 1, Offset: offset})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	res := &todov1.ListTodosResponse{}
+	if len(todos) > size {
+		todos = todos[:size]
+		res.NextPageToken = encodePageToken(offset + size)
+	}
+	for _, t := range todos {
+		res.Todos = append(res.Todos, toProto(t))
+	}
+	return res, nil
+}
+
+func (s *todoServer) UpdateTodo(ctx context.Context, req *todov1.UpdateTodoRequest) (*todov1.UpdateTodoResponse, error) {
+	actor, err := actorFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	st, ok := statusFromProto[req.GetStatus()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	if err := validate(&handler.UpdateRequestBodyParam{Task: req.GetTask(), Status: st}); err != nil {
+		return nil, err
+	}
+	if err := s.usecase.Update(ctx, actor, int(req.GetId()), req.GetTask(), st); err != nil {
+		return nil, toStatus(err)
+	}
+	return &todov1.UpdateTodoResponse{}, nil
+}
+
+func (s *todoServer) DeleteTodo(ctx context.Context, req *todov1.DeleteTodoRequest) (*todov1.DeleteTodoResponse, error) {
+	actor, err := actorFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.usecase.Delete(ctx, actor, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &todov1.DeleteTodoResponse{}, nil
+}
+
+// WatchTodos streams the changes of the workspace as they are published. A
+// watcher that cannot keep up is dropped with Unavailable and lists the
+// todos again after it reconnects.
+func (s *todoServer) WatchTodos(req *todov1.WatchTodosRequest, stream todov1.TodoService_WatchTodosServer) error {
+	ctx := stream.Context()
+	actor, err := actorFrom(ctx)
+	if err != nil {
+		return err
+	}
+	_, events, cancel, err := s.usecase.Subscribe(ctx, actor, 0)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "the watch fell behind the changes")
+			}
+			if err := stream.Send(toEvent(e)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var eventTypeToProto = map[usecase.TodoEventType]todov1.TodoEvent_Type{
+	usecase.TodoCreated: todov1.TodoEvent_TYPE_CREATED,
+	usecase.TodoUpdated: todov1.TodoEvent_TYPE_UPDATED,
+	usecase.TodoDeleted: todov1.TodoEvent_TYPE_DELETED,
+}
+
+func toEvent(e usecase.TodoEvent) *todov1.TodoEvent {
+	if e.Type == usecase.TodoDeleted {
+		return &todov1.TodoEvent{Type: todov1.TodoEvent_TYPE_DELETED, Todo: &todov1.Todo{Id: int64(e.Todo.ID)}}
+	}
+	return &todov1.TodoEvent{Type: eventTypeToProto[e.Type], Todo: toProto(&e.Todo)}
+}
+
+func toProto(t *model.Todo) *todov1.Todo {
+	return &todov1.Todo{
+		Id:          int64(t.ID),
+		WorkspaceId: int64(t.WorkspaceID),
+		Task:        t.Task,
+		Status:      statusToProto[t.Status],
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+	}
+}
+
+// validate applies the binding rules of the HTTP request param req.
+func validate(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+func actorFrom(ctx context.Context) (model.Actor, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	userID, err := metadataInt(md, UserIDMetadata)
+	if err != nil {
+		return model.Actor{}, status.Errorf(codes.Unauthenticated, "missing or invalid %s metadata", UserIDMetadata)
+	}
+	workspaceID, err := metadataInt(md, WorkspaceIDMetadata)
+	if err != nil {
+		return model.Actor{}, status.Errorf(codes.InvalidArgument, "missing or invalid %s metadata", WorkspaceIDMetadata)
+	}
+	return model.NewActor(userID, workspaceID), nil
+}
+
+func metadataInt(md metadata.MD, key string) (int, error) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return 0, errors.New("missing")
+	}
+	n, err := strconv.Atoi(values[0])
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid")
+	}
+	return n, nil
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, usecase.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, usecase.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// A page token is the offset of the next page.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	offset, err := strconv.Atoi(string(b))
+	if err != nil || offset < 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+	return offset, nil
+}
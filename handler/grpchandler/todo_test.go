@@ -0,0 +1,351 @@
+package grpchandler_test
+
+import (
+	"app/domain/model"
+	"app/handler/grpchandler"
+	"app/handler/validator"
+	"app/usecase"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	todov1 "app/proto/todo/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockTodo struct {
+	usecase.Todo
+	mockCreate func() error
+	mockUpdate func() error
+	mockDelete func() error
+	mockFind   func() (*model.Todo, error)
+	mockSearch func(f model.TodoFilter) ([]*model.Todo, error)
+	events     chan usecase.TodoEvent
+}
+
+func (m *mockTodo) Create(ctx context.Context, actor model.Actor, task string, listID int) error {
+	return m.mockCreate()
+}
+func (m *mockTodo) Update(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus) error {
+	return m.mockUpdate()
+}
+func (m *mockTodo) Delete(ctx context.Context, actor model.Actor, id int) error {
+	return m.mockDelete()
+}
+func (m *mockTodo) Find(ctx context.Context, actor model.Actor, id int) (*model.Todo, error) {
+	return m.mockFind()
+}
+func (m *mockTodo) Search(ctx context.Context, actor model.Actor, f model.TodoFilter) ([]*model.Todo, error) {
+	return m.mockSearch(f)
+}
+func (m *mockTodo) Subscribe(ctx context.Context, actor model.Actor, lastEventID uint64) ([]usecase.TodoEvent, <-chan usecase.TodoEvent, func(), error) {
+	return nil, m.events, func() {}, nil
+}
+
+func newClient(t *testing.T, u usecase.Todo) (todov1.TodoServiceClient, *grpc.ClientConn) {
+	t.Helper()
+	validator.SetupValidator()
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpchandler.NewServer(grpchandler.NewTodo(u))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return todov1.NewTodoServiceClient(conn), conn
+}
+
+func authContext() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(),
+		grpchandler.UserIDMetadata, "1",
+		grpchandler.WorkspaceIDMetadata, "1",
+	)
+}
+
+func TestCreateTodo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		request   *todov1.CreateTodoRequest
+		usecase   usecase.Todo
+		want_code codes.Code
+	}{
+		{
+			name:    "正常系_タスクの登録ができること",
+			ctx:     authContext(),
+			request: &todov1.CreateTodoRequest{Task: "test"},
+			usecase: &mockTodo{
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			want_code: codes.OK,
+		},
+		{
+			name:      "異常系_必須項目がなかった場合InvalidArgumentになること（task）",
+			ctx:       authContext(),
+			request:   &todov1.CreateTodoRequest{},
+			want_code: codes.InvalidArgument,
+		},
+		{
+			name:      "異常系_タスクが60文字を超える場合InvalidArgumentになること（task）",
+			ctx:       authContext(),
+			request:   &todov1.CreateTodoRequest{Task: strings.Repeat("あ", 61)},
+			want_code: codes.InvalidArgument,
+		},
+		{
+			name:      "異常系_ユーザーIDが指定されていなかった場合Unauthenticatedになること",
+			ctx:       context.Background(),
+			request:   &todov1.CreateTodoRequest{Task: "test"},
+			want_code: codes.Unauthenticated,
+		},
+		{
+			name:    "異常系_権限がなかった場合PermissionDeniedになること",
+			ctx:     authContext(),
+			request: &todov1.CreateTodoRequest{Task: "test"},
+			usecase: &mockTodo{
+				mockCreate: func() error {
+					return usecase.ErrForbidden
+				},
+			},
+			want_code: codes.PermissionDenied,
+		},
+		{
+			name:    "異常系_タスクの登録に失敗した場合Internalになること",
+			ctx:     authContext(),
+			request: &todov1.CreateTodoRequest{Task: "test"},
+			usecase: &mockTodo{
+				mockCreate: func() error {
+					return errors.New("xxxx error")
+				},
+			},
+			want_code: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, _ := newClient(t, tt.usecase)
+
+			_, err := client.CreateTodo(tt.ctx, tt.request)
+			if got := status.Code(err); got != tt.want_code {
+				t.Errorf("want = %v, got = %v", tt.want_code, got)
+			}
+		})
+	}
+}
+
+func TestGetTodo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		usecase   usecase.Todo
+		want_code codes.Code
+	}{
+		{
+			name: "正常系_タスクの検索ができること",
+			usecase: &mockTodo{
+				mockFind: func() (*model.Todo, error) {
+					return &model.Todo{ID: 1, Task: "task", Status: model.Done}, nil
+				},
+			},
+			want_code: codes.OK,
+		},
+		{
+			name: "異常系_タスクが存在しない場合NotFoundになること",
+			usecase: &mockTodo{
+				mockFind: func() (*model.Todo, error) {
+					return nil, nil
+				},
+			},
+			want_code: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, _ := newClient(t, tt.usecase)
+
+			res, err := client.GetTodo(authContext(), &todov1.GetTodoRequest{Id: 1})
+			if got := status.Code(err); got != tt.want_code {
+				t.Errorf("want = %v, got = %v", tt.want_code, got)
+			}
+			if err == nil && res.Status != todov1.TaskStatus_TASK_STATUS_DONE {
+				t.Errorf("want = %v, got = %v", todov1.TaskStatus_TASK_STATUS_DONE, res.Status)
+			}
+		})
+	}
+}
+
+func TestUpdateTodo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		request   *todov1.UpdateTodoRequest
+		usecase   usecase.Todo
+		want_code codes.Code
+	}{
+		{
+			name:    "正常系_タスクの更新ができること",
+			request: &todov1.UpdateTodoRequest{Id: 1, Task: "test", Status: todov1.TaskStatus_TASK_STATUS_DONE},
+			usecase: &mockTodo{
+				mockUpdate: func() error {
+					return nil
+				},
+			},
+			want_code: codes.OK,
+		},
+		{
+			name:      "異常系_ステータスが指定されていなかった場合InvalidArgumentになること（status）",
+			request:   &todov1.UpdateTodoRequest{Id: 1, Task: "test"},
+			want_code: codes.InvalidArgument,
+		},
+		{
+			name:    "異常系_タスクが存在しない場合NotFoundになること",
+			request: &todov1.UpdateTodoRequest{Id: 1, Task: "test", Status: todov1.TaskStatus_TASK_STATUS_DONE},
+			usecase: &mockTodo{
+				mockUpdate: func() error {
+					return usecase.ErrNotFound
+				},
+			},
+			want_code: codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, _ := newClient(t, tt.usecase)
+
+			_, err := client.UpdateTodo(authContext(), tt.request)
+			if got := status.Code(err); got != tt.want_code {
+				t.Errorf("want = %v, got = %v", tt.want_code, got)
+			}
+		})
+	}
+}
+
+func TestListTodos(t *testing.T) {
+	t.Parallel()
+	t.Run("ページトークンで続きのページが取得できること", func(t *testing.T) {
+		todos := []*model.Todo{{ID: 1}, {ID: 2}, {ID: 3}}
+		client, _ := newClient(t, &mockTodo{
+			mockSearch: func(f model.TodoFilter) ([]*model.Todo, error) {
+				// 次のページの有無を判定するため1件多く検索する
+				if f.Limit != 3 {
+					t.Errorf("want = %v, got = %v", 3, f.Limit)
+				}
+				end := f.Offset + f.Limit
+				if end > len(todos) {
+					end = len(todos)
+				}
+				return todos[f.Offset:end], nil
+			},
+		})
+
+		first, err := client.ListTodos(authContext(), &todov1.ListTodosRequest{PageSize: 2})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(first.Todos) != 2 || first.Todos[0].Id != 1 || first.NextPageToken == "" {
+			t.Fatalf("unexpected first page %v", first)
+		}
+		second, err := client.ListTodos(authContext(), &todov1.ListTodosRequest{PageSize: 2, PageToken: first.NextPageToken})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(second.Todos) != 1 || second.Todos[0].Id != 3 || second.NextPageToken != "" {
+			t.Errorf("unexpected second page %v", second)
+		}
+	})
+	t.Run("不正なページトークンの場合InvalidArgumentになること", func(t *testing.T) {
+		client, _ := newClient(t, &mockTodo{})
+		_, err := client.ListTodos(authContext(), &todov1.ListTodosRequest{PageToken: "LTE"})
+		if got := status.Code(err); got != codes.InvalidArgument {
+			t.Errorf("want = %v, got = %v", codes.InvalidArgument, got)
+		}
+	})
+}
+
+func TestWatchTodos(t *testing.T) {
+	t.Parallel()
+	t.Run("タスクの変更がストリームで通知されること", func(t *testing.T) {
+		events := make(chan usecase.TodoEvent, 3)
+		client, _ := newClient(t, &mockTodo{events: events})
+		ctx, cancel := context.WithTimeout(authContext(), 5*time.Second)
+		defer cancel()
+		stream, err := client.WatchTodos(ctx, &todov1.WatchTodosRequest{})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		events <- usecase.TodoEvent{ID: 1, Type: usecase.TodoUpdated, Todo: model.Todo{ID: 1, Task: "b"}}
+		events <- usecase.TodoEvent{ID: 2, Type: usecase.TodoCreated, Todo: model.Todo{ID: 2, Task: "c"}}
+		events <- usecase.TodoEvent{ID: 3, Type: usecase.TodoDeleted, Todo: model.Todo{ID: 1, Task: "b"}}
+
+		want := []*todov1.TodoEvent{
+			{Type: todov1.TodoEvent_TYPE_UPDATED, Todo: &todov1.Todo{Id: 1, Task: "b"}},
+			{Type: todov1.TodoEvent_TYPE_CREATED, Todo: &todov1.Todo{Id: 2, Task: "c"}},
+			{Type: todov1.TodoEvent_TYPE_DELETED, Todo: &todov1.Todo{Id: 1}},
+		}
+		for _, w := range want {
+			e, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+			if e.Type != w.Type || e.Todo.Id != w.Todo.Id || e.Todo.Task != w.Todo.Task {
+				t.Errorf("want = %v, got = %v", w, e)
+			}
+		}
+	})
+	t.Run("取りこぼした場合Unavailableで終了すること", func(t *testing.T) {
+		events := make(chan usecase.TodoEvent)
+		close(events)
+		client, _ := newClient(t, &mockTodo{events: events})
+		stream, err := client.WatchTodos(authContext(), &todov1.WatchTodosRequest{})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		_, err = stream.Recv()
+		if got := status.Code(err); got != codes.Unavailable {
+			t.Errorf("want = %v, got = %v", codes.Unavailable, got)
+		}
+	})
+}
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+	t.Run("ヘルスチェックがSERVINGを返すこと", func(t *testing.T) {
+		_, conn := newClient(t, &mockTodo{})
+		res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("want = %v, got = %v", healthpb.HealthCheckResponse_SERVING, res.Status)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"app/handler/todoformat"
+	"app/usecase"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MaxSourceImportSize is larger than maxImportSize since board exports
+// also carry the activity of the board.
+const MaxSourceImportSize = 50 << 20
+
+type ImportJob interface {
+	Start(c *gin.Context)
+	Find(c *gin.Context)
+}
+
+type importJobHandler struct {
+	usecase usecase.ImportJobs
+}
+
+func NewImportJob(u usecase.ImportJobs) ImportJob {
+	return &importJobHandler{u}
+}
+
+type ImportJobRequestQueryParam struct {
+	Format string `form:"format" binding:"required,oneof=trello todoist"`
+}
+
+type ImportJobRequestParam struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// ImportJobResponse is the progress of a job. Rejected lists the entries
+// that failed validation and is only returned when the job is started.
+type ImportJobResponse struct {
+	ID         string                  `json:"id"`
+	Source     string                  `json:"source"`
+	Status     usecase.ImportJobStatus `json:"status"`
+	Total      int                     `json:"total"`
+	Processed  int                     `json:"processed"`
+	Created    int                     `json:"created"`
+	Skipped    int                     `json:"skipped"`
+	Error      string                  `json:"error,omitempty"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+	Rejected   []ImportRejection       `json:"rejected,omitempty"`
+}
+
+func newImportJobResponse(job *usecase.ImportJob) ImportJobResponse {
+	res := ImportJobResponse{
+		ID:        job.ID,
+		Source:    job.Source,
+		Status:    job.Status,
+		Total:     job.Total,
+		Processed: job.Processed,
+		Created:   job.Created,
+		Skipped:   job.Skipped,
+		Error:     job.Error,
+		StartedAt: job.StartedAt,
+	}
+	if !job.FinishedAt.IsZero() {
+		res.FinishedAt = &job.FinishedAt
+	}
+	return res
+}
+
+// Start validates the entries of an export and imports the valid ones in
+// the background. Entries imported before, identified by their ID in the
+// other app, are skipped. The name of a board or project must also be a
+// valid list name.
+func (h *importJobHandler) Start(c *gin.Context) {
+	var req ImportJobRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, MaxSourceImportSize)
+	var records []todoformat.Record
+	var err error
+	switch req.Format {
+	case "trello":
+		records, err = todoformat.ReadTrello(body)
+	default:
+		records, err = todoformat.ReadTodoist(body)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var todos []usecase.SourcedTodo
+	var rejected []ImportRejection
+	for _, r := range records {
+		todo, err := validateRecord(r)
+		if err == nil && r.List != "" {
+			err = binding.Validator.ValidateStruct(&ListRequestBodyParam{Name: r.List})
+		}
+		if err != nil {
+			rejected = append(rejected, ImportRejection{Line: r.Line, Reason: err.Error()})
+			continue
+		}
+		todos = append(todos, usecase.SourcedTodo{SourceID: r.UID, List: r.List, Todo: todo})
+	}
+	job, err := h.usecase.Start(c.Request.Context(), actor, req.Format, todos)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	res := newImportJobResponse(job)
+	res.Rejected = rejected
+	c.Header("Location", "/todo/import/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, res)
+}
+
+func (h *importJobHandler) Find(c *gin.Context) {
+	var req ImportJobRequestParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	job, err := h.usecase.Find(c.Request.Context(), actor, req.ID)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newImportJobResponse(job))
+}
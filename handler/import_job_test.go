@@ -0,0 +1,191 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockImportJobs struct {
+	usecase.ImportJobs
+	mockStart func(source string, todos []usecase.SourcedTodo) (*usecase.ImportJob, error)
+	mockFind  func(id string) (*usecase.ImportJob, error)
+}
+
+func (m *mockImportJobs) Start(ctx context.Context, actor model.Actor, source string, todos []usecase.SourcedTodo) (*usecase.ImportJob, error) {
+	return m.mockStart(source, todos)
+}
+func (m *mockImportJobs) Find(ctx context.Context, actor model.Actor, id string) (*usecase.ImportJob, error) {
+	return m.mockFind(id)
+}
+
+func TestStartImportJob(t *testing.T) {
+	t.Parallel()
+	var started []usecase.SourcedTodo
+	tests := []struct {
+		name             string
+		query            string
+		body             string
+		usecase          usecase.ImportJobs
+		want_status_code int
+		want_todos       []usecase.SourcedTodo
+		want_rejected    []handler.ImportRejection
+	}{
+		{
+			name:  "正常系_有効なカードがインポート元のIDとともに渡されること",
+			query: "?format=trello",
+			body:  `{"name": "board", "cards": [{"id": "c1", "name": "task1", "closed": true}, {"id": "c2", "name": ""}]}`,
+			usecase: &mockImportJobs{
+				mockStart: func(source string, todos []usecase.SourcedTodo) (*usecase.ImportJob, error) {
+					if source != "trello" {
+						t.Errorf("want = %v, got = %v", "trello", source)
+					}
+					started = todos
+					return &usecase.ImportJob{ID: "job1", Source: source, Status: usecase.ImportJobRunning, Total: len(todos)}, nil
+				},
+			},
+			want_status_code: http.StatusAccepted,
+			want_todos:       []usecase.SourcedTodo{{SourceID: "trello:c1", List: "board", Todo: &model.Todo{Task: "task1", Status: model.Done}}},
+			want_rejected:    []handler.ImportRejection{{Line: 2}},
+		},
+		{
+			name:  "正常系_ボード名がリスト名として長すぎる場合カードが除外されること",
+			query: "?format=trello",
+			body:  `{"name": "` + strings.Repeat("a", 61) + `", "cards": [{"id": "c1", "name": "task1"}]}`,
+			usecase: &mockImportJobs{
+				mockStart: func(source string, todos []usecase.SourcedTodo) (*usecase.ImportJob, error) {
+					started = todos
+					return &usecase.ImportJob{ID: "job1", Source: source, Status: usecase.ImportJobRunning, Total: len(todos)}, nil
+				},
+			},
+			want_status_code: http.StatusAccepted,
+			want_rejected:    []handler.ImportRejection{{Line: 1}},
+		},
+		{
+			name:             "異常系_形式が不正な場合バリデーションエラーになること",
+			query:            "?format=csv",
+			body:             `{"items": []}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_エクスポートの形式と一致しない場合",
+			query:            "?format=todoist",
+			body:             `{"cards": []}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:  "異常系_権限がない場合",
+			query: "?format=todoist",
+			body:  `{"items": []}`,
+			usecase: &mockImportJobs{
+				mockStart: func(source string, todos []usecase.SourcedTodo) (*usecase.ImportJob, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewImportJob(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/todo/import/jobs", h.Start)
+			req := httptest.NewRequest("POST", "/todo/import/jobs"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Fatalf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusAccepted {
+				return
+			}
+			if got := rec.Header().Get("Location"); got != "/todo/import/jobs/job1" {
+				t.Errorf("want = %v, got = %v", "/todo/import/jobs/job1", got)
+			}
+			if !cmp.Equal(started, tt.want_todos) {
+				t.Errorf("diff %s", cmp.Diff(started, tt.want_todos))
+			}
+			var res handler.ImportJobResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			lines := make([]handler.ImportRejection, len(res.Rejected))
+			for i, r := range res.Rejected {
+				lines[i] = handler.ImportRejection{Line: r.Line}
+			}
+			if !cmp.Equal(lines, tt.want_rejected) {
+				t.Errorf("diff %s", cmp.Diff(lines, tt.want_rejected))
+			}
+		})
+	}
+}
+
+func TestFindImportJob(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		usecase          usecase.ImportJobs
+		want_status_code int
+	}{
+		{
+			name: "正常系_進捗が返ること",
+			usecase: &mockImportJobs{
+				mockFind: func(id string) (*usecase.ImportJob, error) {
+					return &usecase.ImportJob{ID: id, Status: usecase.ImportJobRunning, Total: 10, Processed: 5}, nil
+				},
+			},
+			want_status_code: http.StatusOK,
+		},
+		{
+			name: "異常系_ジョブが存在しない場合",
+			usecase: &mockImportJobs{
+				mockFind: func(id string) (*usecase.ImportJob, error) {
+					return nil, usecase.ErrNotFound
+				},
+			},
+			want_status_code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewImportJob(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.GET("/todo/import/jobs/:id", h.Find)
+			req := httptest.NewRequest("GET", "/todo/import/jobs/job1", nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"app/domain/model"
+	"app/handler/middleware"
+	"app/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Invitation interface {
+	Create(c *gin.Context)
+	FindAll(c *gin.Context)
+	Accept(c *gin.Context)
+	Decline(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type invitationHandler struct {
+	usecase usecase.Invitation
+}
+
+func NewInvitation(u usecase.Invitation) Invitation {
+	return &invitationHandler{u}
+}
+
+type CreateInvitationRequestBodyParam struct {
+	Role model.Role `json:"role" binding:"required,role"`
+}
+
+func (i *invitationHandler) Create(c *gin.Context) {
+	var pathParam WorkspaceRequestPathParam
+	var bodyParam CreateInvitationRequestBodyParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&bodyParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor := model.NewActor(middleware.UserID(c), pathParam.ID)
+	res, err := i.usecase.Create(c.Request.Context(), actor, bodyParam.Role)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+func (i *invitationHandler) FindAll(c *gin.Context) {
+	var req WorkspaceRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := i.usecase.FindAll(c.Request.Context(), model.NewActor(middleware.UserID(c), req.ID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+type InvitationRequestPathParam struct {
+	Token string `uri:"token" binding:"required"`
+}
+
+func (i *invitationHandler) Accept(c *gin.Context) {
+	var req InvitationRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := i.usecase.Accept(c.Request.Context(), middleware.UserID(c), req.Token); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (i *invitationHandler) Decline(c *gin.Context) {
+	var req InvitationRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := i.usecase.Decline(c.Request.Context(), req.Token); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (i *invitationHandler) Revoke(c *gin.Context) {
+	var req InvitationRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := i.usecase.Revoke(c.Request.Context(), middleware.UserID(c), req.Token); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
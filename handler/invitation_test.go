@@ -0,0 +1,174 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockInvitation struct {
+	usecase.Invitation
+	mockCreate  func() (*model.Invitation, error)
+	mockAccept  func() error
+	mockDecline func() error
+	mockRevoke  func() error
+}
+
+func (m *mockInvitation) Create(ctx context.Context, actor model.Actor, role model.Role) (*model.Invitation, error) {
+	return m.mockCreate()
+}
+func (m *mockInvitation) Accept(ctx context.Context, userID int, token string) error {
+	return m.mockAccept()
+}
+func (m *mockInvitation) Decline(ctx context.Context, token string) error {
+	return m.mockDecline()
+}
+func (m *mockInvitation) Revoke(ctx context.Context, userID int, token string) error {
+	return m.mockRevoke()
+}
+
+func TestInvitationCreate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		request          handler.CreateInvitationRequestBodyParam
+		usecase          usecase.Invitation
+		want_status_code int
+	}{
+		{
+			name:    "正常系_招待の作成ができること",
+			request: handler.CreateInvitationRequestBodyParam{Role: model.Viewer},
+			usecase: &mockInvitation{
+				mockCreate: func() (*model.Invitation, error) {
+					return &model.Invitation{ID: 1, Token: "token"}, nil
+				},
+			},
+			want_status_code: http.StatusCreated,
+		},
+		{
+			name:             "異常系_必須項目がなかった場合バリデーションエラーになること（role）",
+			request:          handler.CreateInvitationRequestBodyParam{},
+			want_status_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewInvitation(tt.usecase)
+			reqJSON, _ := json.Marshal(tt.request)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/:id/invitations", h.Create)
+			req := httptest.NewRequest("POST", "/1/invitations", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.UserIDHeader, "1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestInvitationActions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		action           string
+		usecase          usecase.Invitation
+		want_status_code int
+	}{
+		{
+			name:   "正常系_招待の承諾ができること",
+			action: "accept",
+			usecase: &mockInvitation{
+				mockAccept: func() error {
+					return nil
+				},
+			},
+			want_status_code: http.StatusNoContent,
+		},
+		{
+			name:   "異常系_有効期限切れの招待を承諾した場合410エラーになること",
+			action: "accept",
+			usecase: &mockInvitation{
+				mockAccept: func() error {
+					return usecase.ErrInvitationExpired
+				},
+			},
+			want_status_code: http.StatusGone,
+		},
+		{
+			name:   "正常系_招待の辞退ができること",
+			action: "decline",
+			usecase: &mockInvitation{
+				mockDecline: func() error {
+					return nil
+				},
+			},
+			want_status_code: http.StatusNoContent,
+		},
+		{
+			name:   "異常系_存在しない招待を辞退した場合404エラーになること",
+			action: "decline",
+			usecase: &mockInvitation{
+				mockDecline: func() error {
+					return usecase.ErrNotFound
+				},
+			},
+			want_status_code: http.StatusNotFound,
+		},
+		{
+			name:   "異常系_権限がない場合招待の取り消しが403エラーになること",
+			action: "revoke",
+			usecase: &mockInvitation{
+				mockRevoke: func() error {
+					return usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewInvitation(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+
+			r.POST("/:token/accept", h.Accept)
+			r.POST("/:token/decline", h.Decline)
+			r.POST("/:token/revoke", h.Revoke)
+			req := httptest.NewRequest("POST", "/token/"+tt.action, nil)
+			req.Header.Set(middleware.UserIDHeader, "1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"app/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type List interface {
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	Find(c *gin.Context)
+	FindAll(c *gin.Context)
+}
+
+type listHandler struct {
+	usecase usecase.List
+}
+
+func NewList(u usecase.List) List {
+	return &listHandler{u}
+}
+
+type ListRequestBodyParam struct {
+	Name string `json:"name" binding:"required,max=60"`
+}
+
+func (l *listHandler) Create(c *gin.Context) {
+	var req ListRequestBodyParam
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := l.usecase.Create(c.Request.Context(), actor, req.Name)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+type ListRequestPathParam struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+func (l *listHandler) Update(c *gin.Context) {
+	var pathParam ListRequestPathParam
+	var bodyParam ListRequestBodyParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&bodyParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := l.usecase.Update(c.Request.Context(), actor, pathParam.ID, bodyParam.Name); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (l *listHandler) Delete(c *gin.Context) {
+	var req ListRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := l.usecase.Delete(c.Request.Context(), actor, req.ID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (l *listHandler) Find(c *gin.Context) {
+	var req ListRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := l.usecase.Find(c.Request.Context(), actor, req.ID)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (l *listHandler) FindAll(c *gin.Context) {
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := l.usecase.FindAll(c.Request.Context(), actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,140 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockList struct {
+	usecase.List
+	mockCreate func() (*model.List, error)
+	mockDelete func() error
+}
+
+func (m *mockList) Create(ctx context.Context, actor model.Actor, name string) (*model.List, error) {
+	return m.mockCreate()
+}
+func (m *mockList) Delete(ctx context.Context, actor model.Actor, id int) error {
+	return m.mockDelete()
+}
+
+func TestListCreate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		body             string
+		usecase          usecase.List
+		want_status_code int
+	}{
+		{
+			name: "正常系_リストの作成ができること",
+			body: `{"name": "買い物"}`,
+			usecase: &mockList{
+				mockCreate: func() (*model.List, error) {
+					return &model.List{ID: 1, WorkspaceID: 1, Name: "買い物"}, nil
+				},
+			},
+			want_status_code: http.StatusCreated,
+		},
+		{
+			name:             "異常系_名前がない場合バリデーションエラーになること",
+			body:             `{}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_権限がない場合",
+			body: `{"name": "買い物"}`,
+			usecase: &mockList{
+				mockCreate: func() (*model.List, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewList(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/", h.Create)
+			req := httptest.NewRequest("POST", "/", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestListDelete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		usecase          usecase.List
+		want_status_code int
+	}{
+		{
+			name: "正常系_リストの削除ができること",
+			usecase: &mockList{
+				mockDelete: func() error {
+					return nil
+				},
+			},
+			want_status_code: http.StatusNoContent,
+		},
+		{
+			name: "異常系_タスクが残っている場合",
+			usecase: &mockList{
+				mockDelete: func() error {
+					return fmt.Errorf("%w: the list still has 2 todos", usecase.ErrConflict)
+				},
+			},
+			want_status_code: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewList(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+
+			r.DELETE("/:id", h.Delete)
+			req := httptest.NewRequest("DELETE", "/1", nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDHeader carries the user authenticated by the gateway in front of this API.
+const UserIDHeader = "X-User-ID"
+
+const userIDKey = "user_id"
+
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.GetHeader(UserIDHeader))
+		if err != nil || id <= 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid " + UserIDHeader + " header"})
+			return
+		}
+		c.Set(userIDKey, id)
+		c.Next()
+	}
+}
+
+func UserID(c *gin.Context) int {
+	return c.GetInt(userIDKey)
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"app/domain/model"
+	"app/usecase"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// WorkspaceIDHeader is part of the fingerprint, since the same body
+	// does different things in different workspaces.
+	WorkspaceIDHeader = "X-Workspace-ID"
+)
+
+// Idempotency buffers the body of keyed requests to fingerprint them, so
+// maxBodySize must be at least the largest body a handler of the group
+// accepts; larger requests get 413.
+func Idempotency(u usecase.Idempotency, maxBodySize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": IdempotencyKeyHeader + " header is too long"})
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := u.Begin(c.Request.Context(), UserID(c), key, fingerprint(c.Request, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, usecase.ErrIdempotencyKeyMismatch):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			case errors.Is(err, usecase.ErrIdempotencyKeyInFlight):
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+		if record.Status == model.Completed {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.ResponseCode, record.ResponseContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		// The record is settled even when the request was cancelled, so that
+		// the key is not held until it expires.
+		ctx := context.Background()
+		// Server errors are not stored, since a retry may succeed.
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			if err := u.Release(ctx, record); err != nil {
+				c.Error(err)
+			}
+			return
+		}
+		if err := u.Complete(ctx, record, c.Writer.Status(), c.Writer.Header().Get("Content-Type"), w.body.Bytes()); err != nil {
+			c.Error(err)
+		}
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write([]byte(r.Header.Get(WorkspaceIDHeader) + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"app/domain/model"
+	"app/handler/middleware"
+	"app/usecase"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeIdempotency struct {
+	usecase.Idempotency
+	mu   sync.Mutex
+	keys map[string]*model.IdempotencyKey
+}
+
+func (f *fakeIdempotency) Begin(ctx context.Context, userID int, key string, fingerprint string) (*model.IdempotencyKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if k, ok := f.keys[key]; ok {
+		if k.Fingerprint != fingerprint {
+			return nil, usecase.ErrIdempotencyKeyMismatch
+		}
+		if k.Status == model.InFlight {
+			return nil, usecase.ErrIdempotencyKeyInFlight
+		}
+		return k, nil
+	}
+	k := &model.IdempotencyKey{Key: key, Fingerprint: fingerprint, Status: model.InFlight}
+	f.keys[key] = k
+	return k, nil
+}
+func (f *fakeIdempotency) Complete(ctx context.Context, k *model.IdempotencyKey, code int, contentType string, body []byte) error {
+	k.Status = model.Completed
+	k.ResponseCode = code
+	k.ResponseContentType = contentType
+	k.ResponseBody = body
+	return nil
+}
+func (f *fakeIdempotency) Release(ctx context.Context, k *model.IdempotencyKey) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.keys, k.Key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Parallel()
+
+	const maxBodySize = 64
+
+	type call struct {
+		key              string
+		workspaceID      string
+		body             string
+		want_status_code int
+		want_replayed    bool
+	}
+	tests := []struct {
+		name       string
+		failFirst  bool
+		calls      []call
+		want_calls int
+	}{
+		{
+			name: "正常系_同じキーの再送は保存されたレスポンスが返ること",
+			calls: []call{
+				{key: "k1", body: `{"task":"a"}`, want_status_code: http.StatusCreated},
+				{key: "k1", body: `{"task":"a"}`, want_status_code: http.StatusCreated, want_replayed: true},
+			},
+			want_calls: 1,
+		},
+		{
+			name: "異常系_同じキーで異なるリクエストの場合422エラーになること",
+			calls: []call{
+				{key: "k1", body: `{"task":"a"}`, want_status_code: http.StatusCreated},
+				{key: "k1", body: `{"task":"b"}`, want_status_code: http.StatusUnprocessableEntity},
+			},
+			want_calls: 1,
+		},
+		{
+			name: "正常系_キーがない場合は毎回処理されること",
+			calls: []call{
+				{body: `{"task":"a"}`, want_status_code: http.StatusCreated},
+				{body: `{"task":"a"}`, want_status_code: http.StatusCreated},
+			},
+			want_calls: 2,
+		},
+		{
+			name:      "正常系_サーバーエラーの場合は再送で再度処理されること",
+			failFirst: true,
+			calls: []call{
+				{key: "k1", body: `{"task":"a"}`, want_status_code: http.StatusInternalServerError},
+				{key: "k1", body: `{"task":"a"}`, want_status_code: http.StatusCreated},
+			},
+			want_calls: 2,
+		},
+		{
+			name: "異常系_同じキーで別のワークスペースへのリクエストの場合422エラーになること",
+			calls: []call{
+				{key: "k1", workspaceID: "1", body: `{"task":"a"}`, want_status_code: http.StatusCreated},
+				{key: "k1", workspaceID: "2", body: `{"task":"a"}`, want_status_code: http.StatusUnprocessableEntity},
+			},
+			want_calls: 1,
+		},
+		{
+			name: "異常系_ボディが上限を超える場合413エラーになること",
+			calls: []call{
+				{key: "k1", body: `{"task":"` + strings.Repeat("a", maxBodySize) + `"}`, want_status_code: http.StatusRequestEntityTooLarge},
+			},
+			want_calls: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			calls := 0
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			r.Use(middleware.Idempotency(&fakeIdempotency{keys: map[string]*model.IdempotencyKey{}}, maxBodySize))
+			r.POST("/", func(c *gin.Context) {
+				calls++
+				if tt.failFirst && calls == 1 {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "xxxx error"})
+					return
+				}
+				c.JSON(http.StatusCreated, gin.H{"id": calls})
+			})
+
+			var first string
+			for _, cl := range tt.calls {
+				req := httptest.NewRequest("POST", "/", strings.NewReader(cl.body))
+				req.Header.Set(middleware.UserIDHeader, "1")
+				if cl.key != "" {
+					req.Header.Set(middleware.IdempotencyKeyHeader, cl.key)
+				}
+				if cl.workspaceID != "" {
+					req.Header.Set(middleware.WorkspaceIDHeader, cl.workspaceID)
+				}
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+
+				if cl.want_status_code != rec.Code {
+					t.Errorf("want = %v, got = %v", cl.want_status_code, rec.Code)
+				}
+				replayed := rec.Header().Get(middleware.IdempotentReplayedHeader) == "true"
+				if cl.want_replayed != replayed {
+					t.Errorf("want = %v, got = %v", cl.want_replayed, replayed)
+				}
+				if cl.want_replayed && rec.Body.String() != first {
+					t.Errorf("want = %v, got = %v", first, rec.Body.String())
+				}
+				if first == "" {
+					first = rec.Body.String()
+				}
+			}
+			if tt.want_calls != calls {
+				t.Errorf("want = %v, got = %v", tt.want_calls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"app/config"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// RateLimitStore keeps the token buckets. The in-process store is enough for a
+// single instance; a shared store (e.g. Redis) can implement this interface later.
+type RateLimitStore interface {
+	Take(key string, limit config.RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+func RateLimit(store RateLimitStore, group string, limit config.RateLimit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit.RequestsPerMinute <= 0 {
+			c.Next()
+			return
+		}
+		res, err := store.Take(group+":"+rateLimitKey(c), limit, time.Now())
+		if err != nil {
+			// A store failure lets the request through rather than stopping
+			// the whole API.
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey picks the bucket from the authenticated user and never from
+// a header the client chooses freely, which would give every request a
+// fresh bucket.
+func rateLimitKey(c *gin.Context) string {
+	if id := UserID(c); id != 0 {
+		return "user:" + strconv.Itoa(id)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will have refilled to its capacity.
+	full time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+}
+
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (s *memoryRateLimitStore) Take(key string, limit config.RateLimit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rate := float64(limit.RequestsPerMinute) / 60
+	capacity := float64(limit.Burst)
+	if capacity < 1 {
+		capacity = 1
+	}
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// sweep drops buckets idle long enough to have refilled, so that the map does
+// not grow with every client that ever called the API. A dropped bucket is
+// the same as the full one created on the next request; a bucket that is
+// still refilling is kept however long it has been idle, since a slow rate
+// can take longer than any fixed idle time.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < time.Minute {
+		return
+	}
+	s.sweptAt = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,131 @@
+package middleware_test
+
+import (
+	"app/config"
+	"app/handler/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	limit := config.RateLimit{RequestsPerMinute: 60, Burst: 2}
+	tests := []struct {
+		name              string
+		headers           []map[string]string
+		want_status_codes []int
+	}{
+		{
+			name: "正常系_バースト数までのリクエストは許可されること",
+			headers: []map[string]string{
+				{middleware.UserIDHeader: "1"},
+				{middleware.UserIDHeader: "1"},
+			},
+			want_status_codes: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "異常系_バースト数を超えたリクエストは429エラーになること",
+			headers: []map[string]string{
+				{middleware.UserIDHeader: "1"},
+				{middleware.UserIDHeader: "1"},
+				{middleware.UserIDHeader: "1"},
+			},
+			want_status_codes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name: "正常系_ユーザーごとに別々に制限されること",
+			headers: []map[string]string{
+				{middleware.UserIDHeader: "1"},
+				{middleware.UserIDHeader: "1"},
+				{middleware.UserIDHeader: "2"},
+			},
+			want_status_codes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "異常系_APIキーのヘッダーを変えても制限を回避できないこと",
+			headers: []map[string]string{
+				{"X-API-Key": "a", middleware.UserIDHeader: "1"},
+				{"X-API-Key": "b", middleware.UserIDHeader: "1"},
+				{"X-API-Key": "c", middleware.UserIDHeader: "1"},
+			},
+			want_status_codes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			r.Use(middleware.RateLimit(middleware.NewMemoryRateLimitStore(), "todo", limit))
+			r.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for i, header := range tt.headers {
+				req := httptest.NewRequest("GET", "/", nil)
+				for k, v := range header {
+					req.Header.Set(k, v)
+				}
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+
+				if tt.want_status_codes[i] != rec.Code {
+					t.Errorf("want = %v, got = %v", tt.want_status_codes[i], rec.Code)
+				}
+				if rec.Header().Get("RateLimit-Limit") != "2" {
+					t.Errorf("want = %v, got = %v", "2", rec.Header().Get("RateLimit-Limit"))
+				}
+				if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "1" {
+					t.Errorf("want = %v, got = %v", "1", rec.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	t.Parallel()
+	t.Run("時間経過でトークンが補充されること", func(t *testing.T) {
+		store := middleware.NewMemoryRateLimitStore()
+		limit := config.RateLimit{RequestsPerMinute: 60, Burst: 1}
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		if res, _ := store.Take("key", limit, now); !res.Allowed {
+			t.Errorf("want = %v, got = %v", true, res.Allowed)
+		}
+		res, _ := store.Take("key", limit, now.Add(500*time.Millisecond))
+		if res.Allowed {
+			t.Errorf("want = %v, got = %v", false, res.Allowed)
+		}
+		if res.RetryAfter != 500*time.Millisecond {
+			t.Errorf("want = %v, got = %v", 500*time.Millisecond, res.RetryAfter)
+		}
+		if res, _ := store.Take("key", limit, now.Add(time.Second)); !res.Allowed {
+			t.Errorf("want = %v, got = %v", true, res.Allowed)
+		}
+	})
+	t.Run("補充中のバケットは長時間アイドルでも破棄されないこと", func(t *testing.T) {
+		store := middleware.NewMemoryRateLimitStore()
+		// 空のバケットが満タンになるまで20分かかる
+		limit := config.RateLimit{RequestsPerMinute: 1, Burst: 20}
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		for i := 0; i < 20; i++ {
+			store.Take("key", limit, now)
+		}
+		// 別のキーのリクエストで掃除を走らせる
+		store.Take("other", limit, now.Add(11*time.Minute))
+		res, _ := store.Take("key", limit, now.Add(11*time.Minute))
+		if res.Remaining != 10 {
+			t.Errorf("want = %v, got = %v", 10, res.Remaining)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"app/handler/middleware"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    error
+	}{
+		{
+			name:    "正常系_期限を過ぎるとリクエストのコンテキストがキャンセルされること",
+			timeout: 10 * time.Millisecond,
+			want:    context.DeadlineExceeded,
+		},
+		{
+			name:    "正常系_0の場合はコンテキストに期限が設定されないこと",
+			timeout: 0,
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Timeout(tt.timeout))
+			var got error
+			r.GET("/", func(c *gin.Context) {
+				ctx := c.Request.Context()
+				if _, ok := ctx.Deadline(); ok {
+					<-ctx.Done()
+				}
+				got = ctx.Err()
+				c.Status(http.StatusOK)
+			})
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if !errors.Is(got, tt.want) {
+				t.Errorf("want = %v, got = %v", tt.want, got)
+			}
+		})
+	}
+}
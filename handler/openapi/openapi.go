@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"app/handler"
+	"app/handler/middleware"
+	_ "embed"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+var pathParam = regexp.MustCompile(`:([^/]+)`)
+
+func NewDocument() *Document {
+	g := &generator{schemas: map[string]*Schema{}}
+	errorSchema := g.schemaOf(reflect.TypeOf(ErrorResponse{}))
+
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   "go-api-sample-todo",
+			Version: "1.0.0",
+		},
+		Paths: map[string]*PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"user": {
+					Type:        "apiKey",
+					In:          "header",
+					Name:        middleware.UserIDHeader,
+					Description: "User authenticated by the gateway in front of this API",
+				},
+			},
+		},
+		Security: []map[string][]string{{"user": {}}},
+	}
+	for _, rt := range routes {
+		op := &Operation{
+			OperationID: operationID(rt),
+			Summary:     rt.summary,
+			Tags:        []string{rt.tag},
+			Parameters:  append(g.parametersOf(rt.params), g.parametersOf(rt.query)...),
+			Responses:   map[string]*Response{},
+		}
+		if rt.workspace {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        handler.WorkspaceIDHeader,
+				In:          "header",
+				Description: "Workspace the tasks belong to",
+				Required:    true,
+				Schema:      &Schema{Type: "integer"},
+			})
+		}
+		if rt.method != http.MethodGet {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        middleware.IdempotencyKeyHeader,
+				In:          "header",
+				Description: "Replays the stored response when the request is retried",
+				Schema:      &Schema{Type: "string", MaxLength: intPtr(255)},
+			})
+		}
+		if rt.body != nil {
+			bodyMediaType := rt.bodyMediaType
+			if bodyMediaType == "" {
+				bodyMediaType = "application/json"
+			}
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{bodyMediaType: {Schema: g.schemaOf(reflect.TypeOf(rt.body))}},
+			}
+		}
+
+		success := &Response{Description: http.StatusText(rt.status)}
+		if rt.response != nil {
+			mediaType := rt.mediaType
+			if mediaType == "" {
+				mediaType = "application/json"
+			}
+			success.Content = map[string]MediaType{mediaType: {Schema: g.schemaOf(reflect.TypeOf(rt.response))}}
+		}
+		op.Responses[strconv.Itoa(rt.status)] = success
+
+		codes := append([]int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusInternalServerError}, rt.errors...)
+		if rt.method != http.MethodGet {
+			codes = append(codes, http.StatusConflict, http.StatusUnprocessableEntity)
+		}
+		for _, code := range codes {
+			res := &Response{
+				Description: http.StatusText(code),
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			}
+			if code == http.StatusTooManyRequests {
+				res.Headers = map[string]*Header{"Retry-After": {Schema: &Schema{Type: "integer"}}}
+			}
+			op.Responses[strconv.Itoa(code)] = res
+		}
+
+		path := pathParam.ReplaceAllString(rt.path, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = &PathItem{}
+		}
+		(*doc.Paths[path])[strings.ToLower(rt.method)] = op
+	}
+	return doc
+}
+
+// Register serves the document and a Swagger UI page for it. The Swagger
+// UI bundle is embedded, so the page works without CDN access. It has to
+// be called before authentication middleware is attached to the engine.
+func Register(r gin.IRoutes) {
+	doc := NewDocument()
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	r.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+	})
+	r.StaticFS("/docs/ui", http.FS(swaggerFiles.FS))
+}
+
+func operationID(rt route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.method))
+	for _, seg := range strings.Split(rt.path, "/") {
+		seg = strings.TrimPrefix(seg, ":")
+		for _, part := range strings.FieldsFunc(seg, func(r rune) bool { return r == '_' || r == '-' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func intPtr(n int) *int {
+	return &n
+}
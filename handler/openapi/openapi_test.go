@@ -0,0 +1,106 @@
+package openapi_test
+
+import (
+	"app/handler/openapi"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewDocument(t *testing.T) {
+	t.Parallel()
+	doc := openapi.NewDocument()
+
+	t.Run("バインディングの制約がスキーマに反映されること", func(t *testing.T) {
+		s := doc.Components.Schemas["UpdateRequestBodyParam"]
+		if s == nil {
+			t.Fatalf("UpdateRequestBodyParam schema is missing")
+		}
+		if !cmp.Equal(s.Required, []string{"task", "status"}) {
+			t.Errorf("diff %s", cmp.Diff(s.Required, []string{"task", "status"}))
+		}
+		if s.Properties["task"].MaxLength == nil || *s.Properties["task"].MaxLength != 60 {
+			t.Errorf("want = %v, got = %v", 60, s.Properties["task"].MaxLength)
+		}
+		want := []string{"created", "done", "processing"}
+		if !cmp.Equal(s.Properties["status"].Enum, want) {
+			t.Errorf("diff %s", cmp.Diff(s.Properties["status"].Enum, want))
+		}
+	})
+	t.Run("パスパラメータがOpenAPIの形式で出力されること", func(t *testing.T) {
+		item := doc.Paths["/todo/{id}"]
+		if item == nil {
+			t.Fatalf("/todo/{id} is missing")
+		}
+		op := (*item)["get"]
+		if op == nil || len(op.Parameters) == 0 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+			t.Errorf("unexpected parameters %+v", op)
+		}
+		if op.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/Todo" {
+			t.Errorf("unexpected response %+v", op.Responses["200"])
+		}
+	})
+	t.Run("埋め込まれた構造体のクエリパラメータが出力されること", func(t *testing.T) {
+		op := (*doc.Paths["/todo/export"])["get"]
+		names := map[string]bool{}
+		for _, p := range op.Parameters {
+			if p.In == "query" {
+				names[p.Name] = p.Required
+			}
+		}
+		want := map[string]bool{"format": true, "status": false, "created_from": false, "created_to": false}
+		if !cmp.Equal(names, want) {
+			t.Errorf("diff %s", cmp.Diff(names, want))
+		}
+	})
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	openapi.Register(r)
+
+	tests := []struct {
+		name             string
+		path             string
+		want_status_code int
+		want_body        string
+	}{
+		{
+			name:             "正常系_ドキュメントページがSwagger UIを読み込むこと",
+			path:             "/docs",
+			want_status_code: http.StatusOK,
+			want_body:        "docs/ui/swagger-ui-bundle.js",
+		},
+		{
+			name:             "正常系_Swagger UIのバンドルが配信されること",
+			path:             "/docs/ui/swagger-ui-bundle.js",
+			want_status_code: http.StatusOK,
+			want_body:        "SwaggerUIBundle",
+		},
+		{
+			name:             "異常系_存在しないファイルの場合404になること",
+			path:             "/docs/ui/missing.js",
+			want_status_code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.want_status_code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.want_body) {
+				t.Errorf("want body containing %q", tt.want_body)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/graphqlhandler"
+	"net/http"
+)
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type route struct {
+	method   string
+	path     string
+	summary  string
+	tag      string
+	params   any
+	query    any
+	body     any
+	status   int
+	response any
+	// mediaType of the success response; application/json when empty.
+	mediaType string
+	workspace bool
+	errors    []int
+	// bodyMediaType of the request body; application/json when empty.
+	bodyMediaType string
+}
+
+// routes lists every endpoint registered in setupRouter. A test in the main
+// package fails when the two drift apart.
+var routes = []route{
+	{
+		method: http.MethodPost, path: "/todo", summary: "Create a new task", tag: "todo",
+		body: handler.CreateRequestParam{}, status: http.StatusCreated,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/todo", summary: "Get all task list", tag: "todo",
+		query: handler.FindAllRequestQueryParam{}, status: http.StatusOK, response: []*model.Todo{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodPost, path: "/todo/bulk", summary: "Create, update and delete tasks in one request", tag: "todo",
+		body: handler.BulkRequestBodyParam{}, status: http.StatusOK, response: handler.BulkResponse{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodPost, path: "/todo/actions/set-status", summary: "Change the status of every task matching a filter", tag: "todo",
+		body: handler.SetStatusRequestBodyParam{}, status: http.StatusOK, response: handler.SetStatusResponse{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/todo/export.csv", summary: "Export tasks as CSV", tag: "todo",
+		query: handler.ExportRequestQueryParam{}, status: http.StatusOK, response: "", mediaType: "text/csv",
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/todo/calendar.ics", summary: "Export tasks as iCalendar VTODO components", tag: "todo",
+		query: handler.ExportRequestQueryParam{}, status: http.StatusOK, response: "", mediaType: "text/calendar",
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/todo/export", summary: "Export tasks as CSV, todo.txt, a Markdown task list or iCalendar", tag: "todo",
+		query: handler.ExportFormatRequestQueryParam{}, status: http.StatusOK, response: "", mediaType: "text/plain",
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodPost, path: "/todo/import", summary: "Import tasks from a CSV, todo.txt, Markdown or iCalendar file", tag: "todo",
+		query: handler.ImportRequestQueryParam{}, body: "", bodyMediaType: "text/plain", status: http.StatusOK, response: handler.ImportResponse{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge},
+	},
+	{
+		method: http.MethodPost, path: "/todo/import/jobs", summary: "Start importing a Trello board or Todoist backup in the background", tag: "todo",
+		query: handler.ImportJobRequestQueryParam{}, body: map[string]any{}, status: http.StatusAccepted, response: handler.ImportJobResponse{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge},
+	},
+	{
+		method: http.MethodGet, path: "/todo/import/jobs/:id", summary: "Get the progress of an import job", tag: "todo",
+		params: handler.ImportJobRequestParam{}, status: http.StatusOK, response: handler.ImportJobResponse{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/todo/events", summary: "Stream task changes as server-sent events", tag: "todo",
+		params: handler.TodoEventsRequestParam{}, status: http.StatusOK, response: model.Todo{}, mediaType: "text/event-stream",
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/todo/:id", summary: "Get a task", tag: "todo",
+		params: handler.FindRequestParam{}, query: handler.FindRequestQueryParam{}, status: http.StatusOK, response: model.Todo{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/todo/:id/history", summary: "Get the change history of a task", tag: "todo",
+		params: handler.FindRequestParam{}, query: handler.HistoryRequestQueryParam{}, status: http.StatusOK, response: []*model.TodoHistory{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/todo/:id/diff", summary: "Get the field-level diff between two revisions of a task", tag: "todo",
+		params: handler.FindRequestParam{}, query: handler.DiffRequestQueryParam{}, status: http.StatusOK, response: model.TodoDiff{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/todo/:id/revert", summary: "Restore a revision of a task as a new update", tag: "todo",
+		params: handler.FindRequestParam{}, body: handler.RevertRequestBodyParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/activity", summary: "Get the task changes of the workspace, newest first", tag: "todo",
+		query: handler.ActivityRequestQueryParam{}, status: http.StatusOK, response: []*model.TodoHistory{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodPut, path: "/todo/:id", summary: "Update a task", tag: "todo",
+		params: handler.UpdateRequestPathParam{}, body: handler.UpdateRequestBodyParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/todo/:id/list", summary: "Move a task into a list or out of its list", tag: "todo",
+		params: handler.UpdateRequestPathParam{}, body: handler.MoveRequestBodyParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/todo/:id", summary: "Delete a task", tag: "todo",
+		params: handler.DeleteRequestParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/lists", summary: "Create a list", tag: "lists",
+		body: handler.ListRequestBodyParam{}, status: http.StatusCreated, response: model.List{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/lists", summary: "Get all lists", tag: "lists",
+		status: http.StatusOK, response: []*model.List{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/lists/:id", summary: "Get a list", tag: "lists",
+		params: handler.ListRequestPathParam{}, status: http.StatusOK, response: model.List{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/lists/:id", summary: "Rename a list", tag: "lists",
+		params: handler.ListRequestPathParam{}, body: handler.ListRequestBodyParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/lists/:id", summary: "Delete an empty list", tag: "lists",
+		params: handler.ListRequestPathParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		method: http.MethodPost, path: "/workspaces", summary: "Create a new workspace", tag: "workspaces",
+		body: handler.CreateWorkspaceRequestParam{}, status: http.StatusCreated, response: model.Workspace{},
+		errors: []int{http.StatusBadRequest},
+	},
+	{
+		method: http.MethodGet, path: "/workspaces/:id/members", summary: "Get all members of a workspace", tag: "workspaces",
+		params: handler.WorkspaceRequestPathParam{}, status: http.StatusOK, response: []*model.Member{},
+		errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodPut, path: "/workspaces/:id/members/:user_id", summary: "Change the role of a member", tag: "workspaces",
+		params: handler.MemberRequestPathParam{}, body: handler.UpdateMemberRequestBodyParam{}, status: http.StatusNoContent,
+		errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		method: http.MethodDelete, path: "/workspaces/:id/members/:user_id", summary: "Remove a member", tag: "workspaces",
+		params: handler.MemberRequestPathParam{}, status: http.StatusNoContent,
+		errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		method: http.MethodPost, path: "/workspaces/:id/invitations", summary: "Create an invitation", tag: "invitations",
+		params: handler.WorkspaceRequestPathParam{}, body: handler.CreateInvitationRequestBodyParam{}, status: http.StatusCreated, response: model.Invitation{},
+		errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/workspaces/:id/invitations", summary: "Get all invitations of a workspace", tag: "invitations",
+		params: handler.WorkspaceRequestPathParam{}, status: http.StatusOK, response: []*model.Invitation{},
+		errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodPost, path: "/invitations/:token/accept", summary: "Accept an invitation", tag: "invitations",
+		params: handler.InvitationRequestPathParam{}, status: http.StatusNoContent,
+		errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusGone},
+	},
+	{
+		method: http.MethodPost, path: "/invitations/:token/decline", summary: "Decline an invitation", tag: "invitations",
+		params: handler.InvitationRequestPathParam{}, status: http.StatusNoContent,
+		errors: []int{http.StatusNotFound, http.StatusConflict, http.StatusGone},
+	},
+	{
+		method: http.MethodPost, path: "/invitations/:token/revoke", summary: "Revoke an invitation", tag: "invitations",
+		params: handler.InvitationRequestPathParam{}, status: http.StatusNoContent,
+		errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		method: http.MethodPost, path: "/graphql", summary: "Run a GraphQL query or mutation", tag: "graphql",
+		body: graphqlhandler.Request{}, status: http.StatusOK, response: graphqlhandler.Response{},
+		workspace: true, errors: []int{http.StatusBadRequest},
+	},
+	{
+		method: http.MethodGet, path: "/ws", summary: "Open a WebSocket for realtime task sync", tag: "todo",
+		status: http.StatusSwitchingProtocols,
+		errors: []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+	},
+	{
+		method: http.MethodPost, path: "/webhooks", summary: "Create a webhook subscription", tag: "webhooks",
+		body: handler.CreateWebhookRequestBodyParam{}, status: http.StatusCreated, response: handler.CreateWebhookResponse{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/webhooks", summary: "Get all webhook subscriptions", tag: "webhooks",
+		status: http.StatusOK, response: []*model.Webhook{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden},
+	},
+	{
+		method: http.MethodGet, path: "/webhooks/:id", summary: "Get a webhook subscription", tag: "webhooks",
+		params: handler.WebhookRequestPathParam{}, status: http.StatusOK, response: model.Webhook{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/webhooks/:id", summary: "Update a webhook subscription", tag: "webhooks",
+		params: handler.WebhookRequestPathParam{}, body: handler.UpdateWebhookRequestBodyParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/webhooks/:id", summary: "Delete a webhook subscription", tag: "webhooks",
+		params: handler.WebhookRequestPathParam{}, status: http.StatusNoContent,
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/webhooks/:id/deliveries", summary: "Get the delivery log of a webhook", tag: "webhooks",
+		params: handler.WebhookRequestPathParam{}, query: handler.DeliveriesRequestQueryParam{}, status: http.StatusOK, response: []*model.WebhookDelivery{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/webhooks/:id/test", summary: "Send a test event to a webhook", tag: "webhooks",
+		params: handler.WebhookRequestPathParam{}, status: http.StatusAccepted, response: model.WebhookDelivery{},
+		workspace: true, errors: []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+	},
+}
@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"app/domain/model"
+	"app/usecase"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(model.TaskStatus("")):       keys(model.TaskStatusMap),
+	reflect.TypeOf(model.Role("")):             keys(model.RoleMap),
+	reflect.TypeOf(model.InvitationStatus("")): {string(model.Pending), string(model.Accepted), string(model.Declined), string(model.Revoked)},
+	reflect.TypeOf(model.WebhookEvent("")):     keys(model.WebhookEventMap),
+	reflect.TypeOf(model.DeliveryStatus("")):   {string(model.DeliveryPending), string(model.DeliverySucceeded), string(model.DeliveryDead)},
+	reflect.TypeOf(model.TodoAction("")):       keys(model.TodoActionMap),
+	reflect.TypeOf(usecase.TodoOperationType("")): {
+		string(usecase.TodoOperationCreate), string(usecase.TodoOperationUpdate), string(usecase.TodoOperationDelete),
+	},
+	reflect.TypeOf(usecase.ImportJobStatus("")): {
+		string(usecase.ImportJobRunning), string(usecase.ImportJobSucceeded), string(usecase.ImportJobFailed),
+	},
+}
+
+func keys[K ~string](m map[K]bool) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, string(k))
+	}
+	sort.Strings(res)
+	return res
+}
+
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func (g *generator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if values, ok := enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+	switch {
+	case t == reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == reflect.TypeOf(json.RawMessage{}):
+		return &Schema{}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// 再帰的な型に備えて先に登録しておく
+			g.schemas[t.Name()] = &Schema{}
+			*g.schemas[t.Name()] = *g.objectOf(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	return &Schema{}
+}
+
+func (g *generator) objectOf(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("uri") != "" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			// encoding/json flattens embedded structs into the outer object
+			embedded := g.objectOf(f.Type)
+			for name, p := range embedded.Properties {
+				if _, ok := s.Properties[name]; !ok {
+					s.Properties[name] = p
+				}
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			name = strings.Split(tag, ",")[0]
+		}
+		if name == "-" {
+			continue
+		}
+		p := g.schemaOf(f.Type)
+		for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+			k, v, _ := strings.Cut(rule, "=")
+			switch k {
+			case "required":
+				s.Required = append(s.Required, name)
+			case "max", "min":
+				n, err := strconv.Atoi(v)
+				if err != nil || p.Type != "string" {
+					continue
+				}
+				if k == "max" {
+					p.MaxLength = &n
+				} else {
+					p.MinLength = &n
+				}
+			}
+		}
+		s.Properties[name] = p
+	}
+	return s
+}
+
+// parametersOf describes the uri and form tagged fields of a parameter
+// struct as path and query parameters.
+func (g *generator) parametersOf(v any) []Parameter {
+	if v == nil {
+		return nil
+	}
+	var params []Parameter
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			// gin binds the fields of embedded structs like its own
+			params = append(params, g.parametersOf(reflect.Zero(f.Type).Interface())...)
+			continue
+		}
+		if name := f.Tag.Get("uri"); name != "" {
+			params = append(params, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   g.schemaOf(f.Type),
+			})
+		}
+		if name, _, _ := strings.Cut(f.Tag.Get("form"), ","); name != "" {
+			params = append(params, Parameter{
+				Name:     name,
+				In:       "query",
+				Required: strings.Contains(f.Tag.Get("binding"), "required"),
+				Schema:   g.schemaOf(f.Type),
+			})
+		}
+	}
+	return params
+}
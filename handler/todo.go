@@ -4,16 +4,25 @@ import (
 	"app/domain/model"
 	"app/usecase"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type Todo interface {
 	Create(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
+	Move(c *gin.Context)
 	Find(c *gin.Context)
 	FindAll(c *gin.Context)
+	History(c *gin.Context)
+	Activity(c *gin.Context)
+	Diff(c *gin.Context)
+	Revert(c *gin.Context)
+	Bulk(c *gin.Context)
+	SetStatus(c *gin.Context)
 }
 
 type todoHandler struct {
@@ -24,8 +33,10 @@ func NewTodo(u usecase.Todo) Todo {
 	return &todoHandler{u}
 }
 
+// CreateRequestParam adds the task to the list with ListID when it is given.
 type CreateRequestParam struct {
-	Task string `json:"task" binding:"required,max=60"`
+	Task   string `json:"task" binding:"required,max=60"`
+	ListID int    `json:"list_id" binding:"min=0"`
 }
 
 func (t *todoHandler) Create(c *gin.Context) {
@@ -34,9 +45,13 @@ func (t *todoHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	err := t.usecase.Create(req.Task)
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	err := t.usecase.Create(c.Request.Context(), actor, req.Task, req.ListID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "")
+		errorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, nil)
@@ -64,8 +79,12 @@ func (t *todoHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := t.usecase.Update(pathParam.ID, bodyParam.Task, bodyParam.Status); err != nil {
-		c.JSON(http.StatusInternalServerError, "")
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := t.usecase.Update(c.Request.Context(), actor, pathParam.ID, bodyParam.Task, bodyParam.Status); err != nil {
+		errorResponse(c, err)
 		return
 	}
 
@@ -82,8 +101,41 @@ func (t *todoHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := t.usecase.Delete(req.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := t.usecase.Delete(c.Request.Context(), actor, req.ID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// MoveRequestBodyParam puts the task into the list with ListID; 0 takes it
+// out of its list.
+type MoveRequestBodyParam struct {
+	ListID int `json:"list_id" binding:"min=0"`
+}
+
+func (t *todoHandler) Move(c *gin.Context) {
+	var pathParam UpdateRequestPathParam
+	var bodyParam MoveRequestBodyParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&bodyParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := t.usecase.Move(c.Request.Context(), actor, pathParam.ID, bodyParam.ListID); err != nil {
+		errorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -93,15 +145,36 @@ type FindRequestParam struct {
 	ID int `uri:"id" binding:"required"`
 }
 
+// FindRequestQueryParam rebuilds the task from its history as it was at
+// as_of (RFC 3339) when given.
+type FindRequestQueryParam struct {
+	AsOf time.Time `form:"as_of"`
+}
+
 func (t *todoHandler) Find(c *gin.Context) {
 	var req FindRequestParam
+	var queryParam FindRequestQueryParam
 	if err := c.ShouldBindUri(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	res, err := t.usecase.Find(req.ID)
+	if err := c.ShouldBindQuery(&queryParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	var res *model.Todo
+	var err error
+	if queryParam.AsOf.IsZero() {
+		res, err = t.usecase.Find(c.Request.Context(), actor, req.ID)
+	} else {
+		res, err = t.usecase.FindAsOf(c.Request.Context(), actor, req.ID, queryParam.AsOf)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 	if res == nil {
@@ -111,11 +184,325 @@ func (t *todoHandler) Find(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
+// FindAllRequestQueryParam keeps the tasks of the list with ListID when it
+// is given.
+type FindAllRequestQueryParam struct {
+	ListID int `form:"list_id" binding:"min=0"`
+}
+
 func (t *todoHandler) FindAll(c *gin.Context) {
-	res, err := t.usecase.FindAll()
+	var req FindAllRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	var res []*model.Todo
+	var err error
+	if req.ListID == 0 {
+		res, err = t.usecase.FindAll(c.Request.Context(), actor)
+	} else {
+		res, err = t.usecase.Search(c.Request.Context(), actor, model.TodoFilter{ListID: req.ListID})
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
 }
+
+type HistoryRequestQueryParam struct {
+	Limit  int `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset int `form:"offset" binding:"min=0"`
+}
+
+func (t *todoHandler) History(c *gin.Context) {
+	var pathParam FindRequestParam
+	var queryParam HistoryRequestQueryParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&queryParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := t.usecase.History(c.Request.Context(), actor, pathParam.ID, queryParam.Limit, queryParam.Offset)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ActivityRequestQueryParam takes the time range as RFC 3339; from is
+// inclusive and to is exclusive.
+type ActivityRequestQueryParam struct {
+	UserID int              `form:"user_id" binding:"min=0"`
+	Action model.TodoAction `form:"action" binding:"omitempty,todo_action"`
+	From   time.Time        `form:"from"`
+	To     time.Time        `form:"to"`
+	Limit  int              `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset int              `form:"offset" binding:"min=0"`
+}
+
+func (t *todoHandler) Activity(c *gin.Context) {
+	var req ActivityRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := t.usecase.Activity(c.Request.Context(), actor, model.ActivityFilter{
+		UserID: req.UserID,
+		Action: req.Action,
+		From:   req.From,
+		To:     req.To,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// DiffRequestQueryParam takes revisions, which are the IDs of history entries.
+type DiffRequestQueryParam struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+func (t *todoHandler) Diff(c *gin.Context) {
+	var pathParam FindRequestParam
+	var queryParam DiffRequestQueryParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&queryParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := t.usecase.Diff(c.Request.Context(), actor, pathParam.ID, queryParam.From, queryParam.To)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// RevertRequestBodyParam restores Revision. BaseRevision, when given, must be
+// the latest revision of the task or the revert fails with 409.
+type RevertRequestBodyParam struct {
+	Revision     int `json:"revision" binding:"required,min=1"`
+	BaseRevision int `json:"base_revision" binding:"omitempty,min=1"`
+}
+
+func (t *todoHandler) Revert(c *gin.Context) {
+	var pathParam FindRequestParam
+	var bodyParam RevertRequestBodyParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&bodyParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := t.usecase.Revert(c.Request.Context(), actor, pathParam.ID, bodyParam.Revision, bodyParam.BaseRevision); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// BulkRequestBodyParam applies up to 500 operations in order. In the atomic
+// mode, the default, nothing is applied when any operation fails; in the
+// best_effort mode only the failing operations are skipped.
+type BulkRequestBodyParam struct {
+	Mode       string               `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BulkOperationParam `json:"operations" binding:"required,min=1,max=500"`
+}
+
+// BulkOperationParam is validated per item with the rules of the single
+// create and update requests, so that one invalid item does not reject the
+// whole batch.
+type BulkOperationParam struct {
+	Op     usecase.TodoOperationType `json:"op" binding:"required,oneof=create update delete"`
+	ID     int                       `json:"id" binding:"required_unless=Op create"`
+	Task   string                    `json:"task"`
+	Status model.TaskStatus          `json:"status"`
+}
+
+// BulkResponse has a result per operation in request order, with the status
+// code the single request would have returned.
+type BulkResponse struct {
+	Results []BulkResult `json:"results"`
+}
+
+type BulkResult struct {
+	ID     int    `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (t *todoHandler) Bulk(c *gin.Context) {
+	var req BulkRequestBodyParam
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	atomic := req.Mode != "best_effort"
+
+	res := BulkResponse{Results: make([]BulkResult, len(req.Operations))}
+	ops := make([]usecase.TodoOperation, 0, len(req.Operations))
+	index := make([]int, 0, len(req.Operations))
+	for i, op := range req.Operations {
+		if err := validateBulkOperation(op); err != nil {
+			res.Results[i] = BulkResult{ID: op.ID, Status: http.StatusBadRequest, Error: err.Error()}
+			continue
+		}
+		ops = append(ops, usecase.TodoOperation{Type: op.Op, ID: op.ID, Task: op.Task, Status: op.Status})
+		index = append(index, i)
+	}
+	if atomic && len(ops) < len(req.Operations) {
+		for j, i := range index {
+			res.Results[i] = BulkResult{ID: ops[j].ID, Status: http.StatusFailedDependency, Error: usecase.ErrRolledBack.Error()}
+		}
+		c.JSON(http.StatusUnprocessableEntity, res)
+		return
+	}
+
+	results, err := t.usecase.Bulk(c.Request.Context(), actor, ops, atomic)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	failed := false
+	for j, r := range results {
+		i := index[j]
+		if r.Err != nil {
+			failed = true
+			res.Results[i] = BulkResult{ID: r.ID, Status: errorStatus(r.Err), Error: r.Err.Error()}
+			continue
+		}
+		status := http.StatusNoContent
+		if ops[j].Type == usecase.TodoOperationCreate {
+			status = http.StatusCreated
+		}
+		res.Results[i] = BulkResult{ID: r.ID, Status: status}
+	}
+	if atomic && failed {
+		c.JSON(http.StatusUnprocessableEntity, res)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func validateBulkOperation(op BulkOperationParam) error {
+	if err := binding.Validator.ValidateStruct(&op); err != nil {
+		return err
+	}
+	switch op.Op {
+	case usecase.TodoOperationCreate:
+		return binding.Validator.ValidateStruct(&CreateRequestParam{Task: op.Task})
+	case usecase.TodoOperationUpdate:
+		return binding.Validator.ValidateStruct(&UpdateRequestBodyParam{Task: op.Task, Status: op.Status})
+	default:
+		return nil
+	}
+}
+
+// SetStatusRequestBodyParam moves every task matching Filter to Status.
+// Filter needs at least one condition, and at most usecase.MaxStatusChanges
+// tasks may match it.
+type SetStatusRequestBodyParam struct {
+	Status model.TaskStatus     `json:"status" binding:"required,task_status"`
+	Filter SetStatusFilterParam `json:"filter"`
+	DryRun bool                 `json:"dry_run"`
+}
+
+// SetStatusFilterParam takes the creation time range as RFC 3339;
+// created_from is inclusive and created_to is exclusive.
+type SetStatusFilterParam struct {
+	Status      model.TaskStatus `json:"status" binding:"omitempty,task_status"`
+	ListID      int              `json:"list_id" binding:"min=0"`
+	IDs         []int            `json:"ids" binding:"omitempty,max=500,dive,min=1"`
+	CreatedFrom time.Time        `json:"created_from"`
+	CreatedTo   time.Time        `json:"created_to"`
+}
+
+func (f SetStatusFilterParam) empty() bool {
+	return f.Status == "" && f.ListID == 0 && len(f.IDs) == 0 && f.CreatedFrom.IsZero() && f.CreatedTo.IsZero()
+}
+
+// SetStatusResponse lists the matching tasks and which of them were changed
+// or, with dry_run, would be. Skipped tasks already had the status.
+type SetStatusResponse struct {
+	DryRun  bool          `json:"dry_run"`
+	Count   int           `json:"count"`
+	Todos   []*model.Todo `json:"todos"`
+	Changed []int         `json:"changed"`
+	Skipped []int         `json:"skipped"`
+}
+
+func (t *todoHandler) SetStatus(c *gin.Context) {
+	var req SetStatusRequestBodyParam
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Filter.empty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "filter needs at least one condition"})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := t.usecase.SetStatus(c.Request.Context(), actor, model.TodoFilter{
+		Status:      req.Filter.Status,
+		ListID:      req.Filter.ListID,
+		IDs:         req.Filter.IDs,
+		CreatedFrom: req.Filter.CreatedFrom,
+		CreatedTo:   req.Filter.CreatedTo,
+	}, req.Status, req.DryRun)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, SetStatusResponse{
+		DryRun:  req.DryRun,
+		Count:   len(res.Matched),
+		Todos:   res.Matched,
+		Changed: res.Changed,
+		Skipped: res.Skipped,
+	})
+}
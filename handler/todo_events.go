@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"app/domain/model"
+	"app/usecase"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const LastEventIDHeader = "Last-Event-ID"
+
+type TodoEvents interface {
+	Stream(c *gin.Context)
+	// Shutdown ends every open stream and rejects new ones.
+	Shutdown()
+}
+
+type todoEventsHandler struct {
+	usecase   usecase.Todo
+	heartbeat time.Duration
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func NewTodoEvents(u usecase.Todo, heartbeat time.Duration) TodoEvents {
+	return &todoEventsHandler{usecase: u, heartbeat: heartbeat, shutdown: make(chan struct{})}
+}
+
+type TodoEventsRequestParam struct {
+	Status model.TaskStatus `form:"status" binding:"omitempty,task_status"`
+	ListID int              `form:"list_id" binding:"min=0"`
+}
+
+// matches reports whether e is forwarded to the stream. An update that moves
+// a todo out of the list is forwarded too, so that the client can drop it.
+func (p TodoEventsRequestParam) matches(e usecase.TodoEvent) bool {
+	if p.Status != "" && e.Todo.Status != p.Status {
+		return false
+	}
+	return p.ListID == 0 || e.Todo.ListID == p.ListID || e.FromListID == p.ListID
+}
+
+func (t *todoEventsHandler) Stream(c *gin.Context) {
+	select {
+	case <-t.shutdown:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server is shutting down"})
+		return
+	default:
+	}
+	var req TodoEventsRequestParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var lastEventID uint64
+	if v := c.GetHeader(LastEventIDHeader); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + LastEventIDHeader + " header"})
+			return
+		}
+		lastEventID = id
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	replay, events, cancel, err := t.usecase.Subscribe(c.Request.Context(), actor, lastEventID)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	defer cancel()
+	// The stream lasts longer than the write timeout of the server.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, e := range replay {
+		writeTodoEvent(c.Writer, e, req)
+	}
+	c.Writer.Flush()
+
+	var heartbeat <-chan time.Time
+	if t.heartbeat > 0 {
+		ticker := time.NewTicker(t.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-t.shutdown:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			writeTodoEvent(c.Writer, e, req)
+		case <-heartbeat:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func (t *todoEventsHandler) Shutdown() {
+	t.shutdownOnce.Do(func() { close(t.shutdown) })
+}
+
+func writeTodoEvent(w io.Writer, e usecase.TodoEvent, req TodoEventsRequestParam) {
+	if !req.matches(e) {
+		return
+	}
+	_ = sse.Encode(w, sse.Event{
+		Id:    strconv.FormatUint(e.ID, 10),
+		Event: string(e.Type),
+		Data:  e.Todo,
+	})
+}
@@ -0,0 +1,168 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockTodoEvents struct {
+	usecase.Todo
+}
+
+// Subscribe は再送分だけを返し、即座に閉じたチャネルでストリームを終了させる
+func (m *mockTodoEvents) Subscribe(ctx context.Context, actor model.Actor, lastEventID uint64) ([]usecase.TodoEvent, <-chan usecase.TodoEvent, func(), error) {
+	events := make(chan usecase.TodoEvent)
+	close(events)
+	replay := []usecase.TodoEvent{
+		{ID: 2, Type: usecase.TodoCreated, Todo: model.Todo{ID: 1, WorkspaceID: 1, ListID: 2, Task: "task", Status: model.Created}, FromListID: 2},
+		{ID: 3, Type: usecase.TodoUpdated, Todo: model.Todo{ID: 1, WorkspaceID: 1, Task: "task", Status: model.Done}, FromListID: 2},
+	}
+	return replay, events, func() {}, nil
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		lastEventID      string
+		want_status_code int
+		want_events      []string
+	}{
+		{
+			name:             "正常系_Last-Event-ID以降のイベントが配信されること",
+			lastEventID:      "1",
+			want_status_code: http.StatusOK,
+			want_events:      []string{"id:2\nevent:created\n", "id:3\nevent:updated\n"},
+		},
+		{
+			name:             "正常系_ステータスで絞り込めること",
+			query:            "?status=done",
+			want_status_code: http.StatusOK,
+			want_events:      []string{"id:3\nevent:updated\n"},
+		},
+		{
+			name:             "正常系_リストから移動したイベントもリストで絞り込めること",
+			query:            "?list_id=2",
+			want_status_code: http.StatusOK,
+			want_events:      []string{"id:2\nevent:created\n", "id:3\nevent:updated\n"},
+		},
+		{
+			name:             "正常系_他のリストのイベントが配信されないこと",
+			query:            "?list_id=5",
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "異常系_不正なリストIDの場合バリデーションエラーになること",
+			query:            "?list_id=-1",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_不正なステータスの場合バリデーションエラーになること",
+			query:            "?status=unknown",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_不正なLast-Event-IDの場合エラーになること",
+			lastEventID:      "abc",
+			want_status_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+			u := &mockTodoEvents{}
+			r.GET("/todo/events", handler.NewTodoEvents(u, 0).Stream)
+
+			req := httptest.NewRequest(http.MethodGet, "/todo/events"+tt.query, nil)
+			setAuthHeader(req)
+			if tt.lastEventID != "" {
+				req.Header.Set(handler.LastEventIDHeader, tt.lastEventID)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("want = %v, got = %v", "text/event-stream", got)
+			}
+			body := rec.Body.String()
+			if n := strings.Count(body, "id:"); n != len(tt.want_events) {
+				t.Errorf("want %d events, got = %q", len(tt.want_events), body)
+			}
+			for _, e := range tt.want_events {
+				if !strings.Contains(body, e) {
+					t.Errorf("want %q in %q", e, body)
+				}
+			}
+		})
+	}
+}
+
+type mockIdleTodoEvents struct {
+	usecase.Todo
+}
+
+// Subscribe はイベントの来ないチャネルを返し、ストリームを開いたままにする
+func (m *mockIdleTodoEvents) Subscribe(ctx context.Context, actor model.Actor, lastEventID uint64) ([]usecase.TodoEvent, <-chan usecase.TodoEvent, func(), error) {
+	return nil, make(chan usecase.TodoEvent), func() {}, nil
+}
+
+func TestStreamShutdown(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_シャットダウン時に開いているストリームが終了し新しいストリームは拒否されること", func(t *testing.T) {
+		r := gin.New()
+		r.Use(middleware.Authenticate())
+		validator.SetupValidator()
+		h := handler.NewTodoEvents(&mockIdleTodoEvents{}, 0)
+		r.GET("/todo/events", h.Stream)
+
+		done := make(chan int)
+		go func() {
+			req := httptest.NewRequest(http.MethodGet, "/todo/events", nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			done <- rec.Code
+		}()
+		time.Sleep(10 * time.Millisecond)
+		h.Shutdown()
+		select {
+		case code := <-done:
+			if code != http.StatusOK {
+				t.Errorf("want = %v, got = %v", http.StatusOK, code)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the stream did not end on shutdown")
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/todo/events", nil)
+		setAuthHeader(req)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("want = %v, got = %v", http.StatusServiceUnavailable, rec.Code)
+		}
+	})
+}
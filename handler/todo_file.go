@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"app/usecase"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	// exportPageSize is the number of todos read from the database at once
+	// while an export is streamed.
+	exportPageSize = 500
+	maxImportSize  = 10 << 20
+)
+
+type TodoFile interface {
+	ExportCSV(c *gin.Context)
+	ExportICalendar(c *gin.Context)
+	Export(c *gin.Context)
+	Import(c *gin.Context)
+}
+
+type exportFormat struct {
+	contentType string
+	filename    string
+	newEncoder  func(w io.Writer) todoformat.Encoder
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":      {"text/csv; charset=utf-8", "todo.csv", todoformat.NewCSVEncoder},
+	"todotxt":  {"text/plain; charset=utf-8", "todo.txt", todoformat.NewTodoTxtEncoder},
+	"markdown": {"text/markdown; charset=utf-8", "todo.md", todoformat.NewMarkdownEncoder},
+	"ics": {"text/calendar; charset=utf-8", "todo.ics", func(w io.Writer) todoformat.Encoder {
+		return todoformat.NewICalendarEncoder(w, time.Now())
+	}},
+}
+
+type todoFileHandler struct {
+	usecase usecase.Todo
+}
+
+func NewTodoFile(u usecase.Todo) TodoFile {
+	return &todoFileHandler{u}
+}
+
+// ExportRequestQueryParam takes the creation time range as RFC 3339;
+// created_from is inclusive and created_to is exclusive.
+type ExportRequestQueryParam struct {
+	Status      model.TaskStatus `form:"status" binding:"omitempty,task_status"`
+	CreatedFrom time.Time        `form:"created_from"`
+	CreatedTo   time.Time        `form:"created_to"`
+}
+
+func (t *todoFileHandler) ExportCSV(c *gin.Context) {
+	var req ExportRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t.export(c, req, exportFormats["csv"])
+}
+
+func (t *todoFileHandler) ExportICalendar(c *gin.Context) {
+	var req ExportRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t.export(c, req, exportFormats["ics"])
+}
+
+type ExportFormatRequestQueryParam struct {
+	Format string `form:"format" binding:"required,oneof=csv todotxt markdown ics"`
+	ExportRequestQueryParam
+}
+
+func (t *todoFileHandler) Export(c *gin.Context) {
+	var req ExportFormatRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	t.export(c, req.ExportRequestQueryParam, exportFormats[req.Format])
+}
+
+func (t *todoFileHandler) export(c *gin.Context, req ExportRequestQueryParam, format exportFormat) {
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	f := model.TodoFilter{Status: req.Status, CreatedFrom: req.CreatedFrom, CreatedTo: req.CreatedTo, Limit: exportPageSize}
+	todos, err := t.usecase.Search(c.Request.Context(), actor, f)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", format.contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+format.filename+`"`)
+	c.Status(http.StatusOK)
+	enc := format.newEncoder(c.Writer)
+	for {
+		if err := enc.Encode(todos...); err != nil {
+			return
+		}
+		if len(todos) < exportPageSize {
+			break
+		}
+		// The next page starts after the last todo rather than at an offset,
+		// which todos created or deleted meanwhile would shift.
+		f.AfterID = todos[len(todos)-1].ID
+		// The status line has been sent, so a failure can only cut the
+		// file short.
+		if todos, err = t.usecase.Search(c.Request.Context(), actor, f); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	_ = enc.Close()
+}
+
+// ImportRequestQueryParam selects the format of the body. The columns map
+// the fields to the header names of a CSV file.
+type ImportRequestQueryParam struct {
+	Format       string `form:"format,default=csv" binding:"oneof=csv todotxt markdown ics"`
+	DryRun       bool   `form:"dry_run"`
+	TaskColumn   string `form:"task_column" binding:"max=255"`
+	StatusColumn string `form:"status_column" binding:"max=255"`
+}
+
+// ImportResponse lists the accepted todos, which are only a preview with
+// dry_run, and the rejected records.
+type ImportResponse struct {
+	DryRun   bool              `json:"dry_run"`
+	Count    int               `json:"count"`
+	Todos    []*model.Todo     `json:"todos"`
+	Rejected []ImportRejection `json:"rejected"`
+}
+
+type ImportRejection struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+func (t *todoFileHandler) Import(c *gin.Context) {
+	var req ImportRequestQueryParam
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var records []todoformat.Record
+	var err error
+	switch req.Format {
+	case "todotxt":
+		records, err = todoformat.ReadTodoTxt(body)
+	case "markdown":
+		records, err = todoformat.ReadMarkdown(body)
+	case "ics":
+		records, err = todoformat.ReadICalendar(body)
+	default:
+		columns := todoformat.DefaultCSVColumns
+		if req.TaskColumn != "" {
+			columns.Task = req.TaskColumn
+		}
+		if req.StatusColumn != "" {
+			columns.Status = req.StatusColumn
+		}
+		records, err = todoformat.ReadCSV(body, columns)
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	duplicates, err := t.duplicates(c.Request.Context(), actor, records)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	res := ImportResponse{DryRun: req.DryRun, Todos: []*model.Todo{}, Rejected: []ImportRejection{}}
+	for _, r := range records {
+		if reason, ok := duplicates[r.Line]; ok {
+			res.Rejected = append(res.Rejected, ImportRejection{Line: r.Line, Reason: reason})
+			continue
+		}
+		todo, err := validateRecord(r)
+		if err != nil {
+			res.Rejected = append(res.Rejected, ImportRejection{Line: r.Line, Reason: err.Error()})
+			continue
+		}
+		res.Todos = append(res.Todos, todo)
+	}
+	res.Count = len(res.Todos)
+	if !req.DryRun {
+		if err := t.usecase.Import(c.Request.Context(), actor, res.Todos); err != nil {
+			errorResponse(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// duplicates returns the reasons for rejecting records by the line they
+// start on: a UID that was already used earlier in the file, or the UID of
+// an exported todo that still exists in the workspace. UIDs of other apps
+// are not stored, so importing their file twice is not detected.
+func (t *todoFileHandler) duplicates(ctx context.Context, actor model.Actor, records []todoformat.Record) (map[int]string, error) {
+	res := map[int]string{}
+	seen := map[string]bool{}
+	exported := map[int]int{}
+	var ids []int
+	for _, r := range records {
+		if r.UID == "" {
+			continue
+		}
+		if seen[r.UID] {
+			res[r.Line] = "duplicate UID " + r.UID
+			continue
+		}
+		seen[r.UID] = true
+		if id, ok := todoformat.TodoIDFromUID(r.UID); ok {
+			exported[id] = r.Line
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return res, nil
+	}
+	todos, err := t.usecase.FindByIDs(ctx, actor, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range todos {
+		res[exported[todo.ID]] = "the task of UID " + todoformat.TodoUID(todo.ID) + " already exists"
+	}
+	return res, nil
+}
+
+// validateRecord applies the rules of the update request, so an imported
+// record is valid exactly when it could have been saved through the API.
+// Records without a status are created as new tasks.
+func validateRecord(r todoformat.Record) (*model.Todo, error) {
+	req := UpdateRequestBodyParam{Task: r.Task, Status: r.Status}
+	if req.Status == "" {
+		req.Status = model.Created
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return nil, err
+	}
+	return &model.Todo{Task: req.Task, Status: req.Status}, nil
+}
@@ -0,0 +1,323 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExportCSV(t *testing.T) {
+	t.Parallel()
+
+	// page は ID が after より大きいタスクを n 件返す
+	page := func(after int, n int) []*model.Todo {
+		todos := make([]*model.Todo, n)
+		for i := range todos {
+			todos[i] = &model.Todo{ID: after + i + 1, Task: fmt.Sprintf("task%d", after+i+1), Status: model.Done}
+		}
+		return todos
+	}
+	tests := []struct {
+		name             string
+		query            string
+		usecase          usecase.Todo
+		want_status_code int
+		want_rows        int
+	}{
+		{
+			name:  "正常系_全件がページごとに読み込まれて出力されること",
+			query: "?status=done",
+			usecase: &mockTodo{
+				mockSearch: func(f model.TodoFilter) ([]*model.Todo, error) {
+					if f.Status != model.Done || f.Limit != 500 {
+						return nil, fmt.Errorf("unexpected filter: %+v", f)
+					}
+					if f.AfterID == 0 {
+						return page(0, 500), nil
+					}
+					if f.Offset != 0 {
+						return nil, fmt.Errorf("unexpected filter: %+v", f)
+					}
+					return page(f.AfterID, 3), nil
+				},
+			},
+			want_status_code: http.StatusOK,
+			want_rows:        503,
+		},
+		{
+			name:             "異常系_ステータスが不正な場合バリデーションエラーになること",
+			query:            "?status=xxxx",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_権限がない場合",
+			usecase: &mockTodo{
+				mockSearch: func(f model.TodoFilter) ([]*model.Todo, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodoFile(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.GET("/export.csv", h.ExportCSV)
+			req := httptest.NewRequest("GET", "/export.csv"+tt.query, nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			// ヘッダー行の分を除く
+			if rows := strings.Count(rec.Body.String(), "\r\n") - 1; rows != tt.want_rows {
+				t.Errorf("want = %v, got = %v", tt.want_rows, rows)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		want_status_code int
+		want_type        string
+		want_body        string
+	}{
+		{
+			name:             "正常系_todo.txt形式で出力されること",
+			query:            "?format=todotxt",
+			want_status_code: http.StatusOK,
+			want_type:        "text/plain; charset=utf-8",
+			want_body:        "x task1\ntask2 status:processing\n",
+		},
+		{
+			name:             "正常系_Markdownのタスクリストで出力されること",
+			query:            "?format=markdown",
+			want_status_code: http.StatusOK,
+			want_type:        "text/markdown; charset=utf-8",
+			want_body:        "- [x] task1\n- [/] task2\n",
+		},
+		{
+			name:             "異常系_形式が指定されていない場合バリデーションエラーになること",
+			want_status_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodoFile(&mockTodo{
+				mockSearch: func(f model.TodoFilter) ([]*model.Todo, error) {
+					return []*model.Todo{
+						{ID: 1, Task: "task1", Status: model.Done},
+						{ID: 2, Task: "task2", Status: model.Processing},
+					}, nil
+				},
+			})
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.GET("/export", h.Export)
+			req := httptest.NewRequest("GET", "/export"+tt.query, nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.want_type {
+				t.Errorf("want = %v, got = %v", tt.want_type, got)
+			}
+			if rec.Body.String() != tt.want_body {
+				t.Errorf("diff %s", cmp.Diff(rec.Body.String(), tt.want_body))
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		body             string
+		usecase          usecase.Todo
+		want_status_code int
+		want_todos       []*model.Todo
+		want_rejected    []handler.ImportRejection
+	}{
+		{
+			name: "正常系_有効な行が登録され不正な行が行番号とともに返ること",
+			body: "task,status\ntask1,done\n,created\ntask3\ntask4,xxxx\n",
+			usecase: &mockTodo{
+				mockImport: func(todos []*model.Todo) error { return nil },
+			},
+			want_status_code: http.StatusOK,
+			want_todos: []*model.Todo{
+				{Task: "task1", Status: model.Done},
+				{Task: "task3", Status: model.Created},
+			},
+			want_rejected: []handler.ImportRejection{{Line: 3}, {Line: 5}},
+		},
+		{
+			name:  "正常系_ドライランの場合登録されないこと",
+			query: "?dry_run=true&task_column=Title&status_column=State",
+			body:  "Title,State\ntask1,processing\n",
+			usecase: &mockTodo{
+				mockImport: func(todos []*model.Todo) error { return errors.New("must not be called") },
+			},
+			want_status_code: http.StatusOK,
+			want_todos:       []*model.Todo{{Task: "task1", Status: model.Processing}},
+			want_rejected:    []handler.ImportRejection{},
+		},
+		{
+			name:  "正常系_todo.txt形式で読み込めること",
+			query: "?format=todotxt",
+			body:  "x 2023-01-02 task1\n(A) task2 status:processing\n",
+			usecase: &mockTodo{
+				mockImport: func(todos []*model.Todo) error { return nil },
+			},
+			want_status_code: http.StatusOK,
+			want_todos: []*model.Todo{
+				{Task: "task1", Status: model.Done},
+				{Task: "(A) task2", Status: model.Processing},
+			},
+			want_rejected: []handler.ImportRejection{},
+		},
+		{
+			name:  "正常系_Markdownのタスクリストが読み込めること",
+			query: "?format=markdown",
+			body:  "# todo\n- [ ] task1\n- [x] \n",
+			usecase: &mockTodo{
+				mockImport: func(todos []*model.Todo) error { return nil },
+			},
+			want_status_code: http.StatusOK,
+			want_todos:       []*model.Todo{{Task: "task1", Status: model.Created}},
+			want_rejected:    []handler.ImportRejection{{Line: 3}},
+		},
+		{
+			name:  "正常系_iCalendarのUIDが重複するVTODOが除外されること",
+			query: "?format=ics",
+			body: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VTODO\r\nUID:todo-1@go-api-sample-todo\r\nSUMMARY:exists\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:todo-2@go-api-sample-todo\r\nSUMMARY:deleted\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:a@example.com\r\nSUMMARY:task\r\nSTATUS:IN-PROCESS\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:a@example.com\r\nSUMMARY:again\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+			usecase: &mockTodo{
+				mockFindByIDs: func(ids []int) ([]*model.Todo, error) {
+					if !cmp.Equal(ids, []int{1, 2}) {
+						return nil, fmt.Errorf("unexpected ids: %v", ids)
+					}
+					return []*model.Todo{{ID: 1}}, nil
+				},
+				mockImport: func(todos []*model.Todo) error { return nil },
+			},
+			want_status_code: http.StatusOK,
+			want_todos: []*model.Todo{
+				{Task: "deleted", Status: model.Done},
+				{Task: "task", Status: model.Processing},
+			},
+			want_rejected: []handler.ImportRejection{{Line: 2}, {Line: 16}},
+		},
+		{
+			name:             "異常系_タスクの列がない場合",
+			body:             "name\ntask1\n",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_形式が不正な場合バリデーションエラーになること",
+			query:            "?format=xxxx",
+			body:             "task\ntask1\n",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_権限がない場合",
+			body: "task\ntask1\n",
+			usecase: &mockTodo{
+				mockImport: func(todos []*model.Todo) error { return usecase.ErrForbidden },
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodoFile(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/import", h.Import)
+			req := httptest.NewRequest("POST", "/import"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/csv")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var res handler.ImportResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !cmp.Equal(res.Todos, tt.want_todos) {
+				t.Errorf("diff %s", cmp.Diff(res.Todos, tt.want_todos))
+			}
+			lines := make([]handler.ImportRejection, len(res.Rejected))
+			for i, r := range res.Rejected {
+				if r.Reason == "" {
+					t.Errorf("rejected[%d] has no reason", i)
+				}
+				lines[i] = handler.ImportRejection{Line: r.Line}
+			}
+			if !cmp.Equal(lines, tt.want_rejected) {
+				t.Errorf("diff %s", cmp.Diff(lines, tt.want_rejected))
+			}
+		})
+	}
+}
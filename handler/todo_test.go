@@ -3,15 +3,18 @@ package handler_test
 import (
 	"app/domain/model"
 	"app/handler"
+	"app/handler/middleware"
 	"app/handler/validator"
 	"app/usecase"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,26 +24,72 @@ type mockTodo struct {
 	mockCreate  func() error
 	mockUpdate  func() error
 	mockDelete  func() error
+	mockMove    func(listID int) error
 	mockFind    func() (*model.Todo, error)
 	mockFindAll func() ([]*model.Todo, error)
+	// mockActivity は受け取った絞り込み条件を渡される
+	mockHistory  func() ([]*model.TodoHistory, error)
+	mockActivity func(f model.ActivityFilter) ([]*model.TodoHistory, error)
+	mockFindAsOf func() (*model.Todo, error)
+	mockRevert   func() error
+	// mockBulk は検証を通過した操作を渡される
+	mockBulk func(ops []usecase.TodoOperation, atomic bool) ([]usecase.TodoOperationResult, error)
+	// mockSetStatus は受け取った絞り込み条件を渡される
+	mockSetStatus func(f model.TodoFilter, dryRun bool) (*usecase.StatusChange, error)
+	mockSearch    func(f model.TodoFilter) ([]*model.Todo, error)
+	mockImport    func(todos []*model.Todo) error
+	mockFindByIDs func(ids []int) ([]*model.Todo, error)
 }
 
-func (m *mockTodo) Create(task string) error {
+func (m *mockTodo) Create(ctx context.Context, actor model.Actor, task string, listID int) error {
 	return m.mockCreate()
 }
-func (m *mockTodo) Update(id int, task string, status model.TaskStatus) error {
+func (m *mockTodo) Update(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus) error {
 	return m.mockUpdate()
 }
-func (m *mockTodo) Delete(id int) error {
+func (m *mockTodo) Delete(ctx context.Context, actor model.Actor, id int) error {
 	return m.mockDelete()
 }
+func (m *mockTodo) Move(ctx context.Context, actor model.Actor, id int, listID int) error {
+	return m.mockMove(listID)
+}
 
-func (m *mockTodo) Find(id int) (*model.Todo, error) {
+func (m *mockTodo) Find(ctx context.Context, actor model.Actor, id int) (*model.Todo, error) {
 	return m.mockFind()
 }
-func (m *mockTodo) FindAll() ([]*model.Todo, error) {
+func (m *mockTodo) FindAll(ctx context.Context, actor model.Actor) ([]*model.Todo, error) {
 	return m.mockFindAll()
 }
+func (m *mockTodo) History(ctx context.Context, actor model.Actor, id int, limit int, offset int) ([]*model.TodoHistory, error) {
+	return m.mockHistory()
+}
+func (m *mockTodo) Activity(ctx context.Context, actor model.Actor, f model.ActivityFilter) ([]*model.TodoHistory, error) {
+	return m.mockActivity(f)
+}
+func (m *mockTodo) FindAsOf(ctx context.Context, actor model.Actor, id int, asOf time.Time) (*model.Todo, error) {
+	return m.mockFindAsOf()
+}
+func (m *mockTodo) Revert(ctx context.Context, actor model.Actor, id int, revision int, baseRevision int) error {
+	return m.mockRevert()
+}
+
+func (m *mockTodo) Bulk(ctx context.Context, actor model.Actor, ops []usecase.TodoOperation, atomic bool) ([]usecase.TodoOperationResult, error) {
+	return m.mockBulk(ops, atomic)
+}
+
+func (m *mockTodo) SetStatus(ctx context.Context, actor model.Actor, f model.TodoFilter, status model.TaskStatus, dryRun bool) (*usecase.StatusChange, error) {
+	return m.mockSetStatus(f, dryRun)
+}
+
+func (m *mockTodo) Search(ctx context.Context, actor model.Actor, f model.TodoFilter) ([]*model.Todo, error) {
+	return m.mockSearch(f)
+}
+func (m *mockTodo) Import(ctx context.Context, actor model.Actor, todos []*model.Todo) error {
+	return m.mockImport(todos)
+}
+func (m *mockTodo) FindByIDs(ctx context.Context, actor model.Actor, ids []int) ([]*model.Todo, error) {
+	return m.mockFindByIDs(ids)
+}
 
 func TestCreate(t *testing.T) {
 	t.Parallel()
@@ -90,11 +139,13 @@ func TestCreate(t *testing.T) {
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
+			r.Use(middleware.Authenticate())
 			validator.SetupValidator()
 
 			r.POST("/", h.Create)
 			req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqJSON))
 			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
 			rec := httptest.NewRecorder()
 			r.ServeHTTP(rec, req)
 
@@ -192,6 +243,7 @@ func TestUpdate(t *testing.T) {
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
+			r.Use(middleware.Authenticate())
 			validator.SetupValidator()
 
 			r.PUT("/:id", h.Update)
@@ -201,6 +253,7 @@ func TestUpdate(t *testing.T) {
 			}
 			req := httptest.NewRequest("PUT", "/"+id, bytes.NewBuffer(reqJSON))
 			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
 			rec := httptest.NewRecorder()
 			r.ServeHTTP(rec, req)
 
@@ -265,6 +318,7 @@ func TestDelete(t *testing.T) {
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
+			r.Use(middleware.Authenticate())
 			validator.SetupValidator()
 
 			r.DELETE("/:id", h.Delete)
@@ -273,6 +327,70 @@ func TestDelete(t *testing.T) {
 				id = fmt.Sprintf("%d", tt.request.ID)
 			}
 			req := httptest.NewRequest("DELETE", "/"+id, bytes.NewBuffer(reqJSON))
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestMove(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		body             string
+		usecase          usecase.Todo
+		want_status_code int
+	}{
+		{
+			name: "正常系_タスクをリストに移動できること",
+			body: `{"list_id": 3}`,
+			usecase: &mockTodo{
+				mockMove: func(listID int) error {
+					if listID != 3 {
+						return fmt.Errorf("unexpected list %d", listID)
+					}
+					return nil
+				},
+			},
+			want_status_code: http.StatusNoContent,
+		},
+		{
+			name:             "異常系_リストIDが負の場合バリデーションエラーになること",
+			body:             `{"list_id": -1}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_リストが見つからない場合",
+			body: `{"list_id": 3}`,
+			usecase: &mockTodo{
+				mockMove: func(listID int) error {
+					return fmt.Errorf("list %d: %w", listID, usecase.ErrNotFound)
+				},
+			},
+			want_status_code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.PUT("/:id/list", h.Move)
+			req := httptest.NewRequest("PUT", "/1/list", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
 			rec := httptest.NewRecorder()
 			r.ServeHTTP(rec, req)
 
@@ -334,6 +452,30 @@ func TestFind(t *testing.T) {
 			},
 			want_status_code: http.StatusInternalServerError,
 		},
+		{
+			name: "異常系_タイムアウトした場合504エラーになること",
+			request: handler.FindRequestParam{
+				ID: 1,
+			},
+			usecase: &mockTodo{
+				mockFind: func() (*model.Todo, error) {
+					return nil, context.DeadlineExceeded
+				},
+			},
+			want_status_code: http.StatusGatewayTimeout,
+		},
+		{
+			name: "異常系_リクエストがキャンセルされた場合503エラーになること",
+			request: handler.FindRequestParam{
+				ID: 1,
+			},
+			usecase: &mockTodo{
+				mockFind: func() (*model.Todo, error) {
+					return nil, fmt.Errorf("query: %w", context.Canceled)
+				},
+			},
+			want_status_code: http.StatusServiceUnavailable,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -344,6 +486,7 @@ func TestFind(t *testing.T) {
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
+			r.Use(middleware.Authenticate())
 			validator.SetupValidator()
 
 			r.GET("/:id", h.Find)
@@ -352,6 +495,7 @@ func TestFind(t *testing.T) {
 				id = fmt.Sprintf("%d", tt.request.ID)
 			}
 			req := httptest.NewRequest("GET", "/"+id, bytes.NewBuffer(reqJSON))
+			setAuthHeader(req)
 			rec := httptest.NewRecorder()
 			r.ServeHTTP(rec, req)
 
@@ -382,6 +526,7 @@ func TestFindAll(t *testing.T) {
 
 	tests := []struct {
 		name             string
+		query            string
 		usecase          usecase.Todo
 		want_status_code int
 		want_response    []*model.Todo
@@ -410,6 +555,25 @@ func TestFindAll(t *testing.T) {
 			want_status_code: http.StatusOK,
 			want_response:    []*model.Todo{},
 		},
+		{
+			name:  "正常系_リストを指定した場合リストのタスクだけを検索すること",
+			query: "?list_id=3",
+			usecase: &mockTodo{
+				mockSearch: func(f model.TodoFilter) ([]*model.Todo, error) {
+					if f.ListID != 3 {
+						return nil, fmt.Errorf("unexpected filter %+v", f)
+					}
+					return []*model.Todo{&expected}, nil
+				},
+			},
+			want_status_code: http.StatusOK,
+			want_response:    []*model.Todo{&expected},
+		},
+		{
+			name:             "異常系_リストIDが負の場合バリデーションエラーになること",
+			query:            "?list_id=-1",
+			want_status_code: http.StatusBadRequest,
+		},
 		{
 			name: "異常系_タスクの検索に失敗した場合",
 			usecase: &mockTodo{
@@ -428,10 +592,12 @@ func TestFindAll(t *testing.T) {
 
 			gin.SetMode(gin.TestMode)
 			r := gin.New()
+			r.Use(middleware.Authenticate())
 			validator.SetupValidator()
 
 			r.GET("/", h.FindAll)
-			req := httptest.NewRequest("GET", "/", nil)
+			req := httptest.NewRequest("GET", "/"+tt.query, nil)
+			setAuthHeader(req)
 			rec := httptest.NewRecorder()
 			r.ServeHTTP(rec, req)
 
@@ -450,3 +616,588 @@ func TestFindAll(t *testing.T) {
 		})
 	}
 }
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		usecase          usecase.Todo
+		want_status_code int
+	}{
+		{
+			name:  "正常系_タスクの変更履歴が取得できること",
+			query: "?limit=10",
+			usecase: &mockTodo{
+				mockHistory: func() ([]*model.TodoHistory, error) {
+					return []*model.TodoHistory{{ID: 1, TodoID: 1, Action: model.TodoActionCreated}}, nil
+				},
+			},
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "異常系_件数が上限を超えた場合バリデーションエラーになること",
+			query:            "?limit=501",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_変更履歴がない場合",
+			usecase: &mockTodo{
+				mockHistory: func() ([]*model.TodoHistory, error) {
+					return nil, usecase.ErrNotFound
+				},
+			},
+			want_status_code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.GET("/:id/history", h.History)
+			req := httptest.NewRequest("GET", "/1/history"+tt.query, nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestActivity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		want_filter      model.ActivityFilter
+		want_status_code int
+	}{
+		{
+			name:  "正常系_操作者・期間・操作種別で絞り込めること",
+			query: "?user_id=2&action=deleted&from=2023-01-01T00:00:00Z&to=2023-02-01T00:00:00%2B09:00",
+			want_filter: model.ActivityFilter{
+				UserID: 2,
+				Action: model.TodoActionDeleted,
+				From:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2023, 1, 31, 15, 0, 0, 0, time.UTC),
+				Limit:  50,
+			},
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "異常系_不明な操作種別の場合バリデーションエラーになること",
+			query:            "?action=archived",
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_日時の形式が不正な場合バリデーションエラーになること",
+			query:            "?from=2023-01-01",
+			want_status_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got model.ActivityFilter
+			h := handler.NewTodo(&mockTodo{
+				mockActivity: func(f model.ActivityFilter) ([]*model.TodoHistory, error) {
+					got = f
+					return []*model.TodoHistory{}, nil
+				},
+			})
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.GET("/activity", h.Activity)
+			req := httptest.NewRequest("GET", "/activity"+tt.query, nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code == http.StatusOK && (!got.From.Equal(tt.want_filter.From) || !got.To.Equal(tt.want_filter.To) ||
+				got.UserID != tt.want_filter.UserID || got.Action != tt.want_filter.Action || got.Limit != tt.want_filter.Limit) {
+				t.Errorf("want = %+v, got = %+v", tt.want_filter, got)
+			}
+		})
+	}
+}
+
+func TestFindAsOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		query            string
+		want_status_code int
+	}{
+		{
+			name:             "正常系_指定した時点のタスクが取得できること",
+			query:            "?as_of=2023-01-01T00:00:00Z",
+			want_status_code: http.StatusOK,
+		},
+		{
+			name:             "異常系_指定した時点にタスクが存在しない場合",
+			query:            "?as_of=2000-01-01T00:00:00Z",
+			want_status_code: http.StatusNotFound,
+		},
+		{
+			name:             "異常系_日時の形式が不正な場合バリデーションエラーになること",
+			query:            "?as_of=yesterday",
+			want_status_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(&mockTodo{
+				mockFindAsOf: func() (*model.Todo, error) {
+					if tt.want_status_code == http.StatusNotFound {
+						return nil, nil
+					}
+					return &model.Todo{ID: 1, Task: "task", Status: model.Created}, nil
+				},
+			})
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.GET("/:id", h.Find)
+			req := httptest.NewRequest("GET", "/1"+tt.query, nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRevert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		request          handler.RevertRequestBodyParam
+		usecase          usecase.Todo
+		want_status_code int
+	}{
+		{
+			name:    "正常系_リビジョンに戻せること",
+			request: handler.RevertRequestBodyParam{Revision: 1},
+			usecase: &mockTodo{
+				mockRevert: func() error { return nil },
+			},
+			want_status_code: http.StatusNoContent,
+		},
+		{
+			name:             "異常系_リビジョンが指定されていない場合バリデーションエラーになること",
+			request:          handler.RevertRequestBodyParam{},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:    "異常系_基準のリビジョンが古い場合",
+			request: handler.RevertRequestBodyParam{Revision: 1, BaseRevision: 2},
+			usecase: &mockTodo{
+				mockRevert: func() error { return usecase.ErrConflict },
+			},
+			want_status_code: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(tt.usecase)
+			reqJSON, _ := json.Marshal(tt.request)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/:id/revert", h.Revert)
+			req := httptest.NewRequest("POST", "/1/revert", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestBulk(t *testing.T) {
+	t.Parallel()
+
+	// succeed は全ての操作を成功させ、作成された操作に連番のIDを振る
+	succeed := func(ops []usecase.TodoOperation, atomic bool) ([]usecase.TodoOperationResult, error) {
+		res := make([]usecase.TodoOperationResult, len(ops))
+		for i, op := range ops {
+			res[i] = usecase.TodoOperationResult{ID: op.ID}
+			if op.Type == usecase.TodoOperationCreate {
+				res[i].ID = 100 + i
+			}
+		}
+		return res, nil
+	}
+	tests := []struct {
+		name             string
+		request          handler.BulkRequestBodyParam
+		usecase          usecase.Todo
+		want_status_code int
+		want_results     []handler.BulkResult
+	}{
+		{
+			name: "正常系_操作ごとの結果が返ること",
+			request: handler.BulkRequestBodyParam{Operations: []handler.BulkOperationParam{
+				{Op: usecase.TodoOperationCreate, Task: "task"},
+				{Op: usecase.TodoOperationUpdate, ID: 1, Task: "task", Status: model.Done},
+				{Op: usecase.TodoOperationDelete, ID: 2},
+			}},
+			usecase: &mockTodo{
+				mockBulk: func(ops []usecase.TodoOperation, atomic bool) ([]usecase.TodoOperationResult, error) {
+					if !atomic {
+						return nil, errors.New("atomic mode is expected by default")
+					}
+					return succeed(ops, atomic)
+				},
+			},
+			want_status_code: http.StatusOK,
+			want_results: []handler.BulkResult{
+				{ID: 100, Status: http.StatusCreated},
+				{ID: 1, Status: http.StatusNoContent},
+				{ID: 2, Status: http.StatusNoContent},
+			},
+		},
+		{
+			name: "正常系_ベストエフォートの場合不正な操作以外が実行されること",
+			request: handler.BulkRequestBodyParam{Mode: "best_effort", Operations: []handler.BulkOperationParam{
+				{Op: usecase.TodoOperationCreate},
+				{Op: usecase.TodoOperationUpdate, ID: 1, Task: "task", Status: "xxxx"},
+				{Op: usecase.TodoOperationDelete},
+				{Op: usecase.TodoOperationCreate, Task: "task"},
+			}},
+			usecase:          &mockTodo{mockBulk: succeed},
+			want_status_code: http.StatusOK,
+			want_results: []handler.BulkResult{
+				{Status: http.StatusBadRequest},
+				{ID: 1, Status: http.StatusBadRequest},
+				{Status: http.StatusBadRequest},
+				{ID: 100, Status: http.StatusCreated},
+			},
+		},
+		{
+			name: "異常系_一括モードで不正な操作がある場合何も実行されないこと",
+			request: handler.BulkRequestBodyParam{Operations: []handler.BulkOperationParam{
+				{Op: usecase.TodoOperationCreate, Task: "task"},
+				{Op: usecase.TodoOperationCreate},
+			}},
+			want_status_code: http.StatusUnprocessableEntity,
+			want_results: []handler.BulkResult{
+				{Status: http.StatusFailedDependency},
+				{Status: http.StatusBadRequest},
+			},
+		},
+		{
+			name: "異常系_一括モードで操作が失敗した場合",
+			request: handler.BulkRequestBodyParam{Operations: []handler.BulkOperationParam{
+				{Op: usecase.TodoOperationCreate, Task: "task"},
+				{Op: usecase.TodoOperationDelete, ID: 1},
+			}},
+			usecase: &mockTodo{
+				mockBulk: func(ops []usecase.TodoOperation, atomic bool) ([]usecase.TodoOperationResult, error) {
+					return []usecase.TodoOperationResult{{Err: usecase.ErrRolledBack}, {ID: 1, Err: usecase.ErrNotFound}}, nil
+				},
+			},
+			want_status_code: http.StatusUnprocessableEntity,
+			want_results: []handler.BulkResult{
+				{Status: http.StatusFailedDependency},
+				{ID: 1, Status: http.StatusNotFound},
+			},
+		},
+		{
+			name:             "異常系_操作がない場合バリデーションエラーになること",
+			request:          handler.BulkRequestBodyParam{},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_操作が上限を超える場合バリデーションエラーになること",
+			request: handler.BulkRequestBodyParam{
+				Operations: make([]handler.BulkOperationParam, 501),
+			},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_モードが不正な場合バリデーションエラーになること",
+			request: handler.BulkRequestBodyParam{Mode: "xxxx", Operations: []handler.BulkOperationParam{
+				{Op: usecase.TodoOperationCreate, Task: "task"},
+			}},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_権限がない場合",
+			request: handler.BulkRequestBodyParam{Operations: []handler.BulkOperationParam{
+				{Op: usecase.TodoOperationCreate, Task: "task"},
+			}},
+			usecase: &mockTodo{
+				mockBulk: func(ops []usecase.TodoOperation, atomic bool) ([]usecase.TodoOperationResult, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(tt.usecase)
+			reqJSON, _ := json.Marshal(tt.request)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/bulk", h.Bulk)
+			req := httptest.NewRequest("POST", "/bulk", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if tt.want_results == nil {
+				return
+			}
+			var res handler.BulkResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(res.Results) != len(tt.want_results) {
+				t.Fatalf("want = %v, got = %v", tt.want_results, res.Results)
+			}
+			for i, want := range tt.want_results {
+				got := res.Results[i]
+				if got.ID != want.ID || got.Status != want.Status {
+					t.Errorf("results[%d]: want = %+v, got = %+v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSetStatus(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		request          string
+		usecase          usecase.Todo
+		want_status_code int
+		want_count       int
+	}{
+		{
+			name:    "正常系_絞り込み条件が渡され結果の件数が返ること",
+			request: `{"status": "done", "dry_run": true, "filter": {"status": "processing", "ids": [1, 2], "created_from": "2023-01-01T00:00:00Z"}}`,
+			usecase: &mockTodo{
+				mockSetStatus: func(f model.TodoFilter, dryRun bool) (*usecase.StatusChange, error) {
+					if !dryRun || f.Status != model.Processing || len(f.IDs) != 2 || !f.CreatedFrom.Equal(from) {
+						return nil, fmt.Errorf("unexpected filter: %+v", f)
+					}
+					return &usecase.StatusChange{
+						Matched: []*model.Todo{{ID: 1}, {ID: 2}},
+						Changed: []int{1, 2},
+					}, nil
+				},
+			},
+			want_status_code: http.StatusOK,
+			want_count:       2,
+		},
+		{
+			name:    "正常系_リストだけで絞り込めること",
+			request: `{"status": "done", "filter": {"list_id": 3}}`,
+			usecase: &mockTodo{
+				mockSetStatus: func(f model.TodoFilter, dryRun bool) (*usecase.StatusChange, error) {
+					if f.ListID != 3 {
+						return nil, fmt.Errorf("unexpected filter: %+v", f)
+					}
+					return &usecase.StatusChange{Matched: []*model.Todo{{ID: 1, ListID: 3}}, Changed: []int{1}}, nil
+				},
+			},
+			want_status_code: http.StatusOK,
+			want_count:       1,
+		},
+		{
+			name:             "異常系_リストIDが不正な場合バリデーションエラーになること",
+			request:          `{"status": "done", "filter": {"list_id": -1}}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_変更後のステータスがない場合バリデーションエラーになること",
+			request:          `{"filter": {"ids": [1]}}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_絞り込みのステータスが不正な場合バリデーションエラーになること",
+			request:          `{"status": "done", "filter": {"status": "xxxx"}}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_IDが不正な場合バリデーションエラーになること",
+			request:          `{"status": "done", "filter": {"ids": [0]}}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:             "異常系_絞り込み条件がない場合バリデーションエラーになること",
+			request:          `{"status": "done", "filter": {}}`,
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:    "異常系_該当するタスクが多すぎる場合422エラーになること",
+			request: `{"status": "done", "filter": {"status": "processing"}}`,
+			usecase: &mockTodo{
+				mockSetStatus: func(f model.TodoFilter, dryRun bool) (*usecase.StatusChange, error) {
+					return nil, usecase.ErrTooManyMatches
+				},
+			},
+			want_status_code: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "異常系_権限がない場合",
+			request: `{"status": "done", "filter": {"ids": [1]}}`,
+			usecase: &mockTodo{
+				mockSetStatus: func(f model.TodoFilter, dryRun bool) (*usecase.StatusChange, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/actions/set-status", h.SetStatus)
+			req := httptest.NewRequest("POST", "/actions/set-status", bytes.NewBufferString(tt.request))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var res handler.SetStatusResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if res.Count != tt.want_count {
+				t.Errorf("want = %v, got = %v", tt.want_count, res.Count)
+			}
+		})
+	}
+}
+
+func TestTodoAuthorization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		header           map[string]string
+		usecase          usecase.Todo
+		want_status_code int
+	}{
+		{
+			name:             "異常系_ユーザーIDが指定されていなかった場合401エラーになること",
+			header:           map[string]string{handler.WorkspaceIDHeader: "1"},
+			want_status_code: http.StatusUnauthorized,
+		},
+		{
+			name:             "異常系_ワークスペースIDが指定されていなかった場合400エラーになること",
+			header:           map[string]string{middleware.UserIDHeader: "1"},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:   "異常系_権限がなかった場合403エラーになること",
+			header: map[string]string{middleware.UserIDHeader: "1", handler.WorkspaceIDHeader: "1"},
+			usecase: &mockTodo{
+				mockCreate: func() error {
+					return usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewTodo(tt.usecase)
+			reqJSON, _ := json.Marshal(handler.CreateRequestParam{Task: "test"})
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/", h.Create)
+			req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func setAuthHeader(req *http.Request) {
+	req.Header.Set(middleware.UserIDHeader, "1")
+	req.Header.Set(handler.WorkspaceIDHeader, "1")
+}
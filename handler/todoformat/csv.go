@@ -0,0 +1,126 @@
+package todoformat
+
+import (
+	"app/domain/model"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVHeader is the header of exported files. Columns are only ever appended
+// so that spreadsheets built on an export keep working.
+var CSVHeader = []string{"id", "task", "status", "created_at", "updated_at"}
+
+// CSVColumns maps the imported fields to header names of the file. The
+// status column is optional.
+type CSVColumns struct {
+	Task   string
+	Status string
+}
+
+var DefaultCSVColumns = CSVColumns{Task: "task", Status: "status"}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVEncoder writes RFC 4180 CSV with the CSVHeader columns and CRLF
+// line endings.
+func NewCSVEncoder(w io.Writer) Encoder {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	return &csvEncoder{w: cw}
+}
+
+func (e *csvEncoder) Encode(todos ...*model.Todo) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		err := e.w.Write([]string{
+			strconv.Itoa(todo.ID),
+			todo.Task,
+			string(todo.Status),
+			todo.CreatedAt.UTC().Format(time.RFC3339),
+			todo.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write(CSVHeader)
+}
+
+// ReadCSV reads the records of a CSV file whose first line is a header.
+// Columns other than the mapped ones are ignored.
+func ReadCSV(r io.Reader, columns CSVColumns) ([]Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, invalidFile("the header is missing")
+	}
+	if err != nil {
+		return nil, readError(err)
+	}
+	task, status := -1, -1
+	for i, name := range header {
+		// Spreadsheets often save CSV with a byte order mark.
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		switch {
+		case strings.EqualFold(name, columns.Task):
+			task = i
+		case columns.Status != "" && strings.EqualFold(name, columns.Status):
+			status = i
+		}
+	}
+	if task < 0 {
+		return nil, invalidFile("the header has no %q column", columns.Task)
+	}
+
+	var records []Record
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, readError(err)
+		}
+		line, _ := cr.FieldPos(0)
+		records = append(records, Record{
+			Line:   line,
+			Task:   field(fields, task),
+			Status: model.TaskStatus(field(fields, status)),
+		})
+	}
+}
+
+// field returns the i-th field, or an empty string when the row is shorter.
+func field(fields []string, i int) string {
+	if i < 0 || i >= len(fields) {
+		return ""
+	}
+	return strings.TrimSpace(fields[i])
+}
@@ -0,0 +1,117 @@
+package todoformat_test
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		todos    [][]*model.Todo
+		expected string
+	}{
+		{
+			name: "正常系_RFC4180に従ってクォートされること",
+			todos: [][]*model.Todo{
+				{{ID: 1, Task: `say "hi", then leave`, Status: model.Done, CreatedAt: at, UpdatedAt: at}},
+				{{ID: 2, Task: "line1\nline2", Status: model.Created, CreatedAt: at, UpdatedAt: at}},
+			},
+			expected: "id,task,status,created_at,updated_at\r\n" +
+				"1,\"say \"\"hi\"\", then leave\",done,2023-01-02T03:04:05Z,2023-01-02T03:04:05Z\r\n" +
+				"2,\"line1\r\nline2\",created,2023-01-02T03:04:05Z,2023-01-02T03:04:05Z\r\n",
+		},
+		{
+			name:     "正常系_タスクがない場合もヘッダーが出力されること",
+			expected: "id,task,status,created_at,updated_at\r\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			enc := todoformat.NewCSVEncoder(&buf)
+			for _, todos := range tt.todos {
+				if err := enc.Encode(todos...); err != nil {
+					t.Fatalf("want = %v, got = %v", nil, err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("diff %s", cmp.Diff(buf.String(), tt.expected))
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		columns  todoformat.CSVColumns
+		expected []todoformat.Record
+		err      error
+	}{
+		{
+			name:    "正常系_行番号とともに読み込めること",
+			input:   "id,task,status\n1,task1,done\n2,\"multi\nline\",\n3,task3",
+			columns: todoformat.DefaultCSVColumns,
+			expected: []todoformat.Record{
+				{Line: 2, Task: "task1", Status: model.Done},
+				{Line: 3, Task: "multi\nline"},
+				{Line: 5, Task: "task3"},
+			},
+		},
+		{
+			name:    "正常系_列の対応付けとBOMが扱えること",
+			input:   "\ufeffTitle,Notes,State\r\ntask1,xxxx,processing\r\n",
+			columns: todoformat.CSVColumns{Task: "title", Status: "state"},
+			expected: []todoformat.Record{
+				{Line: 2, Task: "task1", Status: model.Processing},
+			},
+		},
+		{
+			name:    "異常系_タスクの列がない場合エラーになること",
+			input:   "name,status\ntask1,done\n",
+			columns: todoformat.DefaultCSVColumns,
+			err:     todoformat.ErrInvalidFile,
+		},
+		{
+			name:    "異常系_空のファイルはエラーになること",
+			columns: todoformat.DefaultCSVColumns,
+			err:     todoformat.ErrInvalidFile,
+		},
+		{
+			name:    "異常系_クォートが閉じられていない場合エラーになること",
+			input:   "task\n\"task1\n",
+			columns: todoformat.DefaultCSVColumns,
+			err:     todoformat.ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := todoformat.ReadCSV(strings.NewReader(tt.input), tt.columns)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+		})
+	}
+}
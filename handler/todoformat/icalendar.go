@@ -0,0 +1,240 @@
+package todoformat
+
+import (
+	"app/domain/model"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icalendarTime = "20060102T150405Z"
+	// icalendarLineLength is the maximum length of a content line in octets,
+	// without the line break (RFC 5545 3.1).
+	icalendarLineLength = 75
+	uidDomain           = "go-api-sample-todo"
+)
+
+var icalendarStatuses = map[model.TaskStatus]string{
+	model.Created:    "NEEDS-ACTION",
+	model.Processing: "IN-PROCESS",
+	model.Done:       "COMPLETED",
+}
+
+var icalendarText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+var icalendarUnescape = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// TodoUID is the UID of the VTODO of a todo. It stays the same across
+// exports so that calendar apps update their copy.
+func TodoUID(id int) string {
+	return fmt.Sprintf("todo-%d@%s", id, uidDomain)
+}
+
+// TodoIDFromUID returns the todo ID of a UID made by TodoUID.
+func TodoIDFromUID(uid string) (int, bool) {
+	s, ok := strings.CutSuffix(uid, "@"+uidDomain)
+	if !ok {
+		return 0, false
+	}
+	s, ok = strings.CutPrefix(s, "todo-")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+type icalendarEncoder struct {
+	w      io.Writer
+	now    time.Time
+	uid    func(todo *model.Todo) string
+	header bool
+}
+
+// NewICalendarEncoder writes an RFC 5545 calendar with a VTODO per todo.
+// now is the DTSTAMP of the components.
+func NewICalendarEncoder(w io.Writer, now time.Time) Encoder {
+	return &icalendarEncoder{w: w, now: now}
+}
+
+// NewICalendarEncoderWithUID is NewICalendarEncoder with the UID that uid
+// returns for a todo, such as the one a calendar app created it with. Todos
+// for which it returns "" get TodoUID.
+func NewICalendarEncoderWithUID(w io.Writer, now time.Time, uid func(todo *model.Todo) string) Encoder {
+	return &icalendarEncoder{w: w, now: now, uid: uid}
+}
+
+func (e *icalendarEncoder) Encode(todos ...*model.Todo) error {
+	var b strings.Builder
+	e.writeHeader(&b)
+	for _, todo := range todos {
+		writeLine(&b, "BEGIN:VTODO")
+		writeLine(&b, "UID:"+e.uidOf(todo))
+		writeLine(&b, "DTSTAMP:"+e.now.UTC().Format(icalendarTime))
+		writeLine(&b, "SUMMARY:"+icalendarText.Replace(todo.Task))
+		if status, ok := icalendarStatuses[todo.Status]; ok {
+			writeLine(&b, "STATUS:"+status)
+		}
+		if !todo.CreatedAt.IsZero() {
+			writeLine(&b, "CREATED:"+todo.CreatedAt.UTC().Format(icalendarTime))
+		}
+		if !todo.UpdatedAt.IsZero() {
+			writeLine(&b, "LAST-MODIFIED:"+todo.UpdatedAt.UTC().Format(icalendarTime))
+		}
+		writeLine(&b, "END:VTODO")
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *icalendarEncoder) uidOf(todo *model.Todo) string {
+	if e.uid != nil {
+		if uid := e.uid(todo); uid != "" {
+			return uid
+		}
+	}
+	return TodoUID(todo.ID)
+}
+
+func (e *icalendarEncoder) Close() error {
+	var b strings.Builder
+	e.writeHeader(&b)
+	writeLine(&b, "END:VCALENDAR")
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *icalendarEncoder) writeHeader(b *strings.Builder) {
+	if e.header {
+		return
+	}
+	e.header = true
+	writeLine(b, "BEGIN:VCALENDAR")
+	writeLine(b, "VERSION:2.0")
+	writeLine(b, "PRODID:-//"+uidDomain+"//todo//EN")
+}
+
+// writeLine folds the line after 75 octets without splitting a UTF-8
+// sequence; continuation lines start with a space.
+func writeLine(b *strings.Builder, line string) {
+	limit := icalendarLineLength
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i] + "\r\n ")
+		line = line[i:]
+		// the leading space counts towards the length
+		limit = icalendarLineLength - 1
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// ReadICalendar reads the VTODO components of a calendar. Other components,
+// including alarms inside a VTODO, are skipped. A STATUS without a
+// counterpart, such as CANCELLED, is returned as is and fails validation.
+func ReadICalendar(r io.Reader) ([]Record, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0].text, "BEGIN:VCALENDAR") {
+		return nil, invalidFile("the file does not start with BEGIN:VCALENDAR")
+	}
+
+	var records []Record
+	var todo *Record
+	// depth counts the components opened inside the current VTODO
+	depth := 0
+	for _, l := range lines {
+		name, value, ok := property(l.text)
+		if !ok {
+			return nil, invalidFile("line %d is not a content line", l.number)
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO") && todo == nil:
+			todo = &Record{Line: l.number}
+		case todo == nil:
+		case name == "BEGIN":
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(value, "VTODO"):
+			records = append(records, *todo)
+			todo = nil
+		case depth > 0:
+		case name == "UID":
+			todo.UID = value
+		case name == "SUMMARY":
+			todo.Task = icalendarUnescape.Replace(value)
+		case name == "STATUS":
+			todo.Status = model.TaskStatus(value)
+			for status, s := range icalendarStatuses {
+				if strings.EqualFold(value, s) {
+					todo.Status = status
+				}
+			}
+		}
+	}
+	if todo != nil {
+		return nil, invalidFile("the VTODO on line %d is not closed", todo.Line)
+	}
+	return records, nil
+}
+
+type contentLine struct {
+	number int
+	text   string
+}
+
+// unfold joins folded lines and keeps the number of their first line.
+func unfold(r io.Reader) ([]contentLine, error) {
+	var lines []contentLine
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		text := strings.TrimRight(s.Text(), "\r")
+		if n == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		lines = append(lines, contentLine{n, text})
+	}
+	if err := s.Err(); err != nil {
+		return nil, readError(err)
+	}
+	return lines, nil
+}
+
+// property splits a content line into its upper case name and its value;
+// parameters are dropped. Parameter values may contain quoted colons.
+func property(line string) (string, string, bool) {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if quoted || i == 0 {
+				continue
+			}
+			name, _, _ := strings.Cut(line[:i], ";")
+			return strings.ToUpper(name), line[i+1:], true
+		}
+	}
+	return "", "", false
+}
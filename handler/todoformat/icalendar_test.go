@@ -0,0 +1,171 @@
+package todoformat_test
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestICalendarEncoder(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2023, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	todos := []*model.Todo{
+		{ID: 1, Task: "call mom; then dad, maybe\nlater", Status: model.Processing, CreatedAt: created, UpdatedAt: created},
+		{ID: 2, Task: strings.Repeat("あ", 30), Status: model.Done},
+	}
+	expected := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"PRODID:-//go-api-sample-todo//todo//EN\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:todo-1@go-api-sample-todo\r\n" +
+		"DTSTAMP:20230103T000000Z\r\n" +
+		"SUMMARY:call mom\\; then dad\\, maybe\\nlater\r\n" +
+		"STATUS:IN-PROCESS\r\n" +
+		"CREATED:20230101T000000Z\r\n" +
+		"LAST-MODIFIED:20230101T000000Z\r\n" +
+		"END:VTODO\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:todo-2@go-api-sample-todo\r\n" +
+		"DTSTAMP:20230103T000000Z\r\n" +
+		// 75オクテットを超えないようにマルチバイト文字の途中では折り返さない
+		"SUMMARY:" + strings.Repeat("あ", 22) + "\r\n" +
+		" " + strings.Repeat("あ", 8) + "\r\n" +
+		"STATUS:COMPLETED\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	var buf bytes.Buffer
+	enc := todoformat.NewICalendarEncoder(&buf, now)
+	if err := enc.Encode(todos...); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if buf.String() != expected {
+		t.Errorf("diff %s", cmp.Diff(buf.String(), expected))
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line is longer than 75 octets: %q", line)
+		}
+	}
+}
+
+func TestICalendarEncoderWithUID(t *testing.T) {
+	t.Parallel()
+	uids := map[int]string{1: "x@example.com"}
+	var buf bytes.Buffer
+	enc := todoformat.NewICalendarEncoderWithUID(&buf, time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC), func(todo *model.Todo) string {
+		return uids[todo.ID]
+	})
+	if err := enc.Encode(&model.Todo{ID: 1, Task: "a"}, &model.Todo{ID: 2, Task: "b"}); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	// 指定のないタスクは既定のUIDになる
+	for _, want := range []string{"UID:x@example.com\r\n", "UID:todo-2@go-api-sample-todo\r\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%q does not contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestICalendarRoundTrip(t *testing.T) {
+	t.Parallel()
+	todos := roundTripTodos()
+	for i, todo := range todos {
+		todo.ID = i + 1
+	}
+	todos = append(todos, &model.Todo{ID: 99, Task: `back\slash, "quotes"; and` + "\n" + strings.Repeat("long ", 40), Status: model.Created})
+	var buf bytes.Buffer
+	enc := todoformat.NewICalendarEncoder(&buf, time.Now())
+	if err := enc.Encode(todos...); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+
+	got, err := todoformat.ReadICalendar(&buf)
+	if err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	want := records(todos)
+	for i := range want {
+		want[i].UID = todoformat.TodoUID(todos[i].ID)
+	}
+	if !cmp.Equal(withoutLines(got), want) {
+		t.Errorf("diff %s", cmp.Diff(withoutLines(got), want))
+	}
+}
+
+func TestReadICalendar(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected []todoformat.Record
+		err      error
+	}{
+		{
+			name: "正常系_VTODOのみが読み込まれること",
+			input: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+				"BEGIN:VEVENT\r\nUID:event\r\nSUMMARY:meeting\r\nEND:VEVENT\r\n" +
+				"BEGIN:VTODO\r\nUID:a@example.com\r\nSUMMARY;LANGUAGE=en;ALTREP=\"http://example.com:80/\":fol\r\n ded\r\n" +
+				"STATUS:completed\r\nBEGIN:VALARM\r\nSUMMARY:alarm\r\nEND:VALARM\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nSUMMARY:cancelled\r\nSTATUS:CANCELLED\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+			expected: []todoformat.Record{
+				{Line: 7, UID: "a@example.com", Task: "folded", Status: model.Done},
+				{Line: 16, Task: "cancelled", Status: "CANCELLED"},
+			},
+		},
+		{
+			name:  "異常系_VCALENDARで始まらない場合エラーになること",
+			input: "BEGIN:VTODO\r\nEND:VTODO\r\n",
+			err:   todoformat.ErrInvalidFile,
+		},
+		{
+			name:  "異常系_VTODOが閉じられていない場合エラーになること",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:task\r\nEND:VCALENDAR\r\n",
+			err:   todoformat.ErrInvalidFile,
+		},
+		{
+			name:  "異常系_コンテンツ行でない行がある場合エラーになること",
+			input: "BEGIN:VCALENDAR\r\nxxxx\r\nEND:VCALENDAR\r\n",
+			err:   todoformat.ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := todoformat.ReadICalendar(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+		})
+	}
+}
+
+func TestTodoIDFromUID(t *testing.T) {
+	t.Parallel()
+	if id, ok := todoformat.TodoIDFromUID(todoformat.TodoUID(12)); !ok || id != 12 {
+		t.Errorf("want = %v, got = %v", 12, id)
+	}
+	for _, uid := range []string{"todo-12@example.com", "todo-x@go-api-sample-todo", "todo-0@go-api-sample-todo"} {
+		if _, ok := todoformat.TodoIDFromUID(uid); ok {
+			t.Errorf("%s must not be a todo UID", uid)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package todoformat
+
+import (
+	"app/domain/model"
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// markdownItem matches a task list item such as "- [ ] task". Items may be
+// indented, but since there are no subtasks they are all read as top-level
+// todos.
+var markdownItem = regexp.MustCompile(`^\s*[-*+] \[([ xX/])\] (.*)$`)
+
+// GitHub only knows open and checked items. Processing todos use "[/]",
+// which some Markdown task tools read as in progress and others show as
+// plain text.
+var markdownMarks = map[model.TaskStatus]string{
+	model.Created:    " ",
+	model.Processing: "/",
+	model.Done:       "x",
+}
+
+type markdownEncoder struct {
+	w io.Writer
+}
+
+// NewMarkdownEncoder writes a GitHub-style task list. Line breaks in tasks
+// are written as spaces.
+func NewMarkdownEncoder(w io.Writer) Encoder {
+	return &markdownEncoder{w}
+}
+
+func (e *markdownEncoder) Encode(todos ...*model.Todo) error {
+	var b strings.Builder
+	for _, todo := range todos {
+		mark, ok := markdownMarks[todo.Status]
+		if !ok {
+			mark = " "
+		}
+		b.WriteString("- [" + mark + "] " + oneLine(todo.Task) + "\n")
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *markdownEncoder) Close() error {
+	return nil
+}
+
+// ReadMarkdown reads the task list items of a Markdown document and ignores
+// every other line.
+func ReadMarkdown(r io.Reader) ([]Record, error) {
+	var records []Record
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		m := markdownItem.FindStringSubmatch(strings.TrimRight(s.Text(), "\r"))
+		if m == nil {
+			continue
+		}
+		rec := Record{Line: line, Task: strings.TrimSpace(m[2])}
+		for status, mark := range markdownMarks {
+			if strings.EqualFold(m[1], mark) {
+				rec.Status = status
+			}
+		}
+		records = append(records, rec)
+	}
+	if err := s.Err(); err != nil {
+		return nil, readError(err)
+	}
+	return records, nil
+}
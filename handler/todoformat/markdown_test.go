@@ -0,0 +1,49 @@
+package todoformat_test
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	enc := todoformat.NewMarkdownEncoder(&buf)
+	if err := enc.Encode(roundTripTodos()...); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+
+	got, err := todoformat.ReadMarkdown(&buf)
+	if err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	want := records(roundTripTodos())
+	if !cmp.Equal(withoutLines(got), want) {
+		t.Errorf("diff %s", cmp.Diff(withoutLines(got), want))
+	}
+}
+
+func TestReadMarkdown(t *testing.T) {
+	t.Parallel()
+	input := "# Sprint\n\n- [ ] open\n  * [X] nested done\r\n+ [/] in progress\n- plain item\n- [?] unknown\n"
+	expected := []todoformat.Record{
+		{Line: 3, Task: "open", Status: model.Created},
+		{Line: 4, Task: "nested done", Status: model.Done},
+		{Line: 5, Task: "in progress", Status: model.Processing},
+	}
+	got, err := todoformat.ReadMarkdown(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if !cmp.Equal(got, expected) {
+		t.Errorf("diff %s", cmp.Diff(got, expected))
+	}
+}
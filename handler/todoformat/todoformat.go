@@ -0,0 +1,44 @@
+// Package todoformat converts todos from and to the file formats of the
+// import and export endpoints. Validation of the imported values is left to
+// the handler so that it uses the same rules as the JSON API.
+package todoformat
+
+import (
+	"app/domain/model"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidFile = errors.New("invalid file")
+
+// Encoder writes todos in a file format. Encode can be called repeatedly to
+// stream large exports.
+type Encoder interface {
+	Encode(todos ...*model.Todo) error
+	// Close writes the end of the file. It does not close the underlying
+	// writer.
+	Close() error
+}
+
+// Record is a todo read from an import file. Line is the line it starts on,
+// or its position in a JSON export, counting from 1. Status is empty when
+// the file does not specify one, and UID is only set by formats that
+// identify their entries.
+type Record struct {
+	Line   int
+	Task   string
+	Status model.TaskStatus
+	UID    string
+	// List is the name of the list the todo is imported into, or empty.
+	List string
+}
+
+func invalidFile(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidFile, fmt.Sprintf(format, args...))
+}
+
+// readError keeps the reader's error, such as *http.MaxBytesError, in the
+// chain.
+func readError(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidFile, err)
+}
@@ -0,0 +1,104 @@
+package todoformat
+
+import (
+	"app/domain/model"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type todoistBackup struct {
+	Projects []struct {
+		ID   todoistID `json:"id"`
+		Name string    `json:"name"`
+	} `json:"projects"`
+	Items []struct {
+		ID        todoistID   `json:"id"`
+		Content   string      `json:"content"`
+		ProjectID todoistID   `json:"project_id"`
+		ParentID  todoistID   `json:"parent_id"`
+		Checked   todoistBool `json:"checked"`
+		IsDeleted todoistBool `json:"is_deleted"`
+	} `json:"items"`
+}
+
+// todoistID is a string in the current Sync API and a number in older
+// backups.
+type todoistID string
+
+func (id *todoistID) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*id = ""
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*id = todoistID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*id = todoistID(n.String())
+	return nil
+}
+
+// todoistBool is a boolean in the current Sync API and 0 or 1 in older
+// backups.
+type todoistBool bool
+
+func (v *todoistBool) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case "true", "1":
+		*v = true
+	case "false", "0", "null":
+		*v = false
+	default:
+		return fmt.Errorf("%s is not a boolean", b)
+	}
+	return nil
+}
+
+// ReadTodoist reads the items of a Todoist Sync API backup. Every item
+// becomes a todo in the list named after its project. Sections and labels
+// have no counterpart and are dropped; a sub-item is named "parent: item".
+// Checked items are done and deleted ones are skipped. UIDs are "todoist:"
+// and the item ID.
+func ReadTodoist(r io.Reader) ([]Record, error) {
+	var backup todoistBackup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, readError(err)
+	}
+	if backup.Items == nil {
+		return nil, invalidFile("the file is not a Todoist backup")
+	}
+	projects := map[todoistID]string{}
+	for _, p := range backup.Projects {
+		projects[p.ID] = p.Name
+	}
+	contents := map[todoistID]string{}
+	for _, item := range backup.Items {
+		contents[item.ID] = item.Content
+	}
+
+	var records []Record
+	for i, item := range backup.Items {
+		if item.IsDeleted {
+			continue
+		}
+		if item.ID == "" {
+			return nil, invalidFile("item %d has no id", i+1)
+		}
+		rec := Record{Line: i + 1, Task: item.Content, Status: model.Created, UID: "todoist:" + string(item.ID), List: projects[item.ProjectID]}
+		if parent, ok := contents[item.ParentID]; ok && item.ParentID != "" {
+			rec.Task = parent + ": " + item.Content
+		}
+		if item.Checked {
+			rec.Status = model.Done
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
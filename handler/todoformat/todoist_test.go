@@ -0,0 +1,65 @@
+package todoformat_test
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadTodoist(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected []todoformat.Record
+		err      error
+	}{
+		{
+			name: "正常系_アイテムがタスクになり子アイテムに親の名前が付くこと",
+			input: `{"projects": [{"id": "p1", "name": "Inbox"}], "labels": [{"name": "home"}],
+				"items": [
+					{"id": "1", "content": "parent", "project_id": "p1", "parent_id": null, "checked": false, "is_deleted": false, "labels": ["home"]},
+					{"id": "2", "content": "child", "project_id": "p1", "parent_id": "1", "checked": true},
+					{"id": "3", "content": "deleted", "is_deleted": true}
+				]}`,
+			expected: []todoformat.Record{
+				{Line: 1, Task: "parent", Status: model.Created, UID: "todoist:1", List: "Inbox"},
+				{Line: 2, Task: "parent: child", Status: model.Done, UID: "todoist:2", List: "Inbox"},
+			},
+		},
+		{
+			name:  "正常系_数値のIDと0か1のフラグが読み込めること",
+			input: `{"items": [{"id": 10, "content": "old", "checked": 1, "is_deleted": 0}]}`,
+			expected: []todoformat.Record{
+				{Line: 1, Task: "old", Status: model.Done, UID: "todoist:10"},
+			},
+		},
+		{
+			name:  "異常系_バックアップでない場合エラーになること",
+			input: `{"cards": []}`,
+			err:   todoformat.ErrInvalidFile,
+		},
+		{
+			name:  "異常系_IDがない場合エラーになること",
+			input: `{"items": [{"content": "task"}]}`,
+			err:   todoformat.ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := todoformat.ReadTodoist(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package todoformat
+
+import (
+	"app/domain/model"
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// todo.txt has no state between open and done, so other statuses are kept
+// in a key:value tag as the format suggests for extensions.
+const todoTxtStatusKey = "status:"
+
+const todoTxtDate = "2006-01-02"
+
+var (
+	todoTxtPriority = regexp.MustCompile(`^\([A-Z]\) `)
+	todoTxtDateTag  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `)
+)
+
+type todoTxtEncoder struct {
+	w io.Writer
+}
+
+// NewTodoTxtEncoder writes a todo per line in the todo.txt format. Done
+// todos are marked with "x" and the time of their last update as the
+// completion date; the creation date follows the priority, which the task
+// keeps in its text. Line breaks in tasks are written as spaces.
+func NewTodoTxtEncoder(w io.Writer) Encoder {
+	return &todoTxtEncoder{w}
+}
+
+func (e *todoTxtEncoder) Encode(todos ...*model.Todo) error {
+	var b strings.Builder
+	for _, todo := range todos {
+		task := oneLine(todo.Task)
+		if todo.Status == model.Done {
+			b.WriteString("x ")
+			if !todo.UpdatedAt.IsZero() {
+				b.WriteString(todo.UpdatedAt.UTC().Format(todoTxtDate) + " ")
+			}
+		} else if priority := todoTxtPriority.FindString(task); priority != "" {
+			b.WriteString(priority)
+			task = task[len(priority):]
+		}
+		if !todo.CreatedAt.IsZero() {
+			b.WriteString(todo.CreatedAt.UTC().Format(todoTxtDate) + " ")
+		}
+		b.WriteString(task)
+		if todo.Status != model.Done && todo.Status != model.Created {
+			b.WriteString(" " + todoTxtStatusKey + string(todo.Status))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *todoTxtEncoder) Close() error {
+	return nil
+}
+
+// ReadTodoTxt reads a todo per non-empty line. Completion and creation dates
+// are skipped since they are set by the server; priorities, contexts and
+// projects stay in the task text.
+func ReadTodoTxt(r io.Reader) ([]Record, error) {
+	var records []Record
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+		rec := Record{Line: line}
+		priority := ""
+		if strings.HasPrefix(text, "x ") {
+			rec.Status = model.Done
+			text = todoTxtDateTag.ReplaceAllString(text[len("x "):], "")
+		} else if priority = todoTxtPriority.FindString(text); priority != "" {
+			text = text[len(priority):]
+		}
+		text = todoTxtDateTag.ReplaceAllString(text, "")
+
+		words := strings.Split(text, " ")
+		kept := words[:0]
+		for _, w := range words {
+			if v, ok := strings.CutPrefix(w, todoTxtStatusKey); ok && rec.Status == "" {
+				rec.Status = model.TaskStatus(v)
+				continue
+			}
+			kept = append(kept, w)
+		}
+		rec.Task = priority + strings.Join(kept, " ")
+		records = append(records, rec)
+	}
+	if err := s.Err(); err != nil {
+		return nil, readError(err)
+	}
+	return records, nil
+}
+
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// oneLine replaces line breaks, which line based formats cannot hold.
+func oneLine(s string) string {
+	return lineBreaks.Replace(s)
+}
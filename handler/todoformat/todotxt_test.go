@@ -0,0 +1,101 @@
+package todoformat_test
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// roundTripTodos は対応する項目（タスクとステータス）を網羅するタスク
+func roundTripTodos() []*model.Todo {
+	created := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	updated := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+	return []*model.Todo{
+		{Task: "write docs", Status: model.Created, CreatedAt: created, UpdatedAt: updated},
+		{Task: "(A) call mom +family @phone", Status: model.Processing, CreatedAt: created, UpdatedAt: updated},
+		{Task: "(B) review  due:2023-02-01", Status: model.Done, CreatedAt: created, UpdatedAt: updated},
+		{Task: "2023 plans", Status: model.Done},
+		{Task: "[x] not a checkbox", Status: model.Created},
+	}
+}
+
+// records は往復後に保たれるべき内容
+func records(todos []*model.Todo) []todoformat.Record {
+	res := make([]todoformat.Record, len(todos))
+	for i, todo := range todos {
+		res[i] = todoformat.Record{Task: todo.Task, Status: todo.Status}
+	}
+	return res
+}
+
+// withoutLines は比較のため行番号を取り除く
+func withoutLines(recs []todoformat.Record) []todoformat.Record {
+	for i := range recs {
+		recs[i].Line = 0
+	}
+	return recs
+}
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	enc := todoformat.NewTodoTxtEncoder(&buf)
+	if err := enc.Encode(roundTripTodos()...); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+
+	got, err := todoformat.ReadTodoTxt(&buf)
+	if err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	// 作成済みのタスクのステータスは省略されるため作成済みとして比較する
+	want := records(roundTripTodos())
+	for i := range want {
+		if want[i].Status == model.Created {
+			want[i].Status = ""
+		}
+	}
+	if !cmp.Equal(withoutLines(got), want) {
+		t.Errorf("diff %s", cmp.Diff(withoutLines(got), want))
+	}
+}
+
+func TestTodoTxtEncoder(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := todoformat.NewTodoTxtEncoder(&buf).Encode(roundTripTodos()[:3]...); err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	expected := "2023-01-01 write docs\n" +
+		"(A) 2023-01-01 call mom +family @phone status:processing\n" +
+		"x 2023-01-02 2023-01-01 (B) review  due:2023-02-01\n"
+	if buf.String() != expected {
+		t.Errorf("diff %s", cmp.Diff(buf.String(), expected))
+	}
+}
+
+func TestReadTodoTxt(t *testing.T) {
+	t.Parallel()
+	input := "x 2023-01-02 2023-01-01 done task\n\n(C) 2023-01-01 open task @home\r\nline\nbad status:xxxx\n"
+	expected := []todoformat.Record{
+		{Line: 1, Task: "done task", Status: model.Done},
+		{Line: 3, Task: "(C) open task @home"},
+		{Line: 4, Task: "line"},
+		{Line: 5, Task: "bad", Status: "xxxx"},
+	}
+	got, err := todoformat.ReadTodoTxt(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("want = %v, got = %v", nil, err)
+	}
+	if !cmp.Equal(got, expected) {
+		t.Errorf("diff %s", cmp.Diff(got, expected))
+	}
+}
@@ -0,0 +1,72 @@
+package todoformat
+
+import (
+	"app/domain/model"
+	"encoding/json"
+	"io"
+)
+
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Closed      bool   `json:"closed"`
+		DueComplete bool   `json:"dueComplete"`
+		IDList      string `json:"idList"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string `json:"idCard"`
+		CheckItems []struct {
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// ReadTrello reads the JSON export of a Trello board. Every card becomes a
+// todo in the list named after the board. There are no labels or subtasks,
+// so labels are dropped and a card is followed by the items of its
+// checklists as todos named "card: item".
+// Archived cards, cards of archived lists and cards whose due date is marked
+// complete are done. UIDs are "trello:" and the ID of the card or item.
+func ReadTrello(r io.Reader) ([]Record, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, readError(err)
+	}
+	if board.Cards == nil {
+		return nil, invalidFile("the file is not a Trello board export")
+	}
+	closed := map[string]bool{}
+	for _, l := range board.Lists {
+		closed[l.ID] = l.Closed
+	}
+
+	var records []Record
+	for _, card := range board.Cards {
+		rec := Record{Line: len(records) + 1, Task: card.Name, Status: model.Created, UID: "trello:" + card.ID, List: board.Name}
+		if card.Closed || card.DueComplete || closed[card.IDList] {
+			rec.Status = model.Done
+		}
+		records = append(records, rec)
+		for _, checklist := range board.Checklists {
+			if checklist.IDCard != card.ID {
+				continue
+			}
+			for _, item := range checklist.CheckItems {
+				rec := Record{Line: len(records) + 1, Task: card.Name + ": " + item.Name, Status: model.Created, UID: "trello:" + item.ID, List: board.Name}
+				if item.State == "complete" {
+					rec.Status = model.Done
+				}
+				records = append(records, rec)
+			}
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,70 @@
+package todoformat_test
+
+import (
+	"app/domain/model"
+	"app/handler/todoformat"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadTrello(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected []todoformat.Record
+		err      error
+	}{
+		{
+			name: "正常系_カードとチェックリストの項目がタスクになること",
+			input: `{"name": "board", "labels": [{"name": "bug"}],
+				"lists": [{"id": "l1", "closed": false}, {"id": "l2", "closed": true}],
+				"cards": [
+					{"id": "c1", "name": "open", "idList": "l1", "closed": false, "labels": [{"name": "bug"}]},
+					{"id": "c2", "name": "archived", "idList": "l1", "closed": true},
+					{"id": "c3", "name": "in archived list", "idList": "l2"},
+					{"id": "c4", "name": "due complete", "idList": "l1", "dueComplete": true}
+				],
+				"checklists": [
+					{"id": "cl1", "idCard": "c1", "checkItems": [
+						{"id": "i1", "name": "step1", "state": "complete"},
+						{"id": "i2", "name": "step2", "state": "incomplete"}
+					]}
+				]}`,
+			expected: []todoformat.Record{
+				{Line: 1, Task: "open", Status: model.Created, UID: "trello:c1", List: "board"},
+				{Line: 2, Task: "open: step1", Status: model.Done, UID: "trello:i1", List: "board"},
+				{Line: 3, Task: "open: step2", Status: model.Created, UID: "trello:i2", List: "board"},
+				{Line: 4, Task: "archived", Status: model.Done, UID: "trello:c2", List: "board"},
+				{Line: 5, Task: "in archived list", Status: model.Done, UID: "trello:c3", List: "board"},
+				{Line: 6, Task: "due complete", Status: model.Done, UID: "trello:c4", List: "board"},
+			},
+		},
+		{
+			name:  "異常系_ボードのエクスポートでない場合エラーになること",
+			input: `{"items": []}`,
+			err:   todoformat.ErrInvalidFile,
+		},
+		{
+			name:  "異常系_JSONでない場合エラーになること",
+			input: `task1`,
+			err:   todoformat.ErrInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := todoformat.ReadTrello(strings.NewReader(tt.input))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+		})
+	}
+}
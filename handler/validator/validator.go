@@ -2,6 +2,7 @@ package validator
 
 import (
 	"app/domain/model"
+	"net/url"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -12,9 +13,34 @@ func SetupValidator() error {
 		if err := v.RegisterValidation("task_status", ValidateTaskStatus); err != nil {
 			return err
 		}
+		if err := v.RegisterValidation("role", ValidateRole); err != nil {
+			return err
+		}
+		if err := v.RegisterValidation("webhook_event", ValidateWebhookEvent); err != nil {
+			return err
+		}
+		if err := v.RegisterValidation("webhook_url", ValidateWebhookURL); err != nil {
+			return err
+		}
+		if err := v.RegisterValidation("todo_action", ValidateTodoAction); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 func ValidateTaskStatus(fl validator.FieldLevel) bool {
 	return model.TaskStatusMap[model.TaskStatus(fl.Field().String())]
 }
+func ValidateRole(fl validator.FieldLevel) bool {
+	return model.RoleMap[model.Role(fl.Field().String())]
+}
+func ValidateWebhookEvent(fl validator.FieldLevel) bool {
+	return model.WebhookEventMap[model.WebhookEvent(fl.Field().String())]
+}
+func ValidateTodoAction(fl validator.FieldLevel) bool {
+	return model.TodoActionMap[model.TodoAction(fl.Field().String())]
+}
+func ValidateWebhookURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"app/domain/model"
+	"app/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Webhook interface {
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	Find(c *gin.Context)
+	FindAll(c *gin.Context)
+	FindDeliveries(c *gin.Context)
+	SendTest(c *gin.Context)
+}
+
+type webhookHandler struct {
+	usecase usecase.Webhook
+}
+
+func NewWebhook(u usecase.Webhook) Webhook {
+	return &webhookHandler{u}
+}
+
+type CreateWebhookRequestBodyParam struct {
+	URL    string               `json:"url" binding:"required,max=2048,webhook_url"`
+	Events []model.WebhookEvent `json:"events" binding:"required,min=1,dive,webhook_event"`
+	Secret string               `json:"secret" binding:"omitempty,min=16,max=255"`
+}
+
+// CreateWebhookResponse is the only response that carries the signing secret.
+type CreateWebhookResponse struct {
+	model.Webhook
+	Secret string
+}
+
+func (w *webhookHandler) Create(c *gin.Context) {
+	var req CreateWebhookRequestBodyParam
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := w.usecase.Create(c.Request.Context(), actor, req.URL, req.Events, req.Secret)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: *res, Secret: res.Secret})
+}
+
+type WebhookRequestPathParam struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+type UpdateWebhookRequestBodyParam struct {
+	URL    string               `json:"url" binding:"required,max=2048,webhook_url"`
+	Events []model.WebhookEvent `json:"events" binding:"required,min=1,dive,webhook_event"`
+	Active *bool                `json:"active" binding:"required"`
+}
+
+func (w *webhookHandler) Update(c *gin.Context) {
+	var pathParam WebhookRequestPathParam
+	var bodyParam UpdateWebhookRequestBodyParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&bodyParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := w.usecase.Update(c.Request.Context(), actor, pathParam.ID, bodyParam.URL, bodyParam.Events, *bodyParam.Active); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (w *webhookHandler) Delete(c *gin.Context) {
+	var req WebhookRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	if err := w.usecase.Delete(c.Request.Context(), actor, req.ID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (w *webhookHandler) Find(c *gin.Context) {
+	var req WebhookRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := w.usecase.Find(c.Request.Context(), actor, req.ID)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (w *webhookHandler) FindAll(c *gin.Context) {
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := w.usecase.FindAll(c.Request.Context(), actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+type DeliveriesRequestQueryParam struct {
+	Limit  int `form:"limit,default=50" binding:"min=1,max=500"`
+	Offset int `form:"offset" binding:"min=0"`
+}
+
+func (w *webhookHandler) FindDeliveries(c *gin.Context) {
+	var pathParam WebhookRequestPathParam
+	var queryParam DeliveriesRequestQueryParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&queryParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := w.usecase.FindDeliveries(c.Request.Context(), actor, pathParam.ID, queryParam.Limit, queryParam.Offset)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (w *webhookHandler) SendTest(c *gin.Context) {
+	var req WebhookRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor, ok := bindActor(c)
+	if !ok {
+		return
+	}
+	res, err := w.usecase.SendTest(c.Request.Context(), actor, req.ID)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, res)
+}
@@ -0,0 +1,172 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockWebhook struct {
+	usecase.Webhook
+	mockCreate   func() (*model.Webhook, error)
+	mockSendTest func() (*model.WebhookDelivery, error)
+}
+
+func (m *mockWebhook) Create(ctx context.Context, actor model.Actor, url string, events model.WebhookEvents, secret string) (*model.Webhook, error) {
+	return m.mockCreate()
+}
+func (m *mockWebhook) SendTest(ctx context.Context, actor model.Actor, id int) (*model.WebhookDelivery, error) {
+	return m.mockSendTest()
+}
+
+func TestWebhookCreate(t *testing.T) {
+	t.Parallel()
+
+	created := func() (*model.Webhook, error) {
+		return model.NewWebhook(1, "https://example.com/hook", model.WebhookEvents{model.TodoCreatedEvent}, "generated-secret"), nil
+	}
+	tests := []struct {
+		name             string
+		request          handler.CreateWebhookRequestBodyParam
+		usecase          usecase.Webhook
+		want_status_code int
+		want_secret      bool
+	}{
+		{
+			name: "正常系_Webhookの登録ができシークレットが返されること",
+			request: handler.CreateWebhookRequestBodyParam{
+				URL:    "https://example.com/hook",
+				Events: []model.WebhookEvent{model.TodoCreatedEvent},
+			},
+			usecase:          &mockWebhook{mockCreate: created},
+			want_status_code: http.StatusCreated,
+			want_secret:      true,
+		},
+		{
+			name: "異常系_http以外のURLの場合バリデーションエラーになること",
+			request: handler.CreateWebhookRequestBodyParam{
+				URL:    "ftp://example.com/hook",
+				Events: []model.WebhookEvent{model.TodoCreatedEvent},
+			},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_不明なイベント種別の場合バリデーションエラーになること",
+			request: handler.CreateWebhookRequestBodyParam{
+				URL:    "https://example.com/hook",
+				Events: []model.WebhookEvent{"todo.archived"},
+			},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_イベント種別がない場合バリデーションエラーになること",
+			request: handler.CreateWebhookRequestBodyParam{
+				URL: "https://example.com/hook",
+			},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name: "異常系_権限がない場合",
+			request: handler.CreateWebhookRequestBodyParam{
+				URL:    "https://example.com/hook",
+				Events: []model.WebhookEvent{model.TodoCreatedEvent},
+			},
+			usecase: &mockWebhook{
+				mockCreate: func() (*model.Webhook, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewWebhook(tt.usecase)
+			reqJSON, _ := json.Marshal(tt.request)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/", h.Create)
+			req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			var res map[string]interface{}
+			_ = json.Unmarshal(rec.Body.Bytes(), &res)
+			if _, ok := res["Secret"]; ok != tt.want_secret {
+				t.Errorf("want secret = %v, got = %v", tt.want_secret, res)
+			}
+		})
+	}
+}
+
+func TestWebhookSendTest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		usecase          usecase.Webhook
+		want_status_code int
+	}{
+		{
+			name: "正常系_テストイベントの送信を受け付けること",
+			usecase: &mockWebhook{
+				mockSendTest: func() (*model.WebhookDelivery, error) {
+					return &model.WebhookDelivery{ID: 1, WebhookID: 1, Event: model.Ping, Status: model.DeliveryPending}, nil
+				},
+			},
+			want_status_code: http.StatusAccepted,
+		},
+		{
+			name: "異常系_Webhookが存在しない場合",
+			usecase: &mockWebhook{
+				mockSendTest: func() (*model.WebhookDelivery, error) {
+					return nil, usecase.ErrNotFound
+				},
+			},
+			want_status_code: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewWebhook(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/:id/test", h.SendTest)
+			req := httptest.NewRequest("POST", "/1/test", nil)
+			setAuthHeader(req)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"app/domain/model"
+	"app/handler/middleware"
+	"app/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Workspace interface {
+	Create(c *gin.Context)
+	FindMembers(c *gin.Context)
+	UpdateMember(c *gin.Context)
+	DeleteMember(c *gin.Context)
+}
+
+type workspaceHandler struct {
+	usecase usecase.Workspace
+}
+
+func NewWorkspace(u usecase.Workspace) Workspace {
+	return &workspaceHandler{u}
+}
+
+type CreateWorkspaceRequestParam struct {
+	Name string `json:"name" binding:"required,max=60"`
+}
+
+func (w *workspaceHandler) Create(c *gin.Context) {
+	var req CreateWorkspaceRequestParam
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := w.usecase.Create(c.Request.Context(), middleware.UserID(c), req.Name)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+type WorkspaceRequestPathParam struct {
+	ID int `uri:"id" binding:"required"`
+}
+
+func (w *workspaceHandler) FindMembers(c *gin.Context) {
+	var req WorkspaceRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, err := w.usecase.FindMembers(c.Request.Context(), model.NewActor(middleware.UserID(c), req.ID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+type MemberRequestPathParam struct {
+	ID     int `uri:"id" binding:"required"`
+	UserID int `uri:"user_id" binding:"required"`
+}
+
+type UpdateMemberRequestBodyParam struct {
+	Role model.Role `json:"role" binding:"required,role"`
+}
+
+func (w *workspaceHandler) UpdateMember(c *gin.Context) {
+	var pathParam MemberRequestPathParam
+	var bodyParam UpdateMemberRequestBodyParam
+
+	if err := c.ShouldBindUri(&pathParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindJSON(&bodyParam); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor := model.NewActor(middleware.UserID(c), pathParam.ID)
+	if err := w.usecase.UpdateMember(c.Request.Context(), actor, pathParam.UserID, bodyParam.Role); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (w *workspaceHandler) DeleteMember(c *gin.Context) {
+	var req MemberRequestPathParam
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	actor := model.NewActor(middleware.UserID(c), req.ID)
+	if err := w.usecase.DeleteMember(c.Request.Context(), actor, req.UserID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
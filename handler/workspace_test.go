@@ -0,0 +1,217 @@
+package handler_test
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockWorkspace struct {
+	usecase.Workspace
+	mockCreate       func() (*model.Workspace, error)
+	mockFindMembers  func() ([]*model.Member, error)
+	mockUpdateMember func() error
+	mockDeleteMember func() error
+}
+
+func (m *mockWorkspace) Create(ctx context.Context, userID int, name string) (*model.Workspace, error) {
+	return m.mockCreate()
+}
+func (m *mockWorkspace) FindMembers(ctx context.Context, actor model.Actor) ([]*model.Member, error) {
+	return m.mockFindMembers()
+}
+func (m *mockWorkspace) UpdateMember(ctx context.Context, actor model.Actor, userID int, role model.Role) error {
+	return m.mockUpdateMember()
+}
+func (m *mockWorkspace) DeleteMember(ctx context.Context, actor model.Actor, userID int) error {
+	return m.mockDeleteMember()
+}
+
+func TestWorkspaceCreate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		request          handler.CreateWorkspaceRequestParam
+		usecase          usecase.Workspace
+		want_status_code int
+	}{
+		{
+			name:    "正常系_ワークスペースの登録ができること",
+			request: handler.CreateWorkspaceRequestParam{Name: "workspace"},
+			usecase: &mockWorkspace{
+				mockCreate: func() (*model.Workspace, error) {
+					return &model.Workspace{ID: 1, Name: "workspace"}, nil
+				},
+			},
+			want_status_code: http.StatusCreated,
+		},
+		{
+			name:             "異常系_必須項目がなかった場合バリデーションエラーになること（name）",
+			request:          handler.CreateWorkspaceRequestParam{},
+			want_status_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewWorkspace(tt.usecase)
+			reqJSON, _ := json.Marshal(tt.request)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.POST("/", h.Create)
+			req := httptest.NewRequest("POST", "/", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.UserIDHeader, "1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestWorkspaceUpdateMember(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		request          handler.UpdateMemberRequestBodyParam
+		usecase          usecase.Workspace
+		want_status_code int
+	}{
+		{
+			name:    "正常系_メンバーのロールの更新ができること",
+			request: handler.UpdateMemberRequestBodyParam{Role: model.Editor},
+			usecase: &mockWorkspace{
+				mockUpdateMember: func() error {
+					return nil
+				},
+			},
+			want_status_code: http.StatusNoContent,
+		},
+		{
+			name:             "異常系_ロールに不正な値が指定された場合バリデーションエラーになること（role）",
+			request:          handler.UpdateMemberRequestBodyParam{Role: "guest"},
+			want_status_code: http.StatusBadRequest,
+		},
+		{
+			name:    "異常系_権限がなかった場合403エラーになること",
+			request: handler.UpdateMemberRequestBodyParam{Role: model.Editor},
+			usecase: &mockWorkspace{
+				mockUpdateMember: func() error {
+					return usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+		{
+			name:    "異常系_最後のオーナーを降格しようとした場合409エラーになること",
+			request: handler.UpdateMemberRequestBodyParam{Role: model.Editor},
+			usecase: &mockWorkspace{
+				mockUpdateMember: func() error {
+					return usecase.ErrConflict
+				},
+			},
+			want_status_code: http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewWorkspace(tt.usecase)
+			reqJSON, _ := json.Marshal(tt.request)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+			validator.SetupValidator()
+
+			r.PUT("/:id/members/:user_id", h.UpdateMember)
+			req := httptest.NewRequest("PUT", "/1/members/2", bytes.NewBuffer(reqJSON))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.UserIDHeader, "1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+		})
+	}
+}
+
+func TestWorkspaceFindMembers(t *testing.T) {
+	t.Parallel()
+
+	expected := []*model.Member{model.NewMember(1, 1, model.Owner)}
+	tests := []struct {
+		name             string
+		usecase          usecase.Workspace
+		want_status_code int
+	}{
+		{
+			name: "正常系_メンバー一覧の検索ができること",
+			usecase: &mockWorkspace{
+				mockFindMembers: func() ([]*model.Member, error) {
+					return expected, nil
+				},
+			},
+			want_status_code: http.StatusOK,
+		},
+		{
+			name: "異常系_メンバーでない場合403エラーになること",
+			usecase: &mockWorkspace{
+				mockFindMembers: func() ([]*model.Member, error) {
+					return nil, usecase.ErrForbidden
+				},
+			},
+			want_status_code: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := handler.NewWorkspace(tt.usecase)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(middleware.Authenticate())
+
+			r.GET("/:id/members", h.FindMembers)
+			req := httptest.NewRequest("GET", "/1/members", nil)
+			req.Header.Set(middleware.UserIDHeader, "1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if tt.want_status_code != rec.Code {
+				t.Errorf("want = %v, got = %v", tt.want_status_code, rec.Code)
+			}
+			if rec.Code == http.StatusOK {
+				wr, _ := json.Marshal(expected)
+				if string(wr) != rec.Body.String() {
+					t.Errorf("want = %v, got = %v", string(wr), rec.Body.String())
+				}
+			}
+		})
+	}
+}
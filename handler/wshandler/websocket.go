@@ -0,0 +1,334 @@
+package wshandler
+
+import (
+	"app/domain/model"
+	"app/handler"
+	"app/handler/middleware"
+	"app/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxMessageSize = 4096
+	sendBuffer     = 64
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+)
+
+type WebSocket interface {
+	Serve(c *gin.Context)
+	// Shutdown closes every open connection with a going-away close frame and
+	// rejects new upgrades.
+	Shutdown()
+}
+
+type wsHandler struct {
+	usecase  usecase.Todo
+	upgrader websocket.Upgrader
+
+	mu     sync.Mutex
+	conns  map[*conn]struct{}
+	closed bool
+}
+
+func NewWebSocket(u usecase.Todo) WebSocket {
+	return &wsHandler{
+		usecase: u,
+		conns:   map[*conn]struct{}{},
+	}
+}
+
+// Message is sent by the client. ID is echoed back in the ack or error reply.
+// ListID selects the list to subscribe to (all of the workspace when 0), to
+// create a todo in, or to move a todo into.
+type Message struct {
+	ID          string           `json:"id"`
+	Type        string           `json:"type" binding:"required,oneof=subscribe unsubscribe create update move delete"`
+	WorkspaceID int              `json:"workspace_id" binding:"required,min=1"`
+	ListID      int              `json:"list_id,omitempty" binding:"min=0"`
+	TodoID      int              `json:"todo_id,omitempty" binding:"required_if=Type update,required_if=Type move,required_if=Type delete"`
+	Task        string           `json:"task,omitempty"`
+	Status      model.TaskStatus `json:"status,omitempty"`
+}
+
+// Reply is sent by the server: an ack or error for a client message, or a
+// change event of a subscribed workspace or list.
+type Reply struct {
+	Type        string                `json:"type"`
+	ID          string                `json:"id,omitempty"`
+	Code        int                   `json:"code,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	Event       usecase.TodoEventType `json:"event,omitempty"`
+	EventID     uint64                `json:"event_id,omitempty"`
+	WorkspaceID int                   `json:"workspace_id,omitempty"`
+	ListID      int                   `json:"list_id,omitempty"`
+	Todo        *model.Todo           `json:"todo,omitempty"`
+}
+
+func (h *wsHandler) Serve(c *gin.Context) {
+	h.mu.Lock()
+	closed := h.closed
+	h.mu.Unlock()
+	if closed {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "server is shutting down"})
+		return
+	}
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written the error response.
+		return
+	}
+	cn := &conn{
+		ws:      ws,
+		userID:  middleware.UserID(c),
+		usecase: h.usecase,
+		send:    make(chan Reply, sendBuffer),
+		done:    make(chan struct{}),
+		subs:    map[subscription]func(){},
+	}
+	if !h.register(cn) {
+		cn.close(websocket.CloseGoingAway, "server is shutting down")
+		return
+	}
+	defer h.unregister(cn)
+
+	go cn.writeLoop()
+	cn.readLoop(c.Request.Context())
+}
+
+func (h *wsHandler) Shutdown() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*conn, 0, len(h.conns))
+	for cn := range h.conns {
+		conns = append(conns, cn)
+	}
+	h.mu.Unlock()
+	for _, cn := range conns {
+		cn.close(websocket.CloseGoingAway, "server is shutting down")
+	}
+}
+
+func (h *wsHandler) register(cn *conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns[cn] = struct{}{}
+	return true
+}
+
+func (h *wsHandler) unregister(cn *conn) {
+	h.mu.Lock()
+	delete(h.conns, cn)
+	h.mu.Unlock()
+	cn.unsubscribeAll()
+	cn.close(websocket.CloseNormalClosure, "")
+}
+
+type conn struct {
+	ws      *websocket.Conn
+	userID  int
+	usecase usecase.Todo
+	send    chan Reply
+
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu   sync.Mutex
+	subs map[subscription]func()
+}
+
+// subscription is a workspace, or one list of it when listID is not 0.
+type subscription struct {
+	workspaceID int
+	listID      int
+}
+
+func (s subscription) matches(e usecase.TodoEvent) bool {
+	return s.listID == 0 || e.Todo.ListID == s.listID || e.FromListID == s.listID
+}
+
+// readLoop handles the messages in the context of the upgrade request, which
+// lasts as long as the connection.
+func (c *conn) readLoop(ctx context.Context) {
+	c.ws.SetReadLimit(maxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.push(Reply{Type: "error", Code: http.StatusBadRequest, Error: err.Error()})
+			continue
+		}
+		if err := binding.Validator.ValidateStruct(&msg); err != nil {
+			c.push(Reply{Type: "error", ID: msg.ID, Code: http.StatusBadRequest, Error: err.Error()})
+			continue
+		}
+		if err := c.handle(ctx, msg); err != nil {
+			c.push(Reply{Type: "error", ID: msg.ID, Code: errorCode(err), Error: err.Error()})
+			continue
+		}
+		c.push(Reply{Type: "ack", ID: msg.ID})
+	}
+}
+
+func (c *conn) handle(ctx context.Context, msg Message) error {
+	actor := model.NewActor(c.userID, msg.WorkspaceID)
+	switch msg.Type {
+	case "subscribe":
+		return c.subscribe(ctx, actor, msg.ListID)
+	case "unsubscribe":
+		c.unsubscribe(subscription{workspaceID: msg.WorkspaceID, listID: msg.ListID})
+		return nil
+	case "create":
+		req := handler.CreateRequestParam{Task: msg.Task, ListID: msg.ListID}
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			return errInvalid{err}
+		}
+		return c.usecase.Create(ctx, actor, req.Task, req.ListID)
+	case "update":
+		req := handler.UpdateRequestBodyParam{Task: msg.Task, Status: msg.Status}
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			return errInvalid{err}
+		}
+		return c.usecase.Update(ctx, actor, msg.TodoID, req.Task, req.Status)
+	case "move":
+		return c.usecase.Move(ctx, actor, msg.TodoID, msg.ListID)
+	default:
+		return c.usecase.Delete(ctx, actor, msg.TodoID)
+	}
+}
+
+func (c *conn) subscribe(ctx context.Context, actor model.Actor, listID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub := subscription{workspaceID: actor.WorkspaceID, listID: listID}
+	if _, ok := c.subs[sub]; ok {
+		return nil
+	}
+	_, events, cancel, err := c.usecase.Subscribe(ctx, actor, 0)
+	if err != nil {
+		return err
+	}
+	c.subs[sub] = cancel
+	go c.forward(sub, events)
+	return nil
+}
+
+func (c *conn) forward(sub subscription, events <-chan usecase.TodoEvent) {
+	for e := range events {
+		if !sub.matches(e) {
+			continue
+		}
+		todo := e.Todo
+		c.push(Reply{Type: "event", Event: e.Type, EventID: e.ID, WorkspaceID: sub.workspaceID, ListID: sub.listID, Todo: &todo})
+	}
+	c.mu.Lock()
+	_, subscribed := c.subs[sub]
+	c.mu.Unlock()
+	if subscribed {
+		// The stream dropped the subscription because it could not keep up.
+		c.close(websocket.CloseTryAgainLater, "too slow to receive events")
+	}
+}
+
+func (c *conn) unsubscribe(sub subscription) {
+	c.mu.Lock()
+	cancel, ok := c.subs[sub]
+	delete(c.subs, sub)
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (c *conn) unsubscribeAll() {
+	c.mu.Lock()
+	subs := c.subs
+	c.subs = map[subscription]func(){}
+	c.mu.Unlock()
+	for _, cancel := range subs {
+		cancel()
+	}
+}
+
+// push never blocks: a connection whose send buffer is full is closed
+// instead of stalling the publisher.
+func (c *conn) push(r Reply) {
+	select {
+	case <-c.done:
+	case c.send <- r:
+	default:
+		c.close(websocket.CloseTryAgainLater, "too slow to receive messages")
+	}
+}
+
+func (c *conn) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case r := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.ws.WriteJSON(r); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		if code != websocket.CloseAbnormalClosure {
+			_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(writeWait))
+		}
+		close(c.done)
+		_ = c.ws.Close()
+	})
+}
+
+// errInvalid marks a command whose payload fails the same binding rules as
+// the REST handlers.
+type errInvalid struct{ error }
+
+func errorCode(err error) int {
+	var invalid errInvalid
+	switch {
+	case errors.As(err, &invalid):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,216 @@
+package wshandler_test
+
+import (
+	"app/domain/model"
+	"app/handler/middleware"
+	"app/handler/validator"
+	"app/handler/wshandler"
+	"app/usecase"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// mockTodo はワークスペース1のみ操作でき、登録時に実際のストリームへ配信する
+type mockTodo struct {
+	usecase.Todo
+	stream usecase.TodoStream
+}
+
+func (m *mockTodo) Create(ctx context.Context, actor model.Actor, task string, listID int) error {
+	if actor.WorkspaceID != 1 {
+		return usecase.ErrForbidden
+	}
+	m.stream.Publish(usecase.TodoCreated, model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID, ListID: listID, Task: task, Status: model.Created})
+	return nil
+}
+func (m *mockTodo) Subscribe(ctx context.Context, actor model.Actor, lastEventID uint64) ([]usecase.TodoEvent, <-chan usecase.TodoEvent, func(), error) {
+	if actor.WorkspaceID != 1 {
+		return nil, nil, nil, usecase.ErrForbidden
+	}
+	replay, events, cancel := m.stream.Subscribe(actor.WorkspaceID, lastEventID)
+	return replay, events, cancel, nil
+}
+
+func newServer(t *testing.T) (wshandler.WebSocket, *websocket.Conn) {
+	t.Helper()
+	validator.SetupValidator()
+	h := wshandler.NewWebSocket(&mockTodo{stream: usecase.NewTodoStream(0)})
+	r := gin.New()
+	r.Use(middleware.Authenticate())
+	r.GET("/ws", h.Serve)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	header := http.Header{}
+	header.Set(middleware.UserIDHeader, "1")
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", header)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return h, ws
+}
+
+func send(t *testing.T, ws *websocket.Conn, msg wshandler.Message) wshandler.Reply {
+	t.Helper()
+	if err := ws.WriteJSON(msg); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	return receive(t, ws)
+}
+
+func receive(t *testing.T, ws *websocket.Conn) wshandler.Reply {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var r wshandler.Reply
+	if err := ws.ReadJSON(&r); err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	return r
+}
+
+func TestWebSocket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		msg       wshandler.Message
+		want_type string
+		want_code int
+	}{
+		{
+			name:      "正常系_購読できること",
+			msg:       wshandler.Message{ID: "1", Type: "subscribe", WorkspaceID: 1},
+			want_type: "ack",
+		},
+		{
+			name:      "異常系_権限のないワークスペースは購読できないこと",
+			msg:       wshandler.Message{ID: "1", Type: "subscribe", WorkspaceID: 2},
+			want_type: "error",
+			want_code: http.StatusForbidden,
+		},
+		{
+			name:      "異常系_不明なメッセージ種別の場合バリデーションエラーになること",
+			msg:       wshandler.Message{ID: "1", Type: "unknown", WorkspaceID: 1},
+			want_type: "error",
+			want_code: http.StatusBadRequest,
+		},
+		{
+			name:      "異常系_タスクが60文字を超える場合バリデーションエラーになること",
+			msg:       wshandler.Message{ID: "1", Type: "create", WorkspaceID: 1, Task: strings.Repeat("a", 61)},
+			want_type: "error",
+			want_code: http.StatusBadRequest,
+		},
+		{
+			name:      "異常系_不正なリストIDの場合バリデーションエラーになること",
+			msg:       wshandler.Message{ID: "1", Type: "subscribe", WorkspaceID: 1, ListID: -1},
+			want_type: "error",
+			want_code: http.StatusBadRequest,
+		},
+		{
+			name:      "異常系_移動対象のIDがない場合バリデーションエラーになること",
+			msg:       wshandler.Message{ID: "1", Type: "move", WorkspaceID: 1, ListID: 2},
+			want_type: "error",
+			want_code: http.StatusBadRequest,
+		},
+		{
+			name:      "異常系_更新対象のIDがない場合バリデーションエラーになること",
+			msg:       wshandler.Message{ID: "1", Type: "update", WorkspaceID: 1, Task: "task", Status: model.Done},
+			want_type: "error",
+			want_code: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, ws := newServer(t)
+
+			got := send(t, ws, tt.msg)
+			if got.Type != tt.want_type || got.Code != tt.want_code || got.ID != tt.msg.ID {
+				t.Errorf("want = %v %v, got = %+v", tt.want_type, tt.want_code, got)
+			}
+		})
+	}
+}
+
+func TestWebSocketPushesEvents(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_登録したタスクの変更イベントが配信されること", func(t *testing.T) {
+		_, ws := newServer(t)
+		if got := send(t, ws, wshandler.Message{ID: "1", Type: "subscribe", WorkspaceID: 1}); got.Type != "ack" {
+			t.Fatalf("unexpected reply: %+v", got)
+		}
+		if err := ws.WriteJSON(wshandler.Message{ID: "2", Type: "create", WorkspaceID: 1, Task: "task"}); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+
+		var ack, event bool
+		for i := 0; i < 2; i++ {
+			got := receive(t, ws)
+			switch got.Type {
+			case "ack":
+				ack = got.ID == "2"
+			case "event":
+				event = got.Event == usecase.TodoCreated && got.Todo != nil && got.Todo.Task == "task"
+			}
+		}
+		if !ack || !event {
+			t.Errorf("want ack and event, got ack = %v, event = %v", ack, event)
+		}
+	})
+}
+
+func TestWebSocketPushesListEvents(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_購読したリストの変更イベントだけが配信されること", func(t *testing.T) {
+		_, ws := newServer(t)
+		if got := send(t, ws, wshandler.Message{ID: "1", Type: "subscribe", WorkspaceID: 1, ListID: 2}); got.Type != "ack" {
+			t.Fatalf("unexpected reply: %+v", got)
+		}
+		if got := send(t, ws, wshandler.Message{ID: "2", Type: "create", WorkspaceID: 1, ListID: 3, Task: "other"}); got.Type != "ack" {
+			t.Fatalf("unexpected reply: %+v", got)
+		}
+		if err := ws.WriteJSON(wshandler.Message{ID: "3", Type: "create", WorkspaceID: 1, ListID: 2, Task: "task"}); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+
+		var ack, event bool
+		for i := 0; i < 2; i++ {
+			got := receive(t, ws)
+			switch got.Type {
+			case "ack":
+				ack = got.ID == "3"
+			case "event":
+				event = got.ListID == 2 && got.Todo != nil && got.Todo.Task == "task"
+			}
+		}
+		if !ack || !event {
+			t.Errorf("want ack and event of list 2, got ack = %v, event = %v", ack, event)
+		}
+	})
+}
+
+func TestWebSocketShutdown(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_シャットダウン時にクローズフレームが送られること", func(t *testing.T) {
+		h, ws := newServer(t)
+		if got := send(t, ws, wshandler.Message{ID: "1", Type: "subscribe", WorkspaceID: 1}); got.Type != "ack" {
+			t.Fatalf("unexpected reply: %+v", got)
+		}
+		h.Shutdown()
+
+		_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		_, _, err := ws.ReadMessage()
+		if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+			t.Errorf("want close %v, got = %v", websocket.CloseGoingAway, err)
+		}
+	})
+}
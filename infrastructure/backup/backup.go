@@ -0,0 +1,635 @@
+// Package backup writes the whole database to a portable archive and
+// restores it. An archive is a gzipped tar of a manifest.json followed by a
+// JSON lines file per table, optionally encrypted with a passphrase. Rows
+// are read and written through gorm without SQL of a particular database,
+// and values are stored by their column type, so an archive does not depend
+// on the database it was taken from. Copy moves rows the same way between
+// two databases without an archive.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FormatVersion is the version of the archive layout.
+const FormatVersion = 1
+
+// SchemaVersion is the number of the latest file in migrations/. An
+// archive of an older schema is restored when the columns it holds still
+// exist, since the migrations so far only add tables and columns with
+// defaults; an archive of a newer schema is refused.
+const SchemaVersion = 11
+
+// Tables are all tables of the schema, in the order they are written.
+var Tables = []string{
+	"workspace",
+	"member",
+	"invitation",
+	"list",
+	"todo",
+	"todo_history",
+	"todo_source",
+	"outbox",
+	"webhook",
+	"webhook_delivery",
+	"idempotency_key",
+}
+
+// primaryKeys are the primary keys of the tables whose key is not id.
+var primaryKeys = map[string][]string{
+	"member": {"workspace_id", "user_id"},
+}
+
+// ordered reads the table in the order of its primary key, so that the
+// rows and their checksum do not depend on the backend.
+func ordered(db *gorm.DB, name string) *gorm.DB {
+	key, ok := primaryKeys[name]
+	if !ok {
+		key = []string{"id"}
+	}
+	var order clause.OrderBy
+	for _, c := range key {
+		order.Columns = append(order.Columns, clause.OrderByColumn{Column: clause.Column{Name: c}})
+	}
+	return db.Table(name).Clauses(order)
+}
+
+const (
+	magicPlain     = "TODOBAK1"
+	magicEncrypted = "TODOENC1"
+	manifestName   = "manifest.json"
+	// restoreBatchSize is the number of rows inserted at once.
+	restoreBatchSize = 100
+	// maxRowSize is the longest line of a table file; a response body of
+	// an idempotency key may be a base64 encoded MEDIUMBLOB.
+	maxRowSize = 32 << 20
+)
+
+var (
+	ErrInvalidArchive     = errors.New("invalid archive")
+	ErrPassphraseRequired = errors.New("the archive is encrypted and needs a passphrase")
+	ErrSchemaVersion      = errors.New("the archive does not fit the schema")
+	ErrNotEmpty           = errors.New("the database is not empty")
+)
+
+type ColumnType string
+
+const (
+	ColumnInt    ColumnType = "int"
+	ColumnFloat  ColumnType = "float"
+	ColumnString ColumnType = "string"
+	ColumnBytes  ColumnType = "bytes"
+	ColumnTime   ColumnType = "time"
+	ColumnJSON   ColumnType = "json"
+)
+
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Tables        []Table   `json:"tables"`
+}
+
+// Table describes the file of a table. SHA256 is the checksum of the file.
+type Table struct {
+	Name    string   `json:"name"`
+	Columns []Column `json:"columns"`
+	Rows    int      `json:"rows"`
+	SHA256  string   `json:"sha256"`
+}
+
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// Backup writes every table to w. The tables are read in one transaction so
+// they are consistent with each other, and spooled to temporary files since
+// the manifest with their checksums comes first. The archive is encrypted
+// when a passphrase is given.
+func Backup(db *gorm.DB, w io.Writer, passphrase string) (*Manifest, error) {
+	dir, err := os.MkdirTemp("", "backup")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	m := &Manifest{FormatVersion: FormatVersion, SchemaVersion: SchemaVersion, CreatedAt: time.Now().UTC()}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range Tables {
+			t, err := dumpTable(tx, name, filepath.Join(dir, name+".jsonl"))
+			if err != nil {
+				return fmt.Errorf("table %s: %w", name, err)
+			}
+			m.Tables = append(m.Tables, *t)
+		}
+		return nil
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeArchive(w, passphrase, m, dir); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func dumpTable(tx *gorm.DB, name string, path string) (*Table, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rows, err := ordered(tx, name).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := columnsOf(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Table{Name: name, Columns: columns}
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	for rows.Next() {
+		b, err := encodeRow(rows, t.Columns)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return nil, err
+		}
+		t.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	t.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return t, nil
+}
+
+func columnsOf(rows *sql.Rows) ([]Column, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]Column, 0, len(types))
+	for _, ct := range types {
+		columns = append(columns, Column{Name: ct.Name(), Type: columnType(ct.DatabaseTypeName())})
+	}
+	return columns, nil
+}
+
+// encodeRow scans the current row into a JSON object. The keys are sorted,
+// so the same row is encoded the same way by any backend.
+func encodeRow(rows *sql.Rows, columns []Column) ([]byte, error) {
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	row := make(map[string]any, len(values))
+	for i, c := range columns {
+		v, err := dumpValue(c.Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", c.Name, err)
+		}
+		row[c.Name] = v
+	}
+	return json.Marshal(row)
+}
+
+// columnType maps the type name reported by the driver to the type the
+// values are stored as.
+func columnType(dbType string) ColumnType {
+	t := strings.ToUpper(dbType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return ColumnInt
+	case t == "FLOAT" || t == "DOUBLE" || t == "REAL":
+		return ColumnFloat
+	case strings.Contains(t, "BLOB") || strings.Contains(t, "BINARY") || t == "BYTEA":
+		return ColumnBytes
+	case t == "JSON" || t == "JSONB":
+		return ColumnJSON
+	case strings.Contains(t, "TIME") || t == "DATE":
+		return ColumnTime
+	default:
+		return ColumnString
+	}
+}
+
+// dumpValue converts a scanned value. Drivers return most values as []byte
+// unless they parse them, so both forms are accepted.
+func dumpValue(typ ColumnType, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch typ {
+	case ColumnInt:
+		switch x := v.(type) {
+		case int64:
+			return x, nil
+		case []byte:
+			return strconv.ParseInt(string(x), 10, 64)
+		}
+	case ColumnFloat:
+		switch x := v.(type) {
+		case float64:
+			return x, nil
+		case []byte:
+			return strconv.ParseFloat(string(x), 64)
+		}
+	case ColumnTime:
+		switch x := v.(type) {
+		case time.Time:
+			return x.UTC().Format(time.RFC3339Nano), nil
+		case []byte:
+			t, err := time.Parse("2006-01-02 15:04:05.999999", string(x))
+			if err != nil {
+				return nil, err
+			}
+			return t.Format(time.RFC3339Nano), nil
+		}
+	case ColumnBytes:
+		switch x := v.(type) {
+		case []byte:
+			return x, nil
+		case string:
+			return []byte(x), nil
+		}
+	case ColumnJSON:
+		switch x := v.(type) {
+		case []byte:
+			if json.Valid(x) {
+				return json.RawMessage(x), nil
+			}
+		case string:
+			if json.Valid([]byte(x)) {
+				return json.RawMessage(x), nil
+			}
+		}
+	default:
+		switch x := v.(type) {
+		case []byte:
+			return string(x), nil
+		case string:
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %T for a %s column", v, typ)
+}
+
+func writeArchive(w io.Writer, passphrase string, m *Manifest, dir string) error {
+	out := w
+	var enc io.WriteCloser
+	if passphrase == "" {
+		if _, err := io.WriteString(w, magicPlain); err != nil {
+			return err
+		}
+	} else {
+		if _, err := io.WriteString(w, magicEncrypted); err != nil {
+			return err
+		}
+		var err error
+		if enc, err = newEncryptWriter(w, passphrase); err != nil {
+			return err
+		}
+		out = enc
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0600, Size: int64(len(manifest)), ModTime: m.CreatedAt}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return err
+	}
+	for _, t := range m.Tables {
+		if err := writeFile(tw, filepath.Join(dir, t.Name+".jsonl"), m.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if enc != nil {
+		return enc.Close()
+	}
+	return nil
+}
+
+func writeFile(tw *tar.Writer, path string, modTime time.Time) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: filepath.Base(path), Mode: 0600, Size: info.Size(), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// Restore loads an archive in one transaction, so a restore that fails
+// leaves the database as it was. The tables must be empty unless replace is
+// set, which deletes their rows first. Every table file is checked against
+// the row count and checksum of the manifest.
+func Restore(db *gorm.DB, r io.Reader, passphrase string, replace bool) (*Manifest, error) {
+	magic := make([]byte, len(magicPlain))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	in := r
+	switch string(magic) {
+	case magicPlain:
+	case magicEncrypted:
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		var err error
+		if in, err = newDecryptReader(r, passphrase); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown file type", ErrInvalidArchive)
+	}
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, archiveError(err)
+	}
+	tr := tar.NewReader(gz)
+
+	m, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string]Table{}
+	for _, t := range m.Tables {
+		tables[t.Name] = t
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if m.SchemaVersion < SchemaVersion {
+			if err := checkColumns(tx, m); err != nil {
+				return err
+			}
+		}
+		if err := prepare(tx, replace); err != nil {
+			return err
+		}
+		restored := map[string]bool{}
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return archiveError(err)
+			}
+			name := strings.TrimSuffix(hdr.Name, ".jsonl")
+			t, ok := tables[name]
+			if !ok || restored[name] {
+				return fmt.Errorf("%w: unexpected file %s", ErrInvalidArchive, hdr.Name)
+			}
+			restored[name] = true
+			if err := restoreTable(tx, t, tr); err != nil {
+				return err
+			}
+		}
+		if len(restored) != len(tables) {
+			return fmt.Errorf("%w: some tables of the manifest are missing", ErrInvalidArchive)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// archiveError keeps ErrWrongPassphrase, which surfaces while the
+// decompressor reads, and marks other read errors as ErrInvalidArchive.
+func archiveError(err error) error {
+	if errors.Is(err, ErrWrongPassphrase) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+}
+
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, archiveError(err)
+	}
+	if hdr.Name != manifestName {
+		return nil, fmt.Errorf("%w: the archive does not start with %s", ErrInvalidArchive, manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, archiveError(err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("%w: format version %d is not supported", ErrInvalidArchive, m.FormatVersion)
+	}
+	if m.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%w: schema version %d is newer than %d", ErrSchemaVersion, m.SchemaVersion, SchemaVersion)
+	}
+	known := map[string]bool{}
+	for _, name := range Tables {
+		known[name] = true
+	}
+	for _, t := range m.Tables {
+		if !known[t.Name] {
+			return nil, fmt.Errorf("%w: unknown table %s", ErrSchemaVersion, t.Name)
+		}
+	}
+	return &m, nil
+}
+
+// checkColumns makes sure that the columns of an archive of an older
+// schema still exist; the columns added since then take their defaults.
+func checkColumns(tx *gorm.DB, m *Manifest) error {
+	for _, t := range m.Tables {
+		columns, err := columnNames(tx, t.Name)
+		if err != nil {
+			return err
+		}
+		exists := map[string]bool{}
+		for _, name := range columns {
+			exists[name] = true
+		}
+		for _, c := range t.Columns {
+			if !exists[c.Name] {
+				return fmt.Errorf("%w: column %s.%s of schema version %d no longer exists", ErrSchemaVersion, t.Name, c.Name, m.SchemaVersion)
+			}
+		}
+	}
+	return nil
+}
+
+func columnNames(tx *gorm.DB, table string) ([]string, error) {
+	rows, err := tx.Table(table).Limit(1).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.Columns()
+}
+
+func prepare(tx *gorm.DB, replace bool) error {
+	for _, name := range Tables {
+		if replace {
+			if err := tx.Exec("DELETE FROM ?", clause.Table{Name: name}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		var n int64
+		if err := tx.Table(name).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("%w: table %s has %d rows", ErrNotEmpty, name, n)
+		}
+	}
+	return nil
+}
+
+func restoreTable(tx *gorm.DB, t Table, r io.Reader) error {
+	types := map[string]ColumnType{}
+	for _, c := range t.Columns {
+		types[c.Name] = c.Type
+	}
+	h := sha256.New()
+	s := bufio.NewScanner(io.TeeReader(r, h))
+	s.Buffer(make([]byte, 64<<10), maxRowSize)
+	batch := make([]map[string]any, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(t.Name).Create(&batch).Error; err != nil {
+			return fmt.Errorf("table %s: %w", t.Name, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	count := 0
+	for s.Scan() {
+		count++
+		row, err := decodeRow(s.Bytes(), types)
+		if err != nil {
+			return fmt.Errorf("%w: row %d of table %s: %w", ErrInvalidArchive, count, t.Name, err)
+		}
+		batch = append(batch, row)
+		if len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return archiveError(err)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if count != t.Rows || hex.EncodeToString(h.Sum(nil)) != t.SHA256 {
+		return fmt.Errorf("%w: table %s does not match its checksum", ErrInvalidArchive, t.Name)
+	}
+	return nil
+}
+
+// decodeRow turns a row encoded by encodeRow into the values to insert.
+func decodeRow(b []byte, types map[string]ColumnType) (map[string]any, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	row := make(map[string]any, len(raw))
+	for name, v := range raw {
+		typ, ok := types[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+		value, err := restoreValue(typ, v)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", name, err)
+		}
+		row[name] = value
+	}
+	return row, nil
+}
+
+func restoreValue(typ ColumnType, raw json.RawMessage) (any, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+	switch typ {
+	case ColumnInt:
+		var n int64
+		err := json.Unmarshal(raw, &n)
+		return n, err
+	case ColumnFloat:
+		var f float64
+		err := json.Unmarshal(raw, &f)
+		return f, err
+	case ColumnTime:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case ColumnBytes:
+		var b []byte
+		err := json.Unmarshal(raw, &b)
+		return b, err
+	case ColumnJSON:
+		return string(raw), nil
+	default:
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	}
+}
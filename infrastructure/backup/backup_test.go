@@ -0,0 +1,371 @@
+package backup_test
+
+import (
+	"app/infrastructure/backup"
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+func newDbMock(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to initialize mock DB: %v", err)
+	}
+	gormDB, err := gorm.Open(mysql.Dialector{
+		Config: &mysql.Config{DriverName: "mysql", Conn: db, SkipInitializeWithVersion: true},
+	},
+		&gorm.Config{
+			NamingStrategy: schema.NamingStrategy{
+				SingularTable: true,
+			},
+		})
+	if err != nil {
+		t.Fatalf("Failed to initialize gorm: %v", err)
+	}
+	return gormDB, mock
+}
+
+var createdAt = time.Date(2026, 1, 2, 3, 4, 5, 600000000, time.UTC)
+
+// expectBackup はworkspaceとtodoに1行ずつあるDBの読み出しを期待する
+func expectBackup(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	expectTables(mock, backup.Tables, "牛乳を買う")
+	mock.ExpectCommit()
+}
+
+// expectTables はtablesのSELECTを期待し、todoのtaskだけを差し替えられる
+func expectTables(mock sqlmock.Sqlmock, tables []string, task string) {
+	for _, name := range tables {
+		// 主キーの順に読み出される
+		order := "`id`"
+		if name == "member" {
+			order = "`workspace_id`,`user_id`"
+		}
+		q := mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `" + name + "` ORDER BY " + order))
+		switch name {
+		case "workspace":
+			q.WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("id").OfType("BIGINT", int64(0)),
+				sqlmock.NewColumn("name").OfType("VARCHAR", ""),
+				sqlmock.NewColumn("created_at").OfType("DATETIME", time.Time{}),
+			).AddRow([]byte("1"), []byte("家"), createdAt))
+		case "todo":
+			q.WillReturnRows(sqlmock.NewRowsWithColumnDefinition(
+				sqlmock.NewColumn("id").OfType("BIGINT", int64(0)),
+				sqlmock.NewColumn("workspace_id").OfType("BIGINT", int64(0)),
+				sqlmock.NewColumn("task").OfType("VARCHAR", ""),
+				sqlmock.NewColumn("deleted_at").OfType("DATETIME", time.Time{}),
+			).AddRow(int64(10), int64(1), []byte(task), nil))
+		default:
+			q.WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		}
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{
+			name: "正常系_暗号化しないアーカイブから復元できること",
+		},
+		{
+			name:       "正常系_暗号化したアーカイブから復元できること",
+			passphrase: "secret",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock := newDbMock(t)
+			expectBackup(mock)
+			var buf bytes.Buffer
+			want, err := backup.Backup(db, &buf, tt.passphrase)
+			if err != nil {
+				t.Fatalf("Backup() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+			if got := want.Tables[0].Columns[2].Type; got != backup.ColumnTime {
+				t.Errorf("created_at type want = %s, got = %s", backup.ColumnTime, got)
+			}
+
+			db, mock = newDbMock(t)
+			mock.ExpectBegin()
+			for _, name := range backup.Tables {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `" + name + "`")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			}
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `workspace` (`created_at`,`id`,`name`) VALUES (?,?,?)")).
+				WithArgs(createdAt, 1, "家").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`deleted_at`,`id`,`task`,`workspace_id`) VALUES (?,?,?,?)")).
+				WithArgs(nil, 10, "牛乳を買う", 1).WillReturnResult(sqlmock.NewResult(10, 1))
+			mock.ExpectCommit()
+			got, err := backup.Restore(db, &buf, tt.passphrase, false)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("manifest mismatch (-want +got):\n%s", diff)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+// archive はテーブルファイルと、内容に関わらずfilesから計算したマニフェストを書く
+func archive(t *testing.T, m backup.Manifest, files map[string]string, tamper map[string]string) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sum := sha256.Sum256([]byte(files[name]))
+		m.Tables = append(m.Tables, backup.Table{
+			Name:    name,
+			Columns: []backup.Column{{Name: "id", Type: backup.ColumnInt}},
+			Rows:    strings.Count(files[name], "\n"),
+			SHA256:  hex.EncodeToString(sum[:]),
+		})
+	}
+	var buf bytes.Buffer
+	buf.WriteString("TODOBAK1")
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, body string) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("manifest.json", string(b))
+	for _, name := range names {
+		body := files[name]
+		if s, ok := tamper[name]; ok {
+			body = s
+		}
+		write(name+".jsonl", body)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRestore_Error(t *testing.T) {
+	t.Parallel()
+	current := backup.Manifest{FormatVersion: backup.FormatVersion, SchemaVersion: backup.SchemaVersion}
+	newer := current
+	newer.SchemaVersion++
+	older := current
+	older.SchemaVersion = 1
+
+	db, mock := newDbMock(t)
+	expectBackup(mock)
+	var encrypted bytes.Buffer
+	if _, err := backup.Backup(db, &encrypted, "secret"); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	truncated := encrypted.Bytes()[:encrypted.Len()-1]
+	flipped := bytes.Clone(encrypted.Bytes())
+	flipped[len(flipped)/2] ^= 1
+	// 小さなアーカイブは最後のチャンクだけなので、切れていても改ざんされていてもマニフェストを読む前に失敗する
+
+	emptyTables := func(mock sqlmock.Sqlmock) {
+		for _, name := range backup.Tables {
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `" + name + "`")).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		}
+	}
+	tests := []struct {
+		name       string
+		data       []byte
+		passphrase string
+		expect     func(mock sqlmock.Sqlmock)
+		err        error
+	}{
+		{
+			name: "異常系_アーカイブでない場合ErrInvalidArchiveになること",
+			data: []byte("not an archive"),
+			err:  backup.ErrInvalidArchive,
+		},
+		{
+			name: "異常系_暗号化されたアーカイブにパスフレーズがない場合ErrPassphraseRequiredになること",
+			data: encrypted.Bytes(),
+			err:  backup.ErrPassphraseRequired,
+		},
+		{
+			name:       "異常系_パスフレーズが違う場合ErrWrongPassphraseになること",
+			data:       encrypted.Bytes(),
+			passphrase: "wrong",
+			err:        backup.ErrWrongPassphrase,
+		},
+		{
+			name:       "異常系_暗号化されたアーカイブが途中で切れている場合ErrWrongPassphraseになること",
+			data:       truncated,
+			passphrase: "secret",
+			err:        backup.ErrWrongPassphrase,
+		},
+		{
+			name:       "異常系_暗号化されたアーカイブが改ざんされている場合ErrWrongPassphraseになること",
+			data:       flipped,
+			passphrase: "secret",
+			err:        backup.ErrWrongPassphrase,
+		},
+		{
+			name: "異常系_新しいスキーマのアーカイブの場合ErrSchemaVersionになること",
+			data: archive(t, newer, map[string]string{"todo": ""}, nil),
+			err:  backup.ErrSchemaVersion,
+		},
+		{
+			name: "異常系_古いスキーマのカラムがなくなっている場合ErrSchemaVersionになること",
+			data: archive(t, older, map[string]string{"todo": ""}, nil),
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` LIMIT 1")).WillReturnRows(sqlmock.NewRows([]string{"task"}))
+				mock.ExpectRollback()
+			},
+			err: backup.ErrSchemaVersion,
+		},
+		{
+			name: "異常系_チェックサムが一致しない場合ロールバックしてErrInvalidArchiveになること",
+			data: archive(t, current, map[string]string{"todo": "{\"id\":1}\n"}, map[string]string{"todo": "{\"id\":2}\n"}),
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				emptyTables(mock)
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`id`) VALUES (?)")).
+					WithArgs(2).WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectRollback()
+			},
+			err: backup.ErrInvalidArchive,
+		},
+		{
+			name: "異常系_テーブルが空でない場合ErrNotEmptyになること",
+			data: archive(t, current, map[string]string{"todo": ""}, nil),
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `workspace`")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectRollback()
+			},
+			err: backup.ErrNotEmpty,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock := newDbMock(t)
+			if tt.expect != nil {
+				tt.expect(mock)
+			}
+			_, err := backup.Restore(db, bytes.NewReader(tt.data), tt.passphrase, false)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestRestore_Replace(t *testing.T) {
+	t.Parallel()
+	db, mock := newDbMock(t)
+	mock.ExpectBegin()
+	for _, name := range backup.Tables {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `" + name + "`")).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`id`) VALUES (?)")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	m := backup.Manifest{FormatVersion: backup.FormatVersion, SchemaVersion: backup.SchemaVersion}
+	data := archive(t, m, map[string]string{"todo": "{\"id\":1}\n"}, nil)
+	if _, err := backup.Restore(db, bytes.NewReader(data), "", true); err != nil {
+		t.Errorf("Restore() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %v", err)
+	}
+}
+
+// マイグレーションを追加したときにSchemaVersionとTablesの更新漏れを防ぐ
+func TestSchemaVersion(t *testing.T) {
+	t.Parallel()
+	files, err := filepath.Glob("../../migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	latest := 0
+	tables := map[string]bool{}
+	createTable := regexp.MustCompile("(?i)CREATE TABLE (?:IF NOT EXISTS )?`?(\\w+)`?")
+	for _, f := range files {
+		n, err := strconv.Atoi(strings.SplitN(filepath.Base(f), "_", 2)[0])
+		if err != nil {
+			t.Fatalf("unexpected migration name %s", f)
+		}
+		if n > latest {
+			latest = n
+		}
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := bufio.NewScanner(bytes.NewReader(b))
+		for s.Scan() {
+			if m := createTable.FindStringSubmatch(s.Text()); m != nil {
+				tables[m[1]] = true
+			}
+		}
+	}
+	if latest != backup.SchemaVersion {
+		t.Errorf("SchemaVersion want = %d, got = %d", latest, backup.SchemaVersion)
+	}
+	for _, name := range backup.Tables {
+		delete(tables, name)
+	}
+	if len(tables) > 0 {
+		t.Errorf("tables missing from backup.Tables: %v", tables)
+	}
+}
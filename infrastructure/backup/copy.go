@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrMismatch = errors.New("the copy does not match the source")
+
+// Copy copies every table from one database to another, keeping IDs and
+// timestamps, since the rows are inserted as maps rather than through the
+// models. The source is read in one read-only transaction and the target
+// written in one transaction, which is rolled back unless the row count
+// and checksum of every table in the target match the source. The target
+// must be empty.
+//
+// Both sides are read in the order of the primary key, so the SHA256 of
+// the returned tables is the checksum of the rows in that order, the same
+// as of the files of a backup.
+func Copy(from, to *gorm.DB) ([]Table, error) {
+	var tables []Table
+	err := from.Transaction(func(src *gorm.DB) error {
+		return to.Transaction(func(dst *gorm.DB) error {
+			if err := prepare(dst, false); err != nil {
+				return err
+			}
+			for _, name := range Tables {
+				t, err := copyTable(src, dst, name)
+				if err != nil {
+					return fmt.Errorf("table %s: %w", name, err)
+				}
+				tables = append(tables, *t)
+			}
+			for _, t := range tables {
+				got, err := checksum(dst, t.Name)
+				if err != nil {
+					return fmt.Errorf("table %s: %w", t.Name, err)
+				}
+				if got.Rows != t.Rows || got.SHA256 != t.SHA256 {
+					return fmt.Errorf("%w: table %s has %d rows with checksum %s, want %d rows with %s", ErrMismatch, t.Name, got.Rows, got.SHA256, t.Rows, t.SHA256)
+				}
+			}
+			return nil
+		})
+	}, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	// MySQL commits implicitly on ALTER TABLE, so the sequences are reset
+	// after the copy is committed.
+	if err := resetSequences(to, tables); err != nil {
+		return nil, fmt.Errorf("the rows were copied but resetting the sequences failed: %w", err)
+	}
+	return tables, nil
+}
+
+func copyTable(src, dst *gorm.DB, name string) (*Table, error) {
+	rows, err := ordered(src, name).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := columnsOf(rows)
+	if err != nil {
+		return nil, err
+	}
+	types := map[string]ColumnType{}
+	for _, c := range columns {
+		types[c.Name] = c.Type
+	}
+
+	t := &Table{Name: name, Columns: columns}
+	h := sha256.New()
+	batch := make([]map[string]any, 0, restoreBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := dst.Table(name).Create(&batch).Error; err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for rows.Next() {
+		b, err := encodeRow(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(append(b, '\n'))
+		t.Rows++
+		row, err := decodeRow(b, types)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, row)
+		if len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	t.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return t, nil
+}
+
+func checksum(db *gorm.DB, name string) (*Table, error) {
+	rows, err := ordered(db, name).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := columnsOf(rows)
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{Name: name, Columns: columns}
+	h := sha256.New()
+	for rows.Next() {
+		b, err := encodeRow(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		h.Write(append(b, '\n'))
+		t.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	t.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return t, nil
+}
+
+// resetSequences moves the next value of the id sequence of each table past
+// the copied IDs. MySQL is the only backend OpenDB supports, so this uses its
+// AUTO_INCREMENT; another backend needs its own way to reset a sequence.
+func resetSequences(db *gorm.DB, tables []Table) error {
+	for _, t := range tables {
+		if !hasIntID(t) {
+			continue
+		}
+		var max int64
+		if err := db.Table(t.Name).Select("COALESCE(MAX(id), 0)").Scan(&max).Error; err != nil {
+			return err
+		}
+		// ALTER TABLE does not take placeholders.
+		err := db.Exec("ALTER TABLE ? AUTO_INCREMENT = "+strconv.FormatInt(max+1, 10), clause.Table{Name: t.Name}).Error
+		if err != nil {
+			return fmt.Errorf("table %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func hasIntID(t Table) bool {
+	for _, c := range t.Columns {
+		if c.Name == "id" && c.Type == ColumnInt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package backup_test
+
+import (
+	"app/infrastructure/backup"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		// copied はコピー後にコピー先から読み出されるtodoのtask
+		copied string
+		// verified はコピー後に読み出されるテーブルで、一致しないテーブルで止まる
+		verified []string
+		err      error
+	}{
+		{
+			name:     "正常系_IDとタイムスタンプを保ったままコピーしシーケンスを進めること",
+			copied:   "牛乳を買う",
+			verified: backup.Tables,
+		},
+		{
+			name:     "異常系_コピー先のチェックサムが一致しない場合ロールバックしてErrMismatchになること",
+			copied:   "卵を買う",
+			verified: []string{"workspace", "member", "invitation", "list", "todo"},
+			err:      backup.ErrMismatch,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			from, src := newDbMock(t)
+			to, dst := newDbMock(t)
+			src.ExpectBegin()
+			expectTables(src, backup.Tables, "牛乳を買う")
+			dst.ExpectBegin()
+			for _, name := range backup.Tables {
+				dst.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `" + name + "`")).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+			}
+			dst.ExpectExec(regexp.QuoteMeta("INSERT INTO `workspace` (`created_at`,`id`,`name`) VALUES (?,?,?)")).
+				WithArgs(createdAt, 1, "家").WillReturnResult(sqlmock.NewResult(1, 1))
+			dst.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`deleted_at`,`id`,`task`,`workspace_id`) VALUES (?,?,?,?)")).
+				WithArgs(nil, 10, "牛乳を買う", 1).WillReturnResult(sqlmock.NewResult(10, 1))
+			expectTables(dst, tt.verified, tt.copied)
+			if tt.err != nil {
+				dst.ExpectRollback()
+				src.ExpectRollback()
+			} else {
+				dst.ExpectCommit()
+				src.ExpectCommit()
+				for _, seq := range []struct {
+					table string
+					max   int
+					next  string
+				}{{"workspace", 1, "2"}, {"todo", 10, "11"}} {
+					dst.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM `" + seq.table + "`")).
+						WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(seq.max))
+					dst.ExpectExec(regexp.QuoteMeta("ALTER TABLE `" + seq.table + "` AUTO_INCREMENT = " + seq.next)).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+				}
+			}
+
+			tables, err := backup.Copy(from, to)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil {
+				if len(tables) != len(backup.Tables) || tables[0].Rows != 1 || tables[4].Rows != 1 {
+					t.Errorf("unexpected tables: %+v", tables)
+				}
+			}
+			if err := src.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled source expectations: %v", err)
+			}
+			if err := dst.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled target expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltSize  = 16
+	chunkSize = 64 << 10
+	// The nonce of a chunk is a random prefix, the chunk counter and a flag
+	// for the last chunk, so chunks cannot be reordered, dropped or cut off
+	// without failing authentication.
+	noncePrefixSize = 7
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted archive")
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type encryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	count  uint32
+	buf    []byte
+}
+
+// newEncryptWriter writes the salt and nonce prefix, followed by the
+// sealed chunks on Write and Close.
+func newEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	header := make([]byte, saltSize+noncePrefixSize)
+	if _, err := rand.Read(header); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, header[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, prefix: header[saltSize:]}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data follows, since the
+		// last chunk is sealed differently.
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return 0, err
+			}
+		}
+		m := chunkSize - len(e.buf)
+		if m > len(p) {
+			m = len(p)
+		}
+		e.buf = append(e.buf, p[:m]...)
+		p = p[m:]
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+// seal writes a chunk as its last flag, its length and its ciphertext.
+func (e *encryptWriter) seal(last bool) error {
+	header := make([]byte, 5)
+	if last {
+		header[0] = 1
+	}
+	sealed := e.aead.Seal(nil, nonce(e.prefix, e.count, last), e.buf, nil)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := e.w.Write(append(header, sealed...)); err != nil {
+		return err
+	}
+	e.count++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+type decryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	count  uint32
+	buf    []byte
+	done   bool
+}
+
+func newDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, saltSize+noncePrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWrongPassphrase, err)
+	}
+	aead, err := newAEAD(passphrase, header[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: r, aead: aead, prefix: header[saltSize:]}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		// The stream ended without its last chunk.
+		return fmt.Errorf("%w: %w", ErrWrongPassphrase, io.ErrUnexpectedEOF)
+	}
+	last := header[0] == 1
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrWrongPassphrase
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("%w: %w", ErrWrongPassphrase, io.ErrUnexpectedEOF)
+	}
+	plain, err := d.aead.Open(nil, nonce(d.prefix, d.count, last), sealed, nil)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+	d.count++
+	d.buf = plain
+	d.done = last
+	return nil
+}
+
+func nonce(prefix []byte, count uint32, last bool) []byte {
+	n := make([]byte, 12)
+	copy(n, prefix)
+	binary.BigEndian.PutUint32(n[noncePrefixSize:], count)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
@@ -0,0 +1,100 @@
+package infrastructure
+
+import (
+	"app/domain/event"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrEventBusClosed = errors.New("event bus is closed")
+
+type EventBus struct {
+	queueSize int
+
+	mu     sync.RWMutex
+	sync   []event.Handler
+	async  []*asyncSubscriber
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type asyncSubscriber struct {
+	name    string
+	handler event.Handler
+	queue   chan event.Event
+}
+
+// NewEventBus creates an in-process bus. Publishing blocks while an async
+// subscriber's queue of queueSize events is full.
+func NewEventBus(queueSize int) event.Bus {
+	return &EventBus{
+		queueSize: queueSize,
+	}
+}
+
+func (b *EventBus) Subscribe(h event.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync = append(b.sync, h)
+}
+
+func (b *EventBus) SubscribeAsync(name string, h event.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &asyncSubscriber{name: name, handler: h, queue: make(chan event.Event, b.queueSize)}
+	b.async = append(b.async, s)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for e := range s.queue {
+			s.handle(e)
+		}
+	}()
+}
+
+func (b *EventBus) Publish(ctx context.Context, events ...event.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrEventBusClosed
+	}
+	var errs []error
+	for _, e := range events {
+		for _, h := range b.sync {
+			if err := h(ctx, e); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for _, s := range b.async {
+			s.queue <- e
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, s := range b.async {
+		close(s.queue)
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+func (s *asyncSubscriber) handle(e event.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("event subscriber %s panicked on %s, err = %v\n", s.name, e.Name(), r)
+		}
+	}()
+	if err := s.handler(context.Background(), e); err != nil {
+		fmt.Printf("event subscriber %s failed on %s, err = %s\n", s.name, e.Name(), err.Error())
+	}
+}
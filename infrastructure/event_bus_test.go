@@ -0,0 +1,73 @@
+package infrastructure_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/infrastructure"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEventBusPublish(t *testing.T) {
+	t.Parallel()
+	t.Run("同期購読者のエラーが発行元に返されること", func(t *testing.T) {
+		bus := infrastructure.NewEventBus(0)
+		defer bus.Close()
+		want := errors.New("xxxx error")
+		var called int
+		bus.Subscribe(func(ctx context.Context, e event.Event) error { return want })
+		bus.Subscribe(func(ctx context.Context, e event.Event) error {
+			called++
+			return nil
+		})
+
+		err := bus.Publish(context.Background(), event.TodoCreated{Todo: model.Todo{ID: 1, WorkspaceID: 1}})
+		if !errors.Is(err, want) {
+			t.Errorf("want = %v, got = %v", want, err)
+		}
+		if called != 1 {
+			t.Errorf("want = %v, got = %v", 1, called)
+		}
+	})
+	t.Run("非同期購読者のイベントがCloseまでに処理されること", func(t *testing.T) {
+		bus := infrastructure.NewEventBus(1)
+		var got []string
+		bus.SubscribeAsync("test", func(ctx context.Context, e event.Event) error {
+			got = append(got, e.Name())
+			return errors.New("xxxx error")
+		})
+
+		err := bus.Publish(context.Background(), event.TodoCreated{}, event.TodoDeleted{})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		bus.Close()
+		if len(got) != 2 || got[0] != event.TodoCreatedName || got[1] != event.TodoDeletedName {
+			t.Errorf("unexpected events: %v", got)
+		}
+	})
+	t.Run("非同期購読者がpanicしても後続のイベントが処理されること", func(t *testing.T) {
+		bus := infrastructure.NewEventBus(1)
+		var got int
+		bus.SubscribeAsync("test", func(ctx context.Context, e event.Event) error {
+			got++
+			panic("xxxx")
+		})
+
+		_ = bus.Publish(context.Background(), event.TodoCreated{}, event.TodoCreated{})
+		bus.Close()
+		if got != 2 {
+			t.Errorf("want = %v, got = %v", 2, got)
+		}
+	})
+	t.Run("Close後の発行はエラーになること", func(t *testing.T) {
+		bus := infrastructure.NewEventBus(0)
+		bus.Close()
+
+		err := bus.Publish(context.Background(), event.TodoCreated{})
+		if !errors.Is(err, infrastructure.ErrEventBusClosed) {
+			t.Errorf("want = %v, got = %v", infrastructure.ErrEventBusClosed, err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+const mysqlErrDuplicateEntry = 1062
+
+type IdempotencyKey struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKey(db *gorm.DB) repository.IdempotencyKey {
+	return &IdempotencyKey{
+		db: db,
+	}
+}
+
+func (ik *IdempotencyKey) Create(ctx context.Context, k *model.IdempotencyKey) error {
+	if err := ik.db.WithContext(ctx).Create(k).Error; err != nil {
+		if isDuplicate(err) {
+			return repository.ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (ik *IdempotencyKey) Complete(ctx context.Context, k *model.IdempotencyKey) (bool, error) {
+	result := ik.db.WithContext(ctx).Model(&model.IdempotencyKey{}).
+		Where("id = ? AND status = ?", k.ID, model.InFlight).
+		Select("status", "response_code", "response_content_type", "response_body").Updates(k)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (ik *IdempotencyKey) Delete(ctx context.Context, id int) error {
+	if err := ik.db.WithContext(ctx).Where("id = ?", id).Delete(&model.IdempotencyKey{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ik *IdempotencyKey) DeleteExpired(ctx context.Context, now time.Time) error {
+	if err := ik.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ik *IdempotencyKey) Find(ctx context.Context, userID int, key string) (*model.IdempotencyKey, error) {
+	var idempotencyKey *model.IdempotencyKey
+	err := ik.db.WithContext(ctx).Where("user_id = ? AND `key` = ?", userID, key).Take(&idempotencyKey).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return idempotencyKey, nil
+}
+
+func isDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,134 @@
+package infrastructure_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/infrastructure"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIdempotencyKeyCreate(t *testing.T) {
+	t.Parallel()
+	expiresAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "キーの登録が行えること",
+		},
+		{
+			name: "一意制約違反の場合ErrDuplicateが返ること",
+			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+			want: repository.ErrDuplicate,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			k := model.NewIdempotencyKey(1, "key", "fp", expiresAt)
+			db, mock, err := newDbMock()
+			if err != nil {
+				t.Errorf("Failed to initialize mock DB: %v", err)
+				return
+			}
+			repository := infrastructure.NewIdempotencyKey(db)
+			mock.ExpectBegin()
+			exec := mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_key` (`user_id`,`key`,`fingerprint`,`status`,`response_code`,`response_content_type`,`response_body`,`expires_at`) VALUES (?,?,?,?,?,?,?,?)")).
+				WithArgs(1, "key", "fp", model.InFlight, 0, "", []byte(nil), expiresAt)
+			if tt.err != nil {
+				exec.WillReturnError(tt.err)
+				mock.ExpectRollback()
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+			err = repository.Create(context.Background(), k)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("want = %v, got = %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyComplete(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		rows int64
+		want bool
+	}{
+		{name: "予約中のキーにだけレスポンスが保存されること", rows: 1, want: true},
+		{name: "予約し直されたキーは更新されないこと", rows: 0, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := newDbMock()
+			if err != nil {
+				t.Fatalf("Failed to initialize mock DB: %v", err)
+			}
+			k := &model.IdempotencyKey{ID: 3, Status: model.Completed, ResponseCode: 201, ResponseContentType: "application/json", ResponseBody: []byte(`{}`)}
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `idempotency_key` SET `status`=?,`response_code`=?,`response_content_type`=?,`response_body`=? WHERE id = ? AND status = ?")).
+				WithArgs(model.Completed, 201, "application/json", []byte(`{}`), 3, model.InFlight).
+				WillReturnResult(sqlmock.NewResult(0, tt.rows))
+			mock.ExpectCommit()
+			got, err := infrastructure.NewIdempotencyKey(db).Complete(context.Background(), k)
+			if err != nil || got != tt.want {
+				t.Errorf("want = %v %v, got = %v %v", tt.want, nil, got, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyFind(t *testing.T) {
+	t.Parallel()
+	t.Run("キーの検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewIdempotencyKey(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `idempotency_key` WHERE user_id = ? AND `key` = ? LIMIT 1")).
+			WithArgs(1, "key").WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Find(context.Background(), 1, "key")
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestIdempotencyKeyDeleteExpired(t *testing.T) {
+	t.Parallel()
+	t.Run("有効期限切れのキーの削除が行えること", func(t *testing.T) {
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewIdempotencyKey(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `idempotency_key` WHERE expires_at <= ?")).
+			WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+		err = repository.DeleteExpired(context.Background(), now)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
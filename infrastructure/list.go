@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type List struct {
+	db *gorm.DB
+}
+
+func NewList(db *gorm.DB) repository.List {
+	return &List{
+		db: db,
+	}
+}
+
+func (ls *List) Create(ctx context.Context, l *model.List) error {
+	if err := ls.db.WithContext(ctx).Create(l).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ls *List) Update(ctx context.Context, l *model.List) error {
+	err := ls.db.WithContext(ctx).Model(&model.List{}).Where("id = ?", l.ID).Update("name", l.Name).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ls *List) Delete(ctx context.Context, id int) error {
+	if err := ls.db.WithContext(ctx).Where("id = ?", id).Delete(&model.List{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ls *List) Find(ctx context.Context, id int) (*model.List, error) {
+	return ls.find(ls.db.WithContext(ctx), id)
+}
+
+func (ls *List) FindForUpdate(ctx context.Context, id int) (*model.List, error) {
+	return ls.find(ls.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (ls *List) find(db *gorm.DB, id int) (*model.List, error) {
+	var list *model.List
+	err := db.Where("id = ?", id).Take(&list).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func (ls *List) FindAll(ctx context.Context, workspaceID int) ([]*model.List, error) {
+	var lists []*model.List
+	err := ls.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Order("id").Find(&lists).Error
+	if err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
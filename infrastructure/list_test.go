@@ -0,0 +1,114 @@
+package infrastructure_test
+
+import (
+	"app/domain/model"
+	"app/infrastructure"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestListCreate(t *testing.T) {
+	t.Parallel()
+	t.Run("リストの登録が行えること", func(t *testing.T) {
+		list := model.NewList(1, "買い物")
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewList(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `list` (`workspace_id`,`name`) VALUES (?,?)")).
+			WithArgs(1, "買い物").WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectCommit()
+		err = repository.Create(context.Background(), list)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if list.ID != 3 {
+			t.Errorf("want = %v, got = %v", 3, list.ID)
+		}
+	})
+}
+
+func TestListUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("リスト名の更新が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewList(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `list` SET `name`=? WHERE id = ?")).
+			WithArgs("仕事", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err = repository.Update(context.Background(), &model.List{ID: 3, WorkspaceID: 1, Name: "仕事"})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestListFindForUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("リストをロックして検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewList(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `list` WHERE id = ? LIMIT 1 FOR UPDATE")).
+			WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name"}).AddRow(3, 1, "買い物"))
+		got, err := repository.FindForUpdate(context.Background(), 3)
+		if err != nil || got == nil || got.ID != 3 {
+			t.Errorf("want = %v %v, got = %+v %v", 3, nil, got, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("存在しない場合nilが返ること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewList(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `list` WHERE id = ? LIMIT 1 FOR UPDATE")).
+			WithArgs(3).WillReturnRows(&sqlmock.Rows{})
+		got, err := repository.FindForUpdate(context.Background(), 3)
+		if err != nil || got != nil {
+			t.Errorf("want = %v %v, got = %v %v", nil, nil, got, err)
+		}
+	})
+}
+
+func TestListFindAll(t *testing.T) {
+	t.Parallel()
+	t.Run("ワークスペースのリストの検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewList(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `list` WHERE workspace_id = ? ORDER BY id")).
+			WithArgs(1).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.FindAll(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
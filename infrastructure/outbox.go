@@ -0,0 +1,105 @@
+package infrastructure
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Outbox struct {
+	db *gorm.DB
+}
+
+func NewOutbox(db *gorm.DB) repository.Outbox {
+	return &Outbox{
+		db: db,
+	}
+}
+
+func (o *Outbox) Store(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	messages := make([]*model.Outbox, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, model.NewOutbox(e.WorkspaceID(), e.Name(), payload))
+	}
+	if err := o.db.WithContext(ctx).Create(&messages).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// Relay selects the head of the outbox with FOR UPDATE. MySQL 5.7 has no
+// SKIP LOCKED, so a concurrent relay blocks on the same rows until this
+// transaction commits and then skips what was published, which keeps the
+// events in order across instances.
+func (o *Outbox) Relay(ctx context.Context, limit int, now time.Time, publish func(messages []*model.Outbox) (int, error)) (int, error) {
+	var published int
+	var publishErr error
+	err := o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var messages []*model.Outbox
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("published_at IS NULL").Order("id").Limit(limit).Find(&messages).Error
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		n, err := publish(messages)
+		publishErr = err
+		if n > 0 {
+			ids := make([]int, 0, n)
+			for _, m := range messages[:n] {
+				ids = append(ids, m.ID)
+			}
+			if err := tx.Model(&model.Outbox{}).Where("id IN ?", ids).Update("published_at", now).Error; err != nil {
+				return err
+			}
+		}
+		published = n
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	// The published prefix is committed even when a later message failed;
+	// the failed one is retried first on the next relay.
+	return published, publishErr
+}
+
+func (o *Outbox) FindAfter(ctx context.Context, id int, limit int) ([]*model.Outbox, error) {
+	var messages []*model.Outbox
+	err := o.db.WithContext(ctx).Where("id > ?", id).Order("id").Limit(limit).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (o *Outbox) LastID(ctx context.Context) (int, error) {
+	var id int
+	err := o.db.WithContext(ctx).Model(&model.Outbox{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (o *Outbox) DeletePublishedBefore(ctx context.Context, t time.Time) error {
+	if err := o.db.WithContext(ctx).Where("published_at < ?", t).Delete(&model.Outbox{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package infrastructure_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/infrastructure"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOutboxStore(t *testing.T) {
+	t.Parallel()
+	t.Run("イベントを保存できること", func(t *testing.T) {
+		todo := model.Todo{ID: 7, WorkspaceID: 1, Task: "task"}
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewOutbox(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox` (`workspace_id`,`name`,`payload`,`published_at`) VALUES (?,?,?,?),(?,?,?,?)")).
+			WithArgs(1, event.TodoUpdatedName, sqlmock.AnyArg(), nil, 1, event.TodoStatusChangedName, sqlmock.AnyArg(), nil).
+			WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+		err = repository.Store(context.Background(), event.TodoUpdated{After: todo}, event.TodoStatusChanged{Todo: todo})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestOutboxRelay(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t.Run("ロックした未発行のイベントを発行済みにできること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewOutbox(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `outbox` WHERE published_at IS NULL ORDER BY id LIMIT 100 FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "payload"}).
+				AddRow(1, 1, "todo.created", []byte("{}")).
+				AddRow(2, 1, "todo.deleted", []byte("{}")))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox` SET `published_at`=? WHERE id IN (?,?)")).
+			WithArgs(now, 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+		var got []int
+		n, err := repository.Relay(context.Background(), 100, now, func(messages []*model.Outbox) (int, error) {
+			for _, m := range messages {
+				got = append(got, m.ID)
+			}
+			return len(messages), nil
+		})
+		if err != nil || n != 2 {
+			t.Errorf("want = %v %v, got = %v %v", 2, nil, n, err)
+		}
+		if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+			t.Errorf("unexpected order: %v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("発行に失敗した場合それより前のイベントだけが発行済みになること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewOutbox(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `outbox` WHERE published_at IS NULL ORDER BY id LIMIT 100 FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "payload"}).
+				AddRow(1, 1, "todo.created", []byte("{}")).
+				AddRow(2, 1, "todo.deleted", []byte("{}")))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox` SET `published_at`=? WHERE id IN (?)")).
+			WithArgs(now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		want := errors.New("xxxx error")
+		n, err := repository.Relay(context.Background(), 100, now, func(messages []*model.Outbox) (int, error) {
+			return 1, want
+		})
+		if !errors.Is(err, want) || n != 1 {
+			t.Errorf("want = %v %v, got = %v %v", 1, want, n, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestOutboxFindAfter(t *testing.T) {
+	t.Parallel()
+	t.Run("指定したIDより後のイベントを発行済みかどうかにかかわらずID順に検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewOutbox(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `outbox` WHERE id > ? ORDER BY id LIMIT 100")).
+			WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "name", "payload"}).
+			AddRow(6, 1, "todo.created", []byte("{}")))
+		messages, err := repository.FindAfter(context.Background(), 5, 100)
+		if err != nil || len(messages) != 1 || messages[0].ID != 6 {
+			t.Errorf("want = %v %v, got = %v %v", 6, nil, messages, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestOutboxLastID(t *testing.T) {
+	t.Parallel()
+	t.Run("最後のイベントのIDが取得できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewOutbox(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM `outbox`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+		id, err := repository.LastID(context.Background())
+		if err != nil || id != 42 {
+			t.Errorf("want = %v %v, got = %v %v", 42, nil, id, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestOutboxDeletePublishedBefore(t *testing.T) {
+	t.Parallel()
+	t.Run("保持期間を過ぎた発行済みのイベントを削除できること", func(t *testing.T) {
+		before := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewOutbox(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `outbox` WHERE published_at < ?")).
+			WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+		if err := repository.DeletePublishedBefore(context.Background(), before); err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
@@ -3,10 +3,15 @@ package infrastructure
 import (
 	"app/domain/model"
 	"app/domain/repository"
+	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// batchSize is the number of rows per multi-row insert.
+const batchSize = 100
+
 type Todo struct {
 	db *gorm.DB
 }
@@ -17,30 +22,48 @@ func NewTodo(db *gorm.DB) repository.Todo {
 	}
 }
 
-func (td *Todo) Create(t *model.Todo) error {
-	if err := td.db.Create(t).Error; err != nil {
+func (td *Todo) Create(ctx context.Context, t *model.Todo) error {
+	if err := td.db.WithContext(ctx).Create(t).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (td *Todo) CreateBatch(ctx context.Context, todos []*model.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	if err := td.db.WithContext(ctx).CreateInBatches(todos, batchSize).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (td *Todo) Update(t *model.Todo) error {
-	if err := td.db.Save(t).Error; err != nil {
+func (td *Todo) Update(ctx context.Context, t *model.Todo) error {
+	if err := td.db.WithContext(ctx).Save(t).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (td *Todo) Delete(id int) error {
-	if err := td.db.Where("id = ?", id).Delete(&model.Todo{}).Error; err != nil {
+func (td *Todo) Delete(ctx context.Context, id int) error {
+	if err := td.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Todo{}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
-func (td *Todo) Find(id int) (*model.Todo, error) {
+func (td *Todo) Find(ctx context.Context, id int) (*model.Todo, error) {
+	return td.find(td.db.WithContext(ctx), id)
+}
+
+func (td *Todo) FindForUpdate(ctx context.Context, id int) (*model.Todo, error) {
+	return td.find(td.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (td *Todo) find(db *gorm.DB, id int) (*model.Todo, error) {
 	var todo *model.Todo
-	err := td.db.Where("id = ?", id).Take(&todo).Error
+	err := db.Where("id = ?", id).Take(&todo).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -49,11 +72,91 @@ func (td *Todo) Find(id int) (*model.Todo, error) {
 	}
 	return todo, nil
 }
-func (td *Todo) FindAll() ([]*model.Todo, error) {
+func (td *Todo) FindAll(ctx context.Context, workspaceID int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	err := td.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (td *Todo) FindByIDs(ctx context.Context, ids []int) ([]*model.Todo, error) {
+	return td.findByIDs(td.db.WithContext(ctx), ids)
+}
+
+// FindByIDsForUpdate locks the todos in the order of their IDs, so that two
+// transactions locking the same todos do not deadlock.
+func (td *Todo) FindByIDsForUpdate(ctx context.Context, ids []int) ([]*model.Todo, error) {
+	return td.findByIDs(td.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Order("id"), ids)
+}
+
+func (td *Todo) findByIDs(db *gorm.DB, ids []int) ([]*model.Todo, error) {
+	var todos []*model.Todo
+	if len(ids) == 0 {
+		return todos, nil
+	}
+	err := db.Where("id IN ?", ids).Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (td *Todo) Search(ctx context.Context, workspaceID int, f model.TodoFilter) ([]*model.Todo, error) {
+	return td.search(td.filter(ctx, workspaceID, f), f)
+}
+
+// SearchForUpdate locks the matching todos in the order of their IDs, like
+// FindByIDsForUpdate.
+func (td *Todo) SearchForUpdate(ctx context.Context, workspaceID int, f model.TodoFilter) ([]*model.Todo, error) {
+	return td.search(td.filter(ctx, workspaceID, f).Clauses(clause.Locking{Strength: "UPDATE"}), f)
+}
+
+func (td *Todo) search(q *gorm.DB, f model.TodoFilter) ([]*model.Todo, error) {
 	var todos []*model.Todo
-	err := td.db.Find(&todos).Error
+	q = q.Order("id")
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	err := q.Find(&todos).Error
 	if err != nil {
 		return nil, err
 	}
 	return todos, nil
 }
+
+func (td *Todo) Count(ctx context.Context, workspaceID int, f model.TodoFilter) (int64, error) {
+	var count int64
+	err := td.filter(ctx, workspaceID, f).Model(&model.Todo{}).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (td *Todo) filter(ctx context.Context, workspaceID int, f model.TodoFilter) *gorm.DB {
+	q := td.db.WithContext(ctx).Where("workspace_id = ?", workspaceID)
+	if f.Status != "" {
+		q = q.Where("status = ?", f.Status)
+	}
+	if f.ListID != 0 {
+		q = q.Where("list_id = ?", f.ListID)
+	}
+	if len(f.IDs) > 0 {
+		q = q.Where("id IN ?", f.IDs)
+	}
+	if f.AfterID != 0 {
+		q = q.Where("id > ?", f.AfterID)
+	}
+	if !f.CreatedFrom.IsZero() {
+		q = q.Where("created_at >= ?", f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		q = q.Where("created_at < ?", f.CreatedTo)
+	}
+	return q
+}
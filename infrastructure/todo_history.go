@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TodoHistory struct {
+	db *gorm.DB
+}
+
+func NewTodoHistory(db *gorm.DB) repository.TodoHistory {
+	return &TodoHistory{
+		db: db,
+	}
+}
+
+func (th *TodoHistory) Create(ctx context.Context, histories ...*model.TodoHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	if err := th.db.WithContext(ctx).CreateInBatches(histories, batchSize).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (th *TodoHistory) FindAll(ctx context.Context, workspaceID int, todoID int, limit int, offset int) ([]*model.TodoHistory, error) {
+	var histories []*model.TodoHistory
+	err := th.db.WithContext(ctx).Where("workspace_id = ? AND todo_id = ?", workspaceID, todoID).
+		Order("id").Limit(limit).Offset(offset).Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+func (th *TodoHistory) Revisions(ctx context.Context, workspaceID int, todoID int) ([]*model.TodoHistory, error) {
+	return th.revisions(th.db.WithContext(ctx), workspaceID, todoID)
+}
+
+func (th *TodoHistory) RevisionsForUpdate(ctx context.Context, workspaceID int, todoID int) ([]*model.TodoHistory, error) {
+	return th.revisions(th.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), workspaceID, todoID)
+}
+
+func (th *TodoHistory) revisions(db *gorm.DB, workspaceID int, todoID int) ([]*model.TodoHistory, error) {
+	var histories []*model.TodoHistory
+	err := db.Where("workspace_id = ? AND todo_id = ?", workspaceID, todoID).Order("id").Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+func (th *TodoHistory) Search(ctx context.Context, workspaceID int, f model.ActivityFilter) ([]*model.TodoHistory, error) {
+	var histories []*model.TodoHistory
+	q := th.db.WithContext(ctx).Where("workspace_id = ?", workspaceID)
+	if f.UserID != 0 {
+		q = q.Where("user_id = ?", f.UserID)
+	}
+	if f.Action != "" {
+		q = q.Where("action = ?", f.Action)
+	}
+	if f.AfterID != 0 {
+		q = q.Where("id > ?", f.AfterID)
+	}
+	if !f.From.IsZero() {
+		q = q.Where("occurred_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		q = q.Where("occurred_at < ?", f.To)
+	}
+	q = q.Order("id DESC")
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	if f.Offset > 0 {
+		q = q.Offset(f.Offset)
+	}
+	if err := q.Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
@@ -0,0 +1,127 @@
+package infrastructure_test
+
+import (
+	"app/domain/model"
+	"app/infrastructure"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTodoHistoryCreate(t *testing.T) {
+	t.Parallel()
+	t.Run("変更された項目だけが記録されること", func(t *testing.T) {
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		before := &model.Todo{ID: 1, WorkspaceID: 1, Task: "task", Status: model.Created}
+		after := &model.Todo{ID: 1, WorkspaceID: 1, Task: "task", Status: model.Done}
+		history := model.NewTodoHistory(model.NewActor(2, 1), model.TodoActionUpdated, before, after, now)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodoHistory(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo_history` (`todo_id`,`workspace_id`,`user_id`,`action`,`changes`,`occurred_at`) VALUES (?,?,?,?,?,?)")).
+			WithArgs(1, 1, 2, model.TodoActionUpdated, `[{"Field":"status","Old":"created","New":"done"}]`, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		if err := repository.Create(context.Background(), history); err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestTodoHistoryFindAll(t *testing.T) {
+	t.Parallel()
+	t.Run("タスクの変更履歴が古い順に取得できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodoHistory(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo_history` WHERE workspace_id = ? AND todo_id = ? ORDER BY id LIMIT 50 OFFSET 10")).
+			WithArgs(1, 3).WillReturnRows(sqlmock.NewRows([]string{"id", "todo_id", "changes"}).
+			AddRow(1, 3, []byte(`[{"Field":"task","Old":"","New":"task"}]`)))
+		got, err := repository.FindAll(context.Background(), 1, 3, 50, 10)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+			return
+		}
+		if len(got) != 1 || len(got[0].Changes) != 1 || got[0].Changes[0].New != "task" {
+			t.Errorf("unexpected histories: %+v", got)
+		}
+	})
+}
+
+func TestTodoHistoryRevisionsForUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("タスクの変更履歴をロックして取得できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodoHistory(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo_history` WHERE workspace_id = ? AND todo_id = ? ORDER BY id FOR UPDATE")).
+			WithArgs(1, 3).WillReturnRows(sqlmock.NewRows([]string{"id", "todo_id"}).AddRow(1, 3))
+		got, err := repository.RevisionsForUpdate(context.Background(), 1, 3)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+			return
+		}
+		if len(got) != 1 {
+			t.Errorf("unexpected histories: %+v", got)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestTodoHistorySearch(t *testing.T) {
+	t.Parallel()
+	t.Run("操作者・期間・操作種別で絞り込めること", func(t *testing.T) {
+		from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodoHistory(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo_history` WHERE workspace_id = ? AND user_id = ? AND action = ? AND occurred_at >= ? AND occurred_at < ? ORDER BY id DESC LIMIT 20")).
+			WithArgs(1, 2, model.TodoActionDeleted, from, to).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Search(context.Background(), 1, model.ActivityFilter{UserID: 2, Action: model.TodoActionDeleted, From: from, To: to, Limit: 20})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("指定したIDより後の履歴に絞り込めること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodoHistory(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo_history` WHERE workspace_id = ? AND id > ? ORDER BY id DESC LIMIT 1000")).
+			WithArgs(1, 10).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Search(context.Background(), 1, model.ActivityFilter{AfterID: 10, Limit: 1000})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type TodoSource struct {
+	db *gorm.DB
+}
+
+func NewTodoSource(db *gorm.DB) repository.TodoSource {
+	return &TodoSource{
+		db: db,
+	}
+}
+
+func (ts *TodoSource) Create(ctx context.Context, sources ...*model.TodoSource) error {
+	if len(sources) == 0 {
+		return nil
+	}
+	if err := ts.db.WithContext(ctx).CreateInBatches(sources, batchSize).Error; err != nil {
+		if isDuplicate(err) {
+			return repository.ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (ts *TodoSource) Relink(ctx context.Context, s *model.TodoSource, from int) (bool, error) {
+	result := ts.db.WithContext(ctx).Model(&model.TodoSource{}).
+		Where("id = ? AND todo_id = ?", s.ID, from).Update("todo_id", s.TodoID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (ts *TodoSource) FindAll(ctx context.Context, workspaceID int, sourceIDs []string) ([]*model.TodoSource, error) {
+	var sources []*model.TodoSource
+	if len(sourceIDs) == 0 {
+		return sources, nil
+	}
+	err := ts.db.WithContext(ctx).Where("workspace_id = ? AND source_id IN ?", workspaceID, sourceIDs).Find(&sources).Error
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (ts *TodoSource) FindByTodoIDs(ctx context.Context, workspaceID int, todoIDs []int) ([]*model.TodoSource, error) {
+	var sources []*model.TodoSource
+	if len(todoIDs) == 0 {
+		return sources, nil
+	}
+	err := ts.db.WithContext(ctx).Where("workspace_id = ? AND todo_id IN ?", workspaceID, todoIDs).Order("id").Find(&sources).Error
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
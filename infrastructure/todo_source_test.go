@@ -0,0 +1,149 @@
+package infrastructure_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/infrastructure"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestTodoSourceCreate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		execErr error
+		err     error
+	}{
+		{
+			name: "正常系_インポート元のIDが1回のINSERTで登録されること",
+		},
+		{
+			name:    "異常系_登録済みのIDの場合ErrDuplicateになること",
+			execErr: &mysql.MySQLError{Number: 1062},
+			err:     repository.ErrDuplicate,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := newDbMock()
+			if err != nil {
+				t.Fatalf("Failed to initialize mock DB: %v", err)
+			}
+			mock.ExpectBegin()
+			exec := mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo_source` (`workspace_id`,`source_id`,`todo_id`) VALUES (?,?,?),(?,?,?)")).
+				WithArgs(1, "trello:a", 10, 1, "trello:b", 11)
+			if tt.execErr != nil {
+				exec.WillReturnError(tt.execErr)
+				mock.ExpectRollback()
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+			}
+			err = infrastructure.NewTodoSource(db).Create(
+				context.Background(), &model.TodoSource{WorkspaceID: 1, SourceID: "trello:a", TodoID: 10},
+				&model.TodoSource{WorkspaceID: 1, SourceID: "trello:b", TodoID: 11},
+			)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestTodoSourceFindAll(t *testing.T) {
+	t.Parallel()
+	t.Run("ワークスペース内の指定したIDだけが検索されること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Fatalf("Failed to initialize mock DB: %v", err)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo_source` WHERE workspace_id = ? AND source_id IN (?,?)")).
+			WithArgs(1, "trello:a", "trello:b").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "source_id", "todo_id"}).AddRow(1, 1, "trello:a", 10))
+		sources, err := infrastructure.NewTodoSource(db).FindAll(context.Background(), 1, []string{"trello:a", "trello:b"})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(sources) != 1 || sources[0].TodoID != 10 {
+			t.Errorf("unexpected sources: %+v", sources)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestTodoSourceRelink(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		affected int64
+		want     bool
+	}{
+		{
+			name:     "元のタスクを指したままのリンクが付け替えられること",
+			affected: 1,
+			want:     true,
+		},
+		{
+			name:     "既に付け替えられていた場合は更新されないこと",
+			affected: 0,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := newDbMock()
+			if err != nil {
+				t.Fatalf("Failed to initialize mock DB: %v", err)
+			}
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `todo_source` SET `todo_id`=? WHERE id = ? AND todo_id = ?")).
+				WithArgs(11, 3, 10).WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			mock.ExpectCommit()
+			got, err := infrastructure.NewTodoSource(db).Relink(context.Background(), &model.TodoSource{ID: 3, TodoID: 11}, 10)
+			if err != nil || got != tt.want {
+				t.Errorf("want = %v %v, got = %v %v", tt.want, nil, got, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestTodoSourceFindByTodoIDs(t *testing.T) {
+	t.Parallel()
+	t.Run("ワークスペース内の指定したタスクのリンクが検索されること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Fatalf("Failed to initialize mock DB: %v", err)
+		}
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo_source` WHERE workspace_id = ? AND todo_id IN (?,?) ORDER BY id")).
+			WithArgs(1, 10, 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "source_id", "todo_id"}).AddRow(1, 1, "caldav:a.ics", 10))
+		sources, err := infrastructure.NewTodoSource(db).FindByTodoIDs(context.Background(), 1, []int{10, 11})
+		if err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(sources) != 1 || sources[0].SourceID != "caldav:a.ics" {
+			t.Errorf("unexpected sources: %+v", sources)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
@@ -3,8 +3,10 @@ package infrastructure_test
 import (
 	"app/domain/model"
 	"app/infrastructure"
+	"context"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"gorm.io/driver/mysql"
@@ -16,7 +18,7 @@ import (
 func TestCreate(t *testing.T) {
 	t.Parallel()
 	t.Run("タスクの登録が行えること", func(t *testing.T) {
-		todo := &model.Todo{Task: "task", Status: model.Created}
+		todo := &model.Todo{WorkspaceID: 1, Task: "task", Status: model.Created}
 		db, mock, err := newDbMock()
 		if err != nil {
 			t.Errorf("Failed to initialize mock DB: %v", err)
@@ -24,19 +26,48 @@ func TestCreate(t *testing.T) {
 		}
 		repository := infrastructure.NewTodo(db)
 		mock.ExpectBegin()
-		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`task`,`status`) VALUES (?,?)")).
-			WithArgs(todo.Task, todo.Status).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`workspace_id`,`list_id`,`task`,`status`) VALUES (?,?,?,?)")).
+			WithArgs(todo.WorkspaceID, todo.ListID, todo.Task, todo.Status).WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
-		err = repository.Create(todo)
+		err = repository.Create(context.Background(), todo)
 		if err != nil {
 			t.Errorf("want = %v, got = %v", nil, err)
 		}
 	})
 }
+func TestCreateBatch(t *testing.T) {
+	t.Parallel()
+	t.Run("複数のタスクが1回のINSERTで登録されIDが設定されること", func(t *testing.T) {
+		todos := []*model.Todo{
+			{WorkspaceID: 1, Task: "task1", Status: model.Created},
+			{WorkspaceID: 1, Task: "task2", Status: model.Created},
+		}
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `todo` (`workspace_id`,`list_id`,`task`,`status`) VALUES (?,?,?,?),(?,?,?,?)")).
+			WithArgs(1, 0, "task1", model.Created, 1, 0, "task2", model.Created).WillReturnResult(sqlmock.NewResult(10, 2))
+		mock.ExpectCommit()
+		err = repository.CreateBatch(context.Background(), todos)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if todos[0].ID != 10 || todos[1].ID != 11 {
+			t.Errorf("want = %v, got = %v", []int{10, 11}, []int{todos[0].ID, todos[1].ID})
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
 func TestUpdate(t *testing.T) {
 	t.Parallel()
 	t.Run("タスクの更新が行えること", func(t *testing.T) {
-		todo := &model.Todo{ID: 1, Task: "task", Status: model.Created}
+		todo := &model.Todo{ID: 1, WorkspaceID: 1, Task: "task", Status: model.Created}
 		db, mock, err := newDbMock()
 		if err != nil {
 			t.Errorf("Failed to initialize mock DB: %v", err)
@@ -44,10 +75,10 @@ func TestUpdate(t *testing.T) {
 		}
 		repository := infrastructure.NewTodo(db)
 		mock.ExpectBegin()
-		mock.ExpectExec(regexp.QuoteMeta("UPDATE `todo` SET `task`=?,`status`=? WHERE `id` = ")).
-			WithArgs(todo.Task, todo.Status, todo.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `todo` SET `workspace_id`=?,`list_id`=?,`task`=?,`status`=? WHERE `id` = ")).
+			WithArgs(todo.WorkspaceID, todo.ListID, todo.Task, todo.Status, todo.ID).WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
-		err = repository.Update(todo)
+		err = repository.Update(context.Background(), todo)
 		if err != nil {
 			t.Errorf("want = %v, got = %v", nil, err)
 		}
@@ -68,7 +99,7 @@ func TestDelete(t *testing.T) {
 		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `todo` WHERE id = ?")).
 			WithArgs(todo.ID).WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
-		err = repository.Delete(todo.ID)
+		err = repository.Delete(context.Background(), todo.ID)
 		if err != nil {
 			t.Errorf("want = %v, got = %v", nil, err)
 		}
@@ -87,13 +118,34 @@ func TestFind(t *testing.T) {
 		repository := infrastructure.NewTodo(db)
 		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE id = ? LIMIT 1")).
 			WithArgs(todo.ID).WillReturnRows(&sqlmock.Rows{})
-		_, err = repository.Find(todo.ID)
+		_, err = repository.Find(context.Background(), todo.ID)
 		if err != nil {
 			t.Errorf("want = %v, got = %v", nil, err)
 		}
 	})
 }
 
+func TestFindForUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("タスクをロックして検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE id = ? LIMIT 1 FOR UPDATE")).
+			WithArgs(1).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.FindForUpdate(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
 func TestFindAll(t *testing.T) {
 	t.Parallel()
 	t.Run("タスクの検索が行えること", func(t *testing.T) {
@@ -103,12 +155,191 @@ func TestFindAll(t *testing.T) {
 			return
 		}
 		repository := infrastructure.NewTodo(db)
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo`")).
-			WillReturnRows(&sqlmock.Rows{})
-		_, err = repository.FindAll()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ?")).
+			WithArgs(1).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.FindAll(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+	t.Run("コンテキストの期限が切れるとクエリが中断されること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ?")).
+			WithArgs(1).WillDelayFor(time.Second).WillReturnRows(&sqlmock.Rows{})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = repository.FindAll(ctx, 1)
+		if err == nil {
+			t.Errorf("want error, got = %v", err)
+		}
+		// クエリの完了を待たずに戻ること
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("want < %v, got = %v", time.Second, elapsed)
+		}
+	})
+}
+
+func TestFindByIDs(t *testing.T) {
+	t.Parallel()
+	t.Run("複数IDのタスクを一度に検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE id IN (?,?)")).
+			WithArgs(1, 2).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.FindByIDs(context.Background(), []int{1, 2})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestFindByIDsForUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("複数IDのタスクをID順にロックして検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE id IN (?,?) ORDER BY id FOR UPDATE")).
+			WithArgs(2, 1).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.FindByIDsForUpdate(context.Background(), []int{2, 1})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+	t.Run("ステータスとページングを指定して検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ? AND status = ? ORDER BY id LIMIT 10 OFFSET 20")).
+			WithArgs(1, model.Done).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Search(context.Background(), 1, model.TodoFilter{Status: model.Done, Limit: 10, Offset: 20})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("リストを指定して検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ? AND list_id = ? ORDER BY id")).
+			WithArgs(1, 3).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Search(context.Background(), 1, model.TodoFilter{ListID: 3})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("指定したIDより後のタスクを検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ? AND id > ? ORDER BY id LIMIT 500")).
+			WithArgs(1, 500).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Search(context.Background(), 1, model.TodoFilter{AfterID: 500, Limit: 500})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("IDと作成日時の範囲を指定して検索が行えること", func(t *testing.T) {
+		from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, 14)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ? AND id IN (?,?) AND created_at >= ? AND created_at < ? ORDER BY id")).
+			WithArgs(1, 1, 2, from, to).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Search(context.Background(), 1, model.TodoFilter{IDs: []int{1, 2}, CreatedFrom: from, CreatedTo: to})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestSearchForUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("条件に該当するタスクをID順にロックして検索できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `todo` WHERE workspace_id = ? AND status = ? ORDER BY id LIMIT 501 FOR UPDATE")).
+			WithArgs(1, model.Done).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.SearchForUpdate(context.Background(), 1, model.TodoFilter{Status: model.Done, Limit: 501})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+	t.Run("タスクの件数が取得できること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewTodo(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `todo` WHERE workspace_id = ?")).
+			WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(3))
+		got, err := repository.Count(context.Background(), 1, model.TodoFilter{})
 		if err != nil {
 			t.Errorf("want = %v, got = %v", nil, err)
 		}
+		if got != 3 {
+			t.Errorf("want = %v, got = %v", 3, got)
+		}
 	})
 }
 
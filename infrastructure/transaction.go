@@ -0,0 +1,37 @@
+package infrastructure
+
+import (
+	"app/domain/repository"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type Transaction struct {
+	db *gorm.DB
+}
+
+func NewTransaction(db *gorm.DB) repository.Transaction {
+	return &Transaction{
+		db: db,
+	}
+}
+
+// Do relies on gorm.DB.Transaction, which rolls back when fn panics and uses
+// a savepoint when it is called on a transaction that is already open.
+func (t *Transaction) Do(ctx context.Context, fn func(r repository.Repositories) error) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(repository.Repositories{
+			Transaction: &Transaction{db: tx},
+			Todo:        NewTodo(tx),
+			List:        NewList(tx),
+			TodoHistory: NewTodoHistory(tx),
+			TodoSource:  NewTodoSource(tx),
+			Outbox:      NewOutbox(tx),
+			Workspace:   NewWorkspace(tx),
+			Member:      NewMember(tx),
+			Invitation:  NewInvitation(tx),
+			Webhook:     NewWebhook(tx),
+		})
+	})
+}
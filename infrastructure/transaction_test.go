@@ -0,0 +1,121 @@
+package infrastructure_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"app/infrastructure"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestTransactionDo(t *testing.T) {
+	t.Parallel()
+	insertTodo := regexp.QuoteMeta("INSERT INTO `todo` (`workspace_id`,`list_id`,`task`,`status`) VALUES (?,?,?,?)")
+	insertOutbox := regexp.QuoteMeta("INSERT INTO `outbox`")
+	t.Run("タスクとイベントが一つのトランザクションで保存されること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(insertTodo).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(insertOutbox).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		err = infrastructure.NewTransaction(db).Do(context.Background(), func(r repository.Repositories) error {
+			todo := model.NewTodo(1, 0, "task")
+			if err := r.Todo.Create(context.Background(), todo); err != nil {
+				return err
+			}
+			return r.Outbox.Store(context.Background(), event.TodoCreated{Todo: *todo})
+		})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("エラーの場合ロールバックされること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(insertTodo).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(insertOutbox).WillReturnError(errors.New("xxxx error"))
+		mock.ExpectRollback()
+		err = infrastructure.NewTransaction(db).Do(context.Background(), func(r repository.Repositories) error {
+			todo := model.NewTodo(1, 0, "task")
+			if err := r.Todo.Create(context.Background(), todo); err != nil {
+				return err
+			}
+			return r.Outbox.Store(context.Background(), event.TodoCreated{Todo: *todo})
+		})
+		if err == nil {
+			t.Errorf("want error, got = %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("panicの場合ロールバックされpanicが伝播すること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(insertTodo).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectRollback()
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("want panic, got = %v", r)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		}()
+		_ = infrastructure.NewTransaction(db).Do(context.Background(), func(r repository.Repositories) error {
+			_ = r.Todo.Create(context.Background(), model.NewTodo(1, 0, "task"))
+			panic("xxxx")
+		})
+	})
+	t.Run("入れ子の場合セーブポイントまでロールバックされること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		mock.ExpectBegin()
+		mock.ExpectExec(insertTodo).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(insertTodo).WillReturnError(errors.New("xxxx error"))
+		mock.ExpectExec("ROLLBACK TO SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		err = infrastructure.NewTransaction(db).Do(context.Background(), func(r repository.Repositories) error {
+			if err := r.Todo.Create(context.Background(), model.NewTodo(1, 0, "task")); err != nil {
+				return err
+			}
+			nested := r.Transaction.Do(context.Background(), func(r repository.Repositories) error {
+				return r.Todo.Create(context.Background(), model.NewTodo(1, 0, "task"))
+			})
+			if nested == nil {
+				t.Errorf("want error, got = %v", nested)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
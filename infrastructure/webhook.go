@@ -0,0 +1,135 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Webhook struct {
+	db *gorm.DB
+}
+
+func NewWebhook(db *gorm.DB) repository.Webhook {
+	return &Webhook{
+		db: db,
+	}
+}
+
+func (wh *Webhook) Create(ctx context.Context, w *model.Webhook) error {
+	if err := wh.db.WithContext(ctx).Create(w).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wh *Webhook) Update(ctx context.Context, w *model.Webhook) error {
+	if err := wh.db.WithContext(ctx).Model(&model.Webhook{}).Where("id = ?", w.ID).
+		Select("url", "events", "active").Updates(w).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wh *Webhook) Delete(ctx context.Context, id int) error {
+	return wh.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).Delete(&model.Webhook{}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (wh *Webhook) Find(ctx context.Context, id int) (*model.Webhook, error) {
+	var webhook *model.Webhook
+	err := wh.db.WithContext(ctx).Where("id = ?", id).Take(&webhook).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (wh *Webhook) FindAll(ctx context.Context, workspaceID int) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	err := wh.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Find(&webhooks).Error
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+type WebhookDelivery struct {
+	db *gorm.DB
+}
+
+func NewWebhookDelivery(db *gorm.DB) repository.WebhookDelivery {
+	return &WebhookDelivery{
+		db: db,
+	}
+}
+
+func (wd *WebhookDelivery) Create(ctx context.Context, d *model.WebhookDelivery) error {
+	if err := wd.db.WithContext(ctx).Create(d).Error; err != nil {
+		if isDuplicate(err) {
+			return repository.ErrDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (wd *WebhookDelivery) Update(ctx context.Context, d *model.WebhookDelivery) error {
+	if err := wd.db.WithContext(ctx).Save(d).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClaimDue selects the delivery with FOR UPDATE like the outbox relay, so a
+// concurrent claim waits for this transaction and then no longer sees it as
+// due.
+func (wd *WebhookDelivery) ClaimDue(ctx context.Context, now time.Time, until time.Time) (*model.WebhookDelivery, error) {
+	var claimed *model.WebhookDelivery
+	err := wd.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var deliveries []*model.WebhookDelivery
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+			Order("next_attempt_at").Order("id").Limit(1).Find(&deliveries).Error
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		d := deliveries[0]
+		if err := tx.Model(&model.WebhookDelivery{}).Where("id = ?", d.ID).Update("next_attempt_at", until).Error; err != nil {
+			return err
+		}
+		d.NextAttemptAt = until
+		claimed = d
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (wd *WebhookDelivery) FindAll(ctx context.Context, webhookID int, limit int, offset int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := wd.db.WithContext(ctx).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var ErrNonPublicAddress = errors.New("webhook URL does not resolve to a public address")
+
+type WebhookSender struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+// NewWebhookSender returns a sender that only connects to public addresses
+// unless allowPrivate is set. The address is checked when the connection is
+// dialed, after the name was resolved, so a name that resolves to a private
+// address after the webhook was registered is rejected as well. Redirects
+// are not followed and proxies from the environment are not used, since
+// either would connect to an address that was not checked.
+func NewWebhookSender(timeout time.Duration, allowPrivate bool) repository.WebhookSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = dialPublicOnly
+	}
+	return &WebhookSender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowPrivate: allowPrivate,
+	}
+}
+
+func dialPublicOnly(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !model.IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Check resolves the host of the URL and rejects it when any of its
+// addresses is not public.
+func (ws *WebhookSender) Check(ctx context.Context, rawURL string) error {
+	if ws.allowPrivate {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, err.Error())
+	}
+	for _, addr := range addrs {
+		if !model.IsPublicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrNonPublicAddress, u.Hostname(), addr.Unmap())
+		}
+	}
+	return nil
+}
+
+func (ws *WebhookSender) Send(ctx context.Context, w *model.Webhook, d *model.WebhookDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-api-sample-todo-webhook")
+	req.Header.Set(WebhookEventHeader, string(d.Event))
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(d.ID))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, "sha256="+model.WebhookSignature(w.Secret, timestamp, d.Payload))
+
+	res, err := ws.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	return res.StatusCode, nil
+}
@@ -0,0 +1,314 @@
+package infrastructure_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/infrastructure"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestWebhookCreate(t *testing.T) {
+	t.Parallel()
+	t.Run("Webhookの登録が行えること", func(t *testing.T) {
+		webhook := model.NewWebhook(1, "https://example.com/hook", model.WebhookEvents{model.TodoCreatedEvent, model.TodoDeletedEvent}, "secret")
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWebhook(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook` (`workspace_id`,`url`,`events`,`secret`,`active`) VALUES (?,?,?,?,?)")).
+			WithArgs(1, webhook.URL, "todo.created,todo.deleted", "secret", true).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		err = repository.Create(context.Background(), webhook)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestWebhookDelete(t *testing.T) {
+	t.Parallel()
+	t.Run("Webhookと配信履歴の削除が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWebhook(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `webhook_delivery` WHERE webhook_id = ?")).
+			WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `webhook` WHERE id = ?")).
+			WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err = repository.Delete(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestWebhookFind(t *testing.T) {
+	t.Parallel()
+	t.Run("購読イベントが復元されること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWebhook(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook` WHERE id = ? LIMIT 1")).
+			WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "workspace_id", "events", "active"}).
+			AddRow(1, 1, "todo.created,todo.updated", true))
+		got, err := repository.Find(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+			return
+		}
+		if len(got.Events) != 2 || got.Events[1] != model.TodoUpdatedEvent {
+			t.Errorf("unexpected events: %v", got.Events)
+		}
+	})
+}
+
+func TestWebhookDeliveryCreate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		execErr error
+		err     error
+	}{
+		{
+			name: "正常系_イベントIDとともに配信が登録されること",
+		},
+		{
+			name:    "異常系_同じイベントの配信が登録済みの場合ErrDuplicateになること",
+			execErr: &mysql.MySQLError{Number: 1062},
+			err:     repository.ErrDuplicate,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			eventID := 7
+			d := model.NewWebhookDelivery(1, model.TodoCreatedEvent, []byte(`{}`), now)
+			d.EventID = &eventID
+			db, mock, err := newDbMock()
+			if err != nil {
+				t.Fatalf("Failed to initialize mock DB: %v", err)
+			}
+			mock.ExpectBegin()
+			exec := mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_delivery` (`webhook_id`,`event_id`,`event`,`payload`,`status`,`attempts`,`next_attempt_at`,`last_status_code`,`last_error`) VALUES (?,?,?,?,?,?,?,?,?)")).
+				WithArgs(1, 7, model.TodoCreatedEvent, sqlmock.AnyArg(), model.DeliveryPending, 0, now, 0, "")
+			if tt.execErr != nil {
+				exec.WillReturnError(tt.execErr)
+				mock.ExpectRollback()
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+			err = infrastructure.NewWebhookDelivery(db).Create(context.Background(), d)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveryClaimDue(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := now.Add(10 * time.Minute)
+	t.Run("送信予定時刻を過ぎた配信をロックして次回の送信予定時刻を延ばせること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWebhookDelivery(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_delivery` WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at,id LIMIT 1 FOR UPDATE")).
+			WithArgs(model.DeliveryPending, now).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "status"}).AddRow(3, 1, model.DeliveryPending))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_delivery` SET `next_attempt_at`=? WHERE id = ?")).
+			WithArgs(until, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		got, err := repository.ClaimDue(context.Background(), now, until)
+		if err != nil || got == nil || got.ID != 3 || !got.NextAttemptAt.Equal(until) {
+			t.Errorf("want = %v %v, got = %+v %v", 3, nil, got, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+	t.Run("送信予定の配信がない場合nilが返ること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWebhookDelivery(db)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_delivery` WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at,id LIMIT 1 FOR UPDATE")).
+			WithArgs(model.DeliveryPending, now).WillReturnRows(&sqlmock.Rows{})
+		mock.ExpectCommit()
+		got, err := repository.ClaimDue(context.Background(), now, until)
+		if err != nil || got != nil {
+			t.Errorf("want = %v %v, got = %v %v", nil, nil, got, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestWebhookSenderSend(t *testing.T) {
+	t.Parallel()
+	t.Run("署名付きで送信されること", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		payload := []byte(`{"Event":"todo.created"}`)
+		var header http.Header
+		var body []byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		sender := infrastructure.NewWebhookSender(time.Second, true)
+		webhook := &model.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}
+		delivery := &model.WebhookDelivery{ID: 7, Event: model.TodoCreatedEvent, Payload: payload}
+		code, err := sender.Send(context.Background(), webhook, delivery, now)
+		if err != nil || code != http.StatusNoContent {
+			t.Fatalf("want = %v, got = %v %v", http.StatusNoContent, code, err)
+		}
+		if string(body) != string(payload) {
+			t.Errorf("want = %s, got = %s", payload, body)
+		}
+		want := "sha256=" + model.WebhookSignature("secret", now.Unix(), payload)
+		if got := header.Get(infrastructure.WebhookSignatureHeader); got != want {
+			t.Errorf("want = %v, got = %v", want, got)
+		}
+		if got := header.Get(infrastructure.WebhookTimestampHeader); got != strconv.FormatInt(now.Unix(), 10) {
+			t.Errorf("want = %v, got = %v", now.Unix(), got)
+		}
+		if got := header.Get(infrastructure.WebhookDeliveryHeader); got != "7" {
+			t.Errorf("want = %v, got = %v", 7, got)
+		}
+	})
+}
+
+func TestWebhookSenderPrivateNetworks(t *testing.T) {
+	t.Parallel()
+	t.Run("プライベートネットワークへの送信が拒否されること", func(t *testing.T) {
+		var called bool
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer receiver.Close()
+
+		sender := infrastructure.NewWebhookSender(time.Second, false)
+		webhook := &model.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}
+		delivery := &model.WebhookDelivery{ID: 7, Event: model.Ping, Payload: []byte("{}")}
+		_, err := sender.Send(context.Background(), webhook, delivery, time.Now())
+		if !errors.Is(err, infrastructure.ErrNonPublicAddress) {
+			t.Errorf("want = %v, got = %v", infrastructure.ErrNonPublicAddress, err)
+		}
+		if called {
+			t.Errorf("want the receiver not to be called")
+		}
+	})
+	t.Run("リダイレクトを辿らないこと", func(t *testing.T) {
+		var redirected bool
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			redirected = true
+		}))
+		defer target.Close()
+		receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer receiver.Close()
+
+		sender := infrastructure.NewWebhookSender(time.Second, true)
+		webhook := &model.Webhook{ID: 1, URL: receiver.URL, Secret: "secret"}
+		delivery := &model.WebhookDelivery{ID: 7, Event: model.Ping, Payload: []byte("{}")}
+		code, err := sender.Send(context.Background(), webhook, delivery, time.Now())
+		if err != nil || code != http.StatusTemporaryRedirect {
+			t.Errorf("want = %v, got = %v %v", http.StatusTemporaryRedirect, code, err)
+		}
+		if redirected {
+			t.Errorf("want the redirect not to be followed")
+		}
+	})
+}
+
+func TestWebhookSenderCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{
+			name: "正常系_公開アドレスは許可されること",
+			url:  "https://93.184.216.34/hook",
+		},
+		{
+			name:    "異常系_ループバックアドレスは拒否されること",
+			url:     "http://127.0.0.1:8080/hook",
+			wantErr: infrastructure.ErrNonPublicAddress,
+		},
+		{
+			name:    "異常系_メタデータのリンクローカルアドレスは拒否されること",
+			url:     "http://169.254.169.254/latest/meta-data",
+			wantErr: infrastructure.ErrNonPublicAddress,
+		},
+		{
+			name:    "異常系_プライベートアドレスは拒否されること",
+			url:     "http://10.0.0.1/hook",
+			wantErr: infrastructure.ErrNonPublicAddress,
+		},
+		{
+			name:    "異常系_IPv4射影アドレスは拒否されること",
+			url:     "http://[::ffff:127.0.0.1]/hook",
+			wantErr: infrastructure.ErrNonPublicAddress,
+		},
+		{
+			name:    "異常系_ループバックに解決される名前は拒否されること",
+			url:     "http://localhost/hook",
+			wantErr: infrastructure.ErrNonPublicAddress,
+		},
+	}
+	sender := infrastructure.NewWebhookSender(time.Second, false)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := sender.Check(context.Background(), tt.url)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want = %v, got = %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package infrastructure
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type Workspace struct {
+	db *gorm.DB
+}
+
+func NewWorkspace(db *gorm.DB) repository.Workspace {
+	return &Workspace{
+		db: db,
+	}
+}
+
+func (ws *Workspace) Create(ctx context.Context, w *model.Workspace) error {
+	if err := ws.db.WithContext(ctx).Create(w).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ws *Workspace) Find(ctx context.Context, id int) (*model.Workspace, error) {
+	var workspace *model.Workspace
+	err := ws.db.WithContext(ctx).Where("id = ?", id).Take(&workspace).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return workspace, nil
+}
+
+type Member struct {
+	db *gorm.DB
+}
+
+func NewMember(db *gorm.DB) repository.Member {
+	return &Member{
+		db: db,
+	}
+}
+
+func (mb *Member) Create(ctx context.Context, m *model.Member) error {
+	if err := mb.db.WithContext(ctx).Create(m).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mb *Member) Update(ctx context.Context, m *model.Member) error {
+	err := mb.db.WithContext(ctx).Model(&model.Member{}).
+		Where("workspace_id = ? AND user_id = ?", m.WorkspaceID, m.UserID).
+		Update("role", m.Role).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mb *Member) Delete(ctx context.Context, workspaceID int, userID int) error {
+	err := mb.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&model.Member{}).Error
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (mb *Member) Find(ctx context.Context, workspaceID int, userID int) (*model.Member, error) {
+	var member *model.Member
+	err := mb.db.WithContext(ctx).Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Take(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (mb *Member) FindAll(ctx context.Context, workspaceID int) ([]*model.Member, error) {
+	var members []*model.Member
+	err := mb.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Find(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+type Invitation struct {
+	db *gorm.DB
+}
+
+func NewInvitation(db *gorm.DB) repository.Invitation {
+	return &Invitation{
+		db: db,
+	}
+}
+
+func (iv *Invitation) Create(ctx context.Context, i *model.Invitation) error {
+	if err := iv.db.WithContext(ctx).Create(i).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (iv *Invitation) UpdateStatus(ctx context.Context, i *model.Invitation, from model.InvitationStatus) (bool, error) {
+	result := iv.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND status = ?", i.ID, from).Update("status", i.Status)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (iv *Invitation) FindByToken(ctx context.Context, token string) (*model.Invitation, error) {
+	var invitation *model.Invitation
+	err := iv.db.WithContext(ctx).Where("token = ?", token).Take(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (iv *Invitation) FindAll(ctx context.Context, workspaceID int) ([]*model.Invitation, error) {
+	var invitations []*model.Invitation
+	err := iv.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
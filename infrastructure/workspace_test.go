@@ -0,0 +1,241 @@
+package infrastructure_test
+
+import (
+	"app/domain/model"
+	"app/infrastructure"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestWorkspaceCreate(t *testing.T) {
+	t.Parallel()
+	t.Run("ワークスペースの登録が行えること", func(t *testing.T) {
+		workspace := &model.Workspace{Name: "workspace"}
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWorkspace(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `workspace` (`name`) VALUES (?)")).
+			WithArgs(workspace.Name).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		err = repository.Create(context.Background(), workspace)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if workspace.ID != 1 {
+			t.Errorf("want = %v, got = %v", 1, workspace.ID)
+		}
+	})
+}
+
+func TestWorkspaceFind(t *testing.T) {
+	t.Parallel()
+	t.Run("ワークスペースの検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewWorkspace(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `workspace` WHERE id = ? LIMIT 1")).
+			WithArgs(1).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.Find(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestMemberCreate(t *testing.T) {
+	t.Parallel()
+	t.Run("メンバーの登録が行えること", func(t *testing.T) {
+		member := model.NewMember(1, 2, model.Editor)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewMember(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `member` (`workspace_id`,`user_id`,`role`) VALUES (?,?,?)")).
+			WithArgs(member.WorkspaceID, member.UserID, member.Role).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err = repository.Create(context.Background(), member)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestMemberUpdate(t *testing.T) {
+	t.Parallel()
+	t.Run("メンバーのロールの更新が行えること", func(t *testing.T) {
+		member := model.NewMember(1, 2, model.Viewer)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewMember(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `member` SET `role`=? WHERE workspace_id = ? AND user_id = ?")).
+			WithArgs(member.Role, member.WorkspaceID, member.UserID).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err = repository.Update(context.Background(), member)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestMemberDelete(t *testing.T) {
+	t.Parallel()
+	t.Run("メンバーの削除が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewMember(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `member` WHERE workspace_id = ? AND user_id = ?")).
+			WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err = repository.Delete(context.Background(), 1, 2)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestMemberFind(t *testing.T) {
+	t.Parallel()
+	t.Run("メンバーの検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewMember(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `member` WHERE workspace_id = ? AND user_id = ? LIMIT 1")).
+			WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "user_id", "role"}).AddRow(1, 2, "viewer"))
+		got, err := repository.Find(context.Background(), 1, 2)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if got == nil || got.Role != model.Viewer {
+			t.Errorf("want = %v, got = %v", model.Viewer, got)
+		}
+	})
+}
+
+func TestMemberFindAll(t *testing.T) {
+	t.Parallel()
+	t.Run("ワークスペースのメンバー一覧の検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewMember(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `member` WHERE workspace_id = ?")).
+			WithArgs(1).WillReturnRows(&sqlmock.Rows{})
+		_, err = repository.FindAll(context.Background(), 1)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestInvitationCreate(t *testing.T) {
+	t.Parallel()
+	t.Run("招待の登録が行えること", func(t *testing.T) {
+		expiresAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		invitation := model.NewInvitation(1, "token", model.Editor, 2, expiresAt)
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewInvitation(db)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `invitation` (`workspace_id`,`token`,`role`,`status`,`invited_by`,`expires_at`) VALUES (?,?,?,?,?,?)")).
+			WithArgs(1, "token", model.Editor, model.Pending, 2, expiresAt).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		err = repository.Create(context.Background(), invitation)
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+	})
+}
+
+func TestInvitationUpdateStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		affected int64
+		want     bool
+	}{
+		{
+			name:     "保留中の招待の状態を更新できること",
+			affected: 1,
+			want:     true,
+		},
+		{
+			name:     "既に状態が変わっていた場合は更新されないこと",
+			affected: 0,
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			db, mock, err := newDbMock()
+			if err != nil {
+				t.Errorf("Failed to initialize mock DB: %v", err)
+				return
+			}
+			repository := infrastructure.NewInvitation(db)
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `invitation` SET `status`=? WHERE id = ? AND status = ?")).
+				WithArgs(model.Accepted, 3, model.Pending).WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			mock.ExpectCommit()
+			got, err := repository.UpdateStatus(context.Background(), &model.Invitation{ID: 3, Status: model.Accepted}, model.Pending)
+			if err != nil || got != tt.want {
+				t.Errorf("want = %v %v, got = %v %v", tt.want, nil, got, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestInvitationFindByToken(t *testing.T) {
+	t.Parallel()
+	t.Run("トークンによる招待の検索が行えること", func(t *testing.T) {
+		db, mock, err := newDbMock()
+		if err != nil {
+			t.Errorf("Failed to initialize mock DB: %v", err)
+			return
+		}
+		repository := infrastructure.NewInvitation(db)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `invitation` WHERE token = ? LIMIT 1")).
+			WithArgs("token").WillReturnRows(&sqlmock.Rows{})
+		got, err := repository.FindByToken(context.Background(), "token")
+		if err != nil {
+			t.Errorf("want = %v, got = %v", nil, err)
+		}
+		if got != nil {
+			t.Errorf("want = %v, got = %v", nil, got)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+CREATE TABLE `workspace` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `name` VARCHAR (128) NOT NULL comment 'ワークスペース名',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `member` (
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `user_id` BIGINT(20) NOT NULL comment 'ユーザーID',
+    `role` VARCHAR(20) NOT NULL comment 'ロール',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`workspace_id`, `user_id`),
+KEY `idx_member_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `invitation` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `token` VARCHAR(64) NOT NULL comment '招待トークン',
+    `role` VARCHAR(20) NOT NULL comment '付与するロール',
+    `status` VARCHAR(20) NOT NULL comment '招待ステータス',
+    `invited_by` BIGINT(20) NOT NULL comment '招待したユーザーID',
+    `expires_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP comment '有効期限',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`id`),
+UNIQUE KEY `uq_invitation_token` (`token`),
+KEY `idx_invitation_workspace_id` (`workspace_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `todo`
+    ADD COLUMN `workspace_id` BIGINT(20) NOT NULL DEFAULT 0 comment 'ワークスペースID' AFTER `id`,
+    ADD KEY `idx_todo_workspace_id` (`workspace_id`);
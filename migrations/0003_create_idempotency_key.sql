@@ -0,0 +1,16 @@
+CREATE TABLE `idempotency_key` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `user_id` BIGINT(20) NOT NULL comment 'ユーザーID',
+    `key` VARCHAR(255) NOT NULL comment 'Idempotency-Keyヘッダーの値',
+    `fingerprint` CHAR(64) NOT NULL comment 'リクエストのハッシュ値',
+    `status` VARCHAR(20) NOT NULL comment '処理ステータス',
+    `response_code` INT NOT NULL DEFAULT 0 comment 'レスポンスのステータスコード',
+    `response_content_type` VARCHAR(255) NOT NULL DEFAULT '' comment 'レスポンスのContent-Type',
+    `response_body` MEDIUMBLOB NULL comment 'レスポンスボディ',
+    `expires_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP comment '有効期限',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`id`),
+UNIQUE KEY `uq_idempotency_key_user_id_key` (`user_id`, `key`),
+KEY `idx_idempotency_key_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,29 @@
+CREATE TABLE `webhook` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `url` VARCHAR(2048) NOT NULL comment '送信先URL',
+    `events` VARCHAR(255) NOT NULL comment '購読するイベント種別（カンマ区切り）',
+    `secret` VARCHAR(255) NOT NULL comment '署名用シークレット',
+    `active` TINYINT(1) NOT NULL DEFAULT 1 comment '有効フラグ',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`id`),
+KEY `idx_webhook_workspace_id` (`workspace_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `webhook_delivery` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `webhook_id` BIGINT(20) NOT NULL comment 'WebhookID',
+    `event` VARCHAR(50) NOT NULL comment 'イベント種別',
+    `payload` MEDIUMBLOB NOT NULL comment '送信するJSON',
+    `status` VARCHAR(20) NOT NULL comment '配信ステータス',
+    `attempts` INT NOT NULL DEFAULT 0 comment '送信試行回数',
+    `next_attempt_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP comment '次回送信日時',
+    `last_status_code` INT NOT NULL DEFAULT 0 comment '最後のレスポンスのステータスコード',
+    `last_error` VARCHAR(1024) NOT NULL DEFAULT '' comment '最後のエラー内容',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`id`),
+KEY `idx_webhook_delivery_webhook_id` (`webhook_id`),
+KEY `idx_webhook_delivery_status_next_attempt_at` (`status`, `next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+CREATE TABLE `outbox` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `name` VARCHAR(50) NOT NULL comment 'イベント名',
+    `payload` MEDIUMBLOB NOT NULL comment 'イベントのJSON',
+    `published_at` timestamp NULL DEFAULT NULL comment '発行日時（未発行はNULL）',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+PRIMARY KEY(`id`),
+KEY `idx_outbox_published_at` (`published_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
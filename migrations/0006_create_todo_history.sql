@@ -0,0 +1,12 @@
+CREATE TABLE `todo_history` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `todo_id` BIGINT(20) NOT NULL comment 'タスクID',
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `user_id` BIGINT(20) NOT NULL comment '変更したユーザーID',
+    `action` VARCHAR(20) NOT NULL comment '操作種別',
+    `changes` JSON NOT NULL comment '変更された項目の変更前後の値',
+    `occurred_at` timestamp(6) NOT NULL comment '変更日時',
+PRIMARY KEY(`id`),
+KEY `idx_todo_history_workspace_id_todo_id` (`workspace_id`, `todo_id`),
+KEY `idx_todo_history_workspace_id_occurred_at` (`workspace_id`, `occurred_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
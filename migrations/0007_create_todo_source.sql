@@ -0,0 +1,9 @@
+CREATE TABLE `todo_source` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `source_id` VARCHAR(255) NOT NULL comment 'インポート元でのID',
+    `todo_id` BIGINT(20) NOT NULL comment 'タスクID',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+PRIMARY KEY(`id`),
+UNIQUE KEY `uq_todo_source_workspace_id_source_id` (`workspace_id`, `source_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
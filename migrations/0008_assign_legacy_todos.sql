@@ -0,0 +1,8 @@
+-- Todos created before workspaces existed were given workspace_id 0, which no
+-- member can read. They have no owner to derive a workspace from, so they are
+-- moved into one workspace named 'Legacy'.
+INSERT INTO `workspace` (`name`)
+    SELECT 'Legacy' FROM DUAL WHERE EXISTS (SELECT 1 FROM `todo` WHERE `workspace_id` = 0);
+
+UPDATE `todo` SET `workspace_id` = (SELECT MAX(`id`) FROM `workspace` WHERE `name` = 'Legacy')
+    WHERE `workspace_id` = 0;
@@ -0,0 +1,13 @@
+CREATE TABLE `list` (
+    `id` BIGINT(20) NOT NULL AUTO_INCREMENT comment 'ID',
+    `workspace_id` BIGINT(20) NOT NULL comment 'ワークスペースID',
+    `name` VARCHAR (128) NOT NULL comment 'リスト名',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP  COMMENT '作成日時',
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+PRIMARY KEY(`id`),
+KEY `idx_list_workspace_id` (`workspace_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `todo`
+    ADD COLUMN `list_id` BIGINT(20) NOT NULL DEFAULT 0 comment 'リストID、リストに属さない場合は0' AFTER `workspace_id`,
+    ADD KEY `idx_todo_workspace_id_list_id` (`workspace_id`, `list_id`);
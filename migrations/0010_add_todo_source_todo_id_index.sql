@@ -0,0 +1,2 @@
+ALTER TABLE `todo_source`
+    ADD KEY `idx_todo_source_workspace_id_todo_id` (`workspace_id`, `todo_id`);
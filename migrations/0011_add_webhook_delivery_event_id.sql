@@ -0,0 +1,3 @@
+ALTER TABLE `webhook_delivery`
+    ADD `event_id` BIGINT(20) NULL DEFAULT NULL comment '配信するoutboxのイベントID（テスト配信はNULL）' AFTER `webhook_id`,
+    ADD UNIQUE KEY `uq_webhook_delivery_webhook_id_event_id` (`webhook_id`, `event_id`);
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+)
+
+func authorize(ctx context.Context, r repository.Member, actor model.Actor, p model.Permission) error {
+	_, err := authorizeMember(ctx, r, actor, p)
+	return err
+}
+
+func authorizeMember(ctx context.Context, r repository.Member, actor model.Actor, p model.Permission) (*model.Member, error) {
+	member, err := r.Find(ctx, actor.WorkspaceID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || !member.Role.Can(p) {
+		return nil, ErrForbidden
+	}
+	return member, nil
+}
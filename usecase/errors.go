@@ -0,0 +1,18 @@
+package usecase
+
+import "errors"
+
+var (
+	ErrForbidden         = errors.New("forbidden")
+	ErrNotFound          = errors.New("not found")
+	ErrConflict          = errors.New("conflict")
+	ErrInvitationExpired = errors.New("invitation expired")
+	ErrRolledBack        = errors.New("rolled back because another operation failed")
+	ErrTooManyMatches    = errors.New("too many todos match the filter")
+	ErrWebhookURL        = errors.New("webhook URL is not allowed")
+	// ErrPreconditionFailed means the todo changed since the client read it.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInFlight = errors.New("a request with the same idempotency key is in progress")
+)
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Idempotency interface {
+	Begin(ctx context.Context, userID int, key string, fingerprint string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, k *model.IdempotencyKey, code int, contentType string, body []byte) error
+	Release(ctx context.Context, k *model.IdempotencyKey) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type idempotency struct {
+	idempotencyKeyRepository repository.IdempotencyKey
+	ttl                      time.Duration
+	lockTimeout              time.Duration
+}
+
+// NewIdempotency keeps completed keys for ttl. lockTimeout releases keys
+// whose request never completed, e.g. because the process crashed while
+// handling it, and has to be longer than any request may run.
+func NewIdempotency(r repository.IdempotencyKey, ttl time.Duration, lockTimeout time.Duration) Idempotency {
+	return &idempotency{r, ttl, lockTimeout}
+}
+
+// Begin reserves the key for a new request. When the key has already been
+// completed the stored response is returned for replay instead.
+func (i *idempotency) Begin(ctx context.Context, userID int, key string, fingerprint string) (*model.IdempotencyKey, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		now := time.Now()
+		k := model.NewIdempotencyKey(userID, key, fingerprint, now.Add(i.ttl))
+		err := i.idempotencyKeyRepository.Create(ctx, k)
+		if err == nil {
+			return k, nil
+		}
+		if !errors.Is(err, repository.ErrDuplicate) {
+			return nil, err
+		}
+
+		existing, err := i.idempotencyKeyRepository.Find(ctx, userID, key)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			continue
+		}
+		if existing.Expired(now) || existing.Status == model.InFlight && now.Sub(existing.CreatedAt) > i.lockTimeout {
+			if err := i.idempotencyKeyRepository.Delete(ctx, existing.ID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if existing.Fingerprint != fingerprint {
+			return nil, ErrIdempotencyKeyMismatch
+		}
+		if existing.Status == model.InFlight {
+			return nil, ErrIdempotencyKeyInFlight
+		}
+		return existing, nil
+	}
+	return nil, ErrIdempotencyKeyInFlight
+}
+
+// Complete stores the response for replay. ErrConflict means the key was
+// taken over by another request, whose response is kept instead.
+func (i *idempotency) Complete(ctx context.Context, k *model.IdempotencyKey, code int, contentType string, body []byte) error {
+	k.Status = model.Completed
+	k.ResponseCode = code
+	k.ResponseContentType = contentType
+	k.ResponseBody = body
+	ok, err := i.idempotencyKeyRepository.Complete(ctx, k)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("idempotency key %q: %w", k.Key, ErrConflict)
+	}
+	return nil
+}
+
+func (i *idempotency) Release(ctx context.Context, k *model.IdempotencyKey) error {
+	if err := i.idempotencyKeyRepository.Delete(ctx, k.ID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (i *idempotency) DeleteExpired(ctx context.Context) error {
+	if err := i.idempotencyKeyRepository.DeleteExpired(ctx, time.Now()); err != nil {
+		return err
+	}
+	return nil
+}
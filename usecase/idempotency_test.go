@@ -0,0 +1,190 @@
+package usecase_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type mockIdempotencyKey struct {
+	repository.IdempotencyKey
+	mockCreate   func() error
+	mockDelete   func() error
+	mockFind     func() (*model.IdempotencyKey, error)
+	mockComplete func() (bool, error)
+}
+
+func (m *mockIdempotencyKey) Create(ctx context.Context, k *model.IdempotencyKey) error {
+	return m.mockCreate()
+}
+func (m *mockIdempotencyKey) Delete(ctx context.Context, id int) error {
+	return m.mockDelete()
+}
+func (m *mockIdempotencyKey) Find(ctx context.Context, userID int, key string) (*model.IdempotencyKey, error) {
+	return m.mockFind()
+}
+func (m *mockIdempotencyKey) Complete(ctx context.Context, k *model.IdempotencyKey) (bool, error) {
+	return m.mockComplete()
+}
+
+func TestIdempotencyBegin(t *testing.T) {
+	t.Parallel()
+	duplicate := func() error {
+		return repository.ErrDuplicate
+	}
+	stored := func(status model.IdempotencyStatus, fingerprint string, createdAt time.Time, expiresAt time.Time) func() (*model.IdempotencyKey, error) {
+		return func() (*model.IdempotencyKey, error) {
+			return &model.IdempotencyKey{
+				ID:          1,
+				Fingerprint: fingerprint,
+				Status:      status,
+				CreatedAt:   createdAt,
+				ExpiresAt:   expiresAt,
+			}, nil
+		}
+	}
+	now := time.Now()
+	tests := []struct {
+		name       string
+		repository repository.IdempotencyKey
+		status     model.IdempotencyStatus
+		err        error
+	}{
+		{
+			name: "正常系_未使用のキーは予約できること",
+			repository: &mockIdempotencyKey{
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			status: model.InFlight,
+			err:    nil,
+		},
+		{
+			name: "正常系_処理済みのキーは保存されたレスポンスが返ること",
+			repository: &mockIdempotencyKey{
+				mockCreate: duplicate,
+				mockFind:   stored(model.Completed, "fp", now, now.Add(time.Hour)),
+			},
+			status: model.Completed,
+			err:    nil,
+		},
+		{
+			name: "異常系_異なるリクエストで同じキーが使われた場合エラーになること",
+			repository: &mockIdempotencyKey{
+				mockCreate: duplicate,
+				mockFind:   stored(model.Completed, "other", now, now.Add(time.Hour)),
+			},
+			err: usecase.ErrIdempotencyKeyMismatch,
+		},
+		{
+			name: "異常系_同じキーのリクエストが処理中の場合エラーになること",
+			repository: &mockIdempotencyKey{
+				mockCreate: duplicate,
+				mockFind:   stored(model.InFlight, "fp", now, now.Add(time.Hour)),
+			},
+			err: usecase.ErrIdempotencyKeyInFlight,
+		},
+		{
+			name: "異常系_処理中のキーは予約期限までは予約できないこと",
+			repository: &mockIdempotencyKey{
+				mockCreate: duplicate,
+				mockFind:   stored(model.InFlight, "fp", now.Add(-2*time.Minute), now.Add(time.Hour)),
+			},
+			err: usecase.ErrIdempotencyKeyInFlight,
+		},
+		{
+			name: "正常系_予約期限を過ぎた処理中のキーは再度予約できること",
+			repository: &mockIdempotencyKey{
+				mockCreate: func() func() error {
+					calls := 0
+					return func() error {
+						calls++
+						if calls == 1 {
+							return repository.ErrDuplicate
+						}
+						return nil
+					}
+				}(),
+				mockFind: stored(model.InFlight, "fp", now.Add(-4*time.Minute), now.Add(time.Hour)),
+				mockDelete: func() error {
+					return nil
+				},
+			},
+			status: model.InFlight,
+			err:    nil,
+		},
+		{
+			name: "正常系_有効期限切れのキーは再度予約できること",
+			repository: &mockIdempotencyKey{
+				mockCreate: func() func() error {
+					calls := 0
+					return func() error {
+						calls++
+						if calls == 1 {
+							return repository.ErrDuplicate
+						}
+						return nil
+					}
+				}(),
+				mockFind: stored(model.Completed, "other", now.Add(-2*time.Hour), now.Add(-time.Hour)),
+				mockDelete: func() error {
+					return nil
+				},
+			},
+			status: model.InFlight,
+			err:    nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewIdempotency(tt.repository, time.Hour, 3*time.Minute)
+
+			got, err := u.Begin(context.Background(), 1, "key", "fp")
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil && got.Status != tt.status {
+				t.Errorf("want = %v, got = %v", tt.status, got.Status)
+			}
+		})
+	}
+}
+
+func TestIdempotencyComplete(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		complete func() (bool, error)
+		err      error
+	}{
+		{
+			name:     "正常系_予約したキーにレスポンスが保存されること",
+			complete: func() (bool, error) { return true, nil },
+		},
+		{
+			name:     "異常系_他のリクエストに予約し直されたキーは上書きされないこと",
+			complete: func() (bool, error) { return false, nil },
+			err:      fmt.Errorf("idempotency key %q: %w", "key", usecase.ErrConflict),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewIdempotency(&mockIdempotencyKey{mockComplete: tt.complete}, time.Hour, 3*time.Minute)
+			k := model.NewIdempotencyKey(1, "key", "fp", time.Now().Add(time.Hour))
+
+			err := u.Complete(context.Background(), k, 201, "application/json", []byte(`{}`))
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// importBatchSize is the number of todos created per transaction, which
+	// is also how often the progress of a job moves.
+	importBatchSize = 100
+	// importJobRetention is how long finished jobs can still be looked up.
+	importJobRetention = 24 * time.Hour
+)
+
+type ImportJobStatus string
+
+const (
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobSucceeded ImportJobStatus = "succeeded"
+	ImportJobFailed    ImportJobStatus = "failed"
+)
+
+// ImportJob is the progress of an import. Of the Processed todos, Created
+// were new and Skipped had been imported before. The batches before a
+// failure stay created, so running the import again picks up where it
+// stopped.
+type ImportJob struct {
+	ID          string
+	WorkspaceID int
+	Source      string
+	Status      ImportJobStatus
+	Total       int
+	Processed   int
+	Created     int
+	Skipped     int
+	Error       string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
+
+// ImportJobs runs imports in the background. Jobs are kept in memory, so
+// they are only visible on the instance that runs them and are lost on
+// restart.
+type ImportJobs interface {
+	Start(ctx context.Context, actor model.Actor, source string, todos []SourcedTodo) (*ImportJob, error)
+	Find(ctx context.Context, actor model.Actor, id string) (*ImportJob, error)
+	// Wait blocks until the running jobs are finished.
+	Wait()
+}
+
+type importJobs struct {
+	todo             Todo
+	memberRepository repository.Member
+
+	mu   sync.Mutex
+	jobs map[string]*ImportJob
+	wg   sync.WaitGroup
+}
+
+func NewImportJobs(t Todo, m repository.Member) ImportJobs {
+	return &importJobs{todo: t, memberRepository: m, jobs: map[string]*ImportJob{}}
+}
+
+func (j *importJobs) Start(ctx context.Context, actor model.Actor, source string, todos []SourcedTodo) (*ImportJob, error) {
+	if err := authorize(ctx, j.memberRepository, actor, model.WriteTodo); err != nil {
+		return nil, err
+	}
+	id, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	job := &ImportJob{
+		ID:          id,
+		WorkspaceID: actor.WorkspaceID,
+		Source:      source,
+		Status:      ImportJobRunning,
+		Total:       len(todos),
+		StartedAt:   now,
+	}
+
+	j.mu.Lock()
+	for id, job := range j.jobs {
+		if job.Status != ImportJobRunning && now.Sub(job.FinishedAt) > importJobRetention {
+			delete(j.jobs, id)
+		}
+	}
+	j.jobs[job.ID] = job
+	res := *job
+	j.mu.Unlock()
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		// The job outlives the request, so it does not run in its context.
+		j.run(context.Background(), actor, job, todos)
+	}()
+	return &res, nil
+}
+
+// run creates the todos batch by batch. Each batch checks the permission of
+// the actor again, so a member who is removed stops their import.
+func (j *importJobs) run(ctx context.Context, actor model.Actor, job *ImportJob, todos []SourcedTodo) {
+	for len(todos) > 0 {
+		n := importBatchSize
+		if n > len(todos) {
+			n = len(todos)
+		}
+		created, err := j.todo.ImportFrom(ctx, actor, todos[:n])
+		j.mu.Lock()
+		if err != nil {
+			job.Status = ImportJobFailed
+			job.Error = err.Error()
+			job.FinishedAt = time.Now()
+			j.mu.Unlock()
+			return
+		}
+		job.Processed += n
+		job.Created += created
+		job.Skipped += n - created
+		j.mu.Unlock()
+		todos = todos[n:]
+	}
+	j.mu.Lock()
+	job.Status = ImportJobSucceeded
+	job.FinishedAt = time.Now()
+	j.mu.Unlock()
+}
+
+func (j *importJobs) Find(ctx context.Context, actor model.Actor, id string) (*ImportJob, error) {
+	if err := authorize(ctx, j.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok || job.WorkspaceID != actor.WorkspaceID {
+		return nil, ErrNotFound
+	}
+	res := *job
+	return &res, nil
+}
+
+func (j *importJobs) Wait() {
+	j.wg.Wait()
+}
@@ -0,0 +1,111 @@
+package usecase_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// importingTodo はバッチごとに ImportFrom を呼び出し、呼ばれた件数を記録する
+type importingTodo struct {
+	usecase.Todo
+	mockImportFrom func(batch int, todos []usecase.SourcedTodo) (int, error)
+	batches        []int
+}
+
+func (m *importingTodo) ImportFrom(ctx context.Context, actor model.Actor, todos []usecase.SourcedTodo) (int, error) {
+	m.batches = append(m.batches, len(todos))
+	return m.mockImportFrom(len(m.batches), todos)
+}
+
+func TestImportJobs(t *testing.T) {
+	t.Parallel()
+	todos := make([]usecase.SourcedTodo, 250)
+	for i := range todos {
+		todos[i] = usecase.SourcedTodo{SourceID: fmt.Sprintf("todoist:%d", i), Todo: &model.Todo{Task: "task"}}
+	}
+	tests := []struct {
+		name     string
+		member   repository.Member
+		todo     *importingTodo
+		expected *usecase.ImportJob
+		batches  []int
+		err      error
+	}{
+		{
+			name:   "正常系_バッチごとに登録され進捗が集計されること",
+			member: memberOf(model.Editor),
+			todo: &importingTodo{mockImportFrom: func(batch int, todos []usecase.SourcedTodo) (int, error) {
+				// 最初のバッチは10件がインポート済み
+				if batch == 1 {
+					return len(todos) - 10, nil
+				}
+				return len(todos), nil
+			}},
+			expected: &usecase.ImportJob{WorkspaceID: 1, Source: "todoist", Status: usecase.ImportJobSucceeded, Total: 250, Processed: 250, Created: 240, Skipped: 10},
+			batches:  []int{100, 100, 50},
+		},
+		{
+			name:   "正常系_失敗したバッチで止まりエラーが記録されること",
+			member: memberOf(model.Editor),
+			todo: &importingTodo{mockImportFrom: func(batch int, todos []usecase.SourcedTodo) (int, error) {
+				if batch == 2 {
+					return 0, usecase.ErrForbidden
+				}
+				return len(todos), nil
+			}},
+			expected: &usecase.ImportJob{WorkspaceID: 1, Source: "todoist", Status: usecase.ImportJobFailed, Total: 250, Processed: 100, Created: 100, Error: "forbidden"},
+			batches:  []int{100, 100},
+		},
+		{
+			name:   "異常系_閲覧者は開始できないこと",
+			member: memberOf(model.Viewer),
+			todo:   &importingTodo{},
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewImportJobs(tt.todo, tt.member)
+			started, err := u.Start(context.Background(), actor, "todoist", todos)
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if started.Status != usecase.ImportJobRunning || started.ID == "" {
+				t.Errorf("unexpected job: %+v", started)
+			}
+			u.Wait()
+
+			job, err := u.Find(context.Background(), actor, started.ID)
+			if err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+			opts := cmpopts.IgnoreFields(usecase.ImportJob{}, "ID", "StartedAt", "FinishedAt")
+			if !cmp.Equal(job, tt.expected, opts) {
+				t.Errorf("diff %s", cmp.Diff(job, tt.expected, opts))
+			}
+			if job.FinishedAt.IsZero() {
+				t.Errorf("FinishedAt is not set")
+			}
+			if !cmp.Equal(tt.todo.batches, tt.batches) {
+				t.Errorf("diff %s", cmp.Diff(tt.todo.batches, tt.batches))
+			}
+			// 別のワークスペースからは見えない
+			if _, err := u.Find(context.Background(), model.NewActor(1, 2), started.ID); !errors.Is(err, usecase.ErrNotFound) {
+				t.Errorf("want = %v, got = %v", usecase.ErrNotFound, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+type Invitation interface {
+	Create(ctx context.Context, actor model.Actor, role model.Role) (*model.Invitation, error)
+	FindAll(ctx context.Context, actor model.Actor) ([]*model.Invitation, error)
+	Accept(ctx context.Context, userID int, token string) error
+	Decline(ctx context.Context, token string) error
+	Revoke(ctx context.Context, userID int, token string) error
+}
+
+type invitation struct {
+	invitationRepository repository.Invitation
+	memberRepository     repository.Member
+	transaction          repository.Transaction
+}
+
+func NewInvitation(i repository.Invitation, m repository.Member, t repository.Transaction) Invitation {
+	return &invitation{i, m, t}
+}
+
+func (iv *invitation) Create(ctx context.Context, actor model.Actor, role model.Role) (*model.Invitation, error) {
+	operator, err := authorizeMember(ctx, iv.memberRepository, actor, model.ManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if role == model.Owner && !operator.Role.Can(model.ManageOwners) {
+		return nil, ErrForbidden
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	invitation := model.NewInvitation(actor.WorkspaceID, token, role, actor.UserID, time.Now().Add(invitationTTL))
+	if err := iv.invitationRepository.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (iv *invitation) FindAll(ctx context.Context, actor model.Actor) ([]*model.Invitation, error) {
+	if err := authorize(ctx, iv.memberRepository, actor, model.ManageMembers); err != nil {
+		return nil, err
+	}
+	invitations, err := iv.invitationRepository.FindAll(ctx, actor.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Accept claims the invitation and adds the member in one transaction, so
+// two concurrent accepts of the same token cannot both add a member.
+func (iv *invitation) Accept(ctx context.Context, userID int, token string) error {
+	return iv.transaction.Do(ctx, func(r repository.Repositories) error {
+		invitation, err := findPendingInvitation(ctx, r.Invitation, token)
+		if err != nil {
+			return err
+		}
+		if err := updateInvitationStatus(ctx, r.Invitation, invitation, model.Accepted); err != nil {
+			return err
+		}
+		member, err := r.Member.Find(ctx, invitation.WorkspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if member != nil {
+			return ErrConflict
+		}
+		if err := r.Member.Create(ctx, model.NewMember(invitation.WorkspaceID, userID, invitation.Role)); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (iv *invitation) Decline(ctx context.Context, token string) error {
+	invitation, err := findPendingInvitation(ctx, iv.invitationRepository, token)
+	if err != nil {
+		return err
+	}
+	return updateInvitationStatus(ctx, iv.invitationRepository, invitation, model.Declined)
+}
+
+func (iv *invitation) Revoke(ctx context.Context, userID int, token string) error {
+	invitation, err := iv.invitationRepository.FindByToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if invitation == nil {
+		return ErrNotFound
+	}
+	actor := model.NewActor(userID, invitation.WorkspaceID)
+	if err := authorize(ctx, iv.memberRepository, actor, model.ManageMembers); err != nil {
+		return err
+	}
+	if invitation.Status != model.Pending {
+		return ErrConflict
+	}
+	return updateInvitationStatus(ctx, iv.invitationRepository, invitation, model.Revoked)
+}
+
+// updateInvitationStatus moves a pending invitation to status. It fails with
+// ErrConflict when the invitation was accepted, declined or revoked after it
+// was read.
+func updateInvitationStatus(ctx context.Context, r repository.Invitation, invitation *model.Invitation, status model.InvitationStatus) error {
+	invitation.Status = status
+	ok, err := r.UpdateStatus(ctx, invitation, model.Pending)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrConflict
+	}
+	return nil
+}
+
+func findPendingInvitation(ctx context.Context, r repository.Invitation, token string) (*model.Invitation, error) {
+	invitation, err := r.FindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, ErrNotFound
+	}
+	if invitation.Status != model.Pending {
+		return nil, ErrConflict
+	}
+	if invitation.Expired(time.Now()) {
+		return nil, ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
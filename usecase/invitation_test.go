@@ -0,0 +1,224 @@
+package usecase_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"testing"
+	"time"
+)
+
+type mockInvitation struct {
+	repository.Invitation
+	mockCreate      func() error
+	mockUpdate      func(i *model.Invitation) (bool, error)
+	mockFindByToken func() (*model.Invitation, error)
+	// stale は別のリクエストが先に状態を変更した場合を表す
+	stale bool
+}
+
+func (m *mockInvitation) Create(ctx context.Context, i *model.Invitation) error {
+	return m.mockCreate()
+}
+func (m *mockInvitation) UpdateStatus(ctx context.Context, i *model.Invitation, from model.InvitationStatus) (bool, error) {
+	return m.mockUpdate(i)
+}
+
+func invitationTransaction(i repository.Invitation, m repository.Member) *mockTransaction {
+	return &mockTransaction{repository.Repositories{Invitation: i, Member: m}}
+}
+func (m *mockInvitation) FindByToken(ctx context.Context, token string) (*model.Invitation, error) {
+	return m.mockFindByToken()
+}
+
+func TestInvitationCreate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		role   model.Role
+		member repository.Member
+		err    error
+	}{
+		{
+			name:   "正常系_管理者は招待を作成できること",
+			role:   model.Editor,
+			member: memberOf(model.Admin),
+			err:    nil,
+		},
+		{
+			name:   "異常系_編集者は招待を作成できないこと",
+			role:   model.Viewer,
+			member: memberOf(model.Editor),
+			err:    usecase.ErrForbidden,
+		},
+		{
+			name:   "異常系_管理者はオーナーとして招待できないこと",
+			role:   model.Owner,
+			member: memberOf(model.Admin),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := &mockInvitation{
+				mockCreate: func() error {
+					return nil
+				},
+			}
+			u := usecase.NewInvitation(r, tt.member, invitationTransaction(r, tt.member))
+
+			got, err := u.Create(context.Background(), actor, tt.role)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil && (got.Token == "" || got.Status != model.Pending || !got.ExpiresAt.After(time.Now())) {
+				t.Errorf("unexpected invitation %+v", got)
+			}
+		})
+	}
+}
+
+func TestInvitationAccept(t *testing.T) {
+	t.Parallel()
+	pending := func(expiresAt time.Time) func() (*model.Invitation, error) {
+		return func() (*model.Invitation, error) {
+			return model.NewInvitation(1, "token", model.Editor, 1, expiresAt), nil
+		}
+	}
+	tests := []struct {
+		name       string
+		repository *mockInvitation
+		member     repository.Member
+		err        error
+	}{
+		{
+			name: "正常系_招待を承諾するとメンバーになること",
+			repository: &mockInvitation{
+				mockFindByToken: pending(time.Now().Add(time.Hour)),
+			},
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return nil, nil
+				},
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "異常系_有効期限切れの招待は承諾できないこと",
+			repository: &mockInvitation{
+				mockFindByToken: pending(time.Now().Add(-time.Hour)),
+			},
+			err: usecase.ErrInvitationExpired,
+		},
+		{
+			name: "異常系_取り消された招待は承諾できないこと",
+			repository: &mockInvitation{
+				mockFindByToken: func() (*model.Invitation, error) {
+					i := model.NewInvitation(1, "token", model.Editor, 1, time.Now().Add(time.Hour))
+					i.Status = model.Revoked
+					return i, nil
+				},
+			},
+			err: usecase.ErrConflict,
+		},
+		{
+			name: "異常系_存在しない招待は承諾できないこと",
+			repository: &mockInvitation{
+				mockFindByToken: func() (*model.Invitation, error) {
+					return nil, nil
+				},
+			},
+			err: usecase.ErrNotFound,
+		},
+		{
+			name: "異常系_同時に承諾された招待は承諾できないこと",
+			repository: &mockInvitation{
+				mockFindByToken: pending(time.Now().Add(time.Hour)),
+				stale:           true,
+			},
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return nil, nil
+				},
+			},
+			err: usecase.ErrConflict,
+		},
+		{
+			name: "異常系_既にメンバーの場合は承諾できないこと",
+			repository: &mockInvitation{
+				mockFindByToken: pending(time.Now().Add(time.Hour)),
+			},
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var updated *model.Invitation
+			tt.repository.mockUpdate = func(i *model.Invitation) (bool, error) {
+				updated = i
+				return !tt.repository.stale, nil
+			}
+			u := usecase.NewInvitation(tt.repository, tt.member, invitationTransaction(tt.repository, tt.member))
+
+			err := u.Accept(context.Background(), 2, "token")
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil && updated.Status != model.Accepted {
+				t.Errorf("want = %v, got = %v", model.Accepted, updated.Status)
+			}
+		})
+	}
+}
+
+func TestInvitationRevoke(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		member repository.Member
+		err    error
+	}{
+		{
+			name:   "正常系_管理者は招待を取り消せること",
+			member: memberOf(model.Admin),
+			err:    nil,
+		},
+		{
+			name:   "異常系_閲覧者は招待を取り消せないこと",
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := &mockInvitation{
+				mockFindByToken: func() (*model.Invitation, error) {
+					return model.NewInvitation(1, "token", model.Editor, 1, time.Now().Add(time.Hour)), nil
+				},
+				mockUpdate: func(i *model.Invitation) (bool, error) {
+					if i.Status != model.Revoked {
+						t.Errorf("want = %v, got = %v", model.Revoked, i.Status)
+					}
+					return true, nil
+				},
+			}
+			u := usecase.NewInvitation(r, tt.member, invitationTransaction(r, tt.member))
+
+			err := u.Revoke(context.Background(), 1, "token")
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+		})
+	}
+}
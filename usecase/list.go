@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"fmt"
+)
+
+type List interface {
+	Create(ctx context.Context, actor model.Actor, name string) (*model.List, error)
+	Update(ctx context.Context, actor model.Actor, id int, name string) error
+	// Delete fails with ErrConflict while todos are still in the list.
+	Delete(ctx context.Context, actor model.Actor, id int) error
+	Find(ctx context.Context, actor model.Actor, id int) (*model.List, error)
+	FindAll(ctx context.Context, actor model.Actor) ([]*model.List, error)
+}
+
+type list struct {
+	listRepository   repository.List
+	memberRepository repository.Member
+	transaction      repository.Transaction
+}
+
+func NewList(l repository.List, m repository.Member, tx repository.Transaction) List {
+	return &list{l, m, tx}
+}
+
+func (l *list) Create(ctx context.Context, actor model.Actor, name string) (*model.List, error) {
+	if err := authorize(ctx, l.memberRepository, actor, model.WriteTodo); err != nil {
+		return nil, err
+	}
+	list := model.NewList(actor.WorkspaceID, name)
+	if err := l.listRepository.Create(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (l *list) Update(ctx context.Context, actor model.Actor, id int, name string) error {
+	if err := authorize(ctx, l.memberRepository, actor, model.WriteTodo); err != nil {
+		return err
+	}
+	list, err := l.findInWorkspace(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	list.Name = name
+	if err := l.listRepository.Update(ctx, list); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete locks the list before it counts the todos in it, so a todo added
+// concurrently either is counted or fails to find the list.
+func (l *list) Delete(ctx context.Context, actor model.Actor, id int) error {
+	if err := authorize(ctx, l.memberRepository, actor, model.WriteTodo); err != nil {
+		return err
+	}
+	return l.transaction.Do(ctx, func(r repository.Repositories) error {
+		if err := lockList(ctx, r.List, actor, id); err != nil {
+			return err
+		}
+		n, err := r.Todo.Count(ctx, actor.WorkspaceID, model.TodoFilter{ListID: id})
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("%w: the list still has %d todos", ErrConflict, n)
+		}
+		return r.List.Delete(ctx, id)
+	})
+}
+
+func (l *list) Find(ctx context.Context, actor model.Actor, id int) (*model.List, error) {
+	if err := authorize(ctx, l.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	return l.findInWorkspace(ctx, actor, id)
+}
+
+func (l *list) FindAll(ctx context.Context, actor model.Actor) ([]*model.List, error) {
+	if err := authorize(ctx, l.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	lists, err := l.listRepository.FindAll(ctx, actor.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (l *list) findInWorkspace(ctx context.Context, actor model.Actor, id int) (*model.List, error) {
+	list, err := l.listRepository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil || list.WorkspaceID != actor.WorkspaceID {
+		return nil, ErrNotFound
+	}
+	return list, nil
+}
@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// mockList は削除されたリストのIDを記録する
+type mockList struct {
+	repository.List
+	mockFind func() (*model.List, error)
+	lists    []*model.List
+	created  []*model.List
+	deleted  []int
+}
+
+func (m *mockList) Create(ctx context.Context, l *model.List) error {
+	l.ID = 1 + len(m.created)
+	m.created = append(m.created, l)
+	return nil
+}
+func (m *mockList) FindAll(ctx context.Context, workspaceID int) ([]*model.List, error) {
+	return m.lists, nil
+}
+func (m *mockList) Delete(ctx context.Context, id int) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+func (m *mockList) Find(ctx context.Context, id int) (*model.List, error) {
+	return m.mockFind()
+}
+func (m *mockList) FindForUpdate(ctx context.Context, id int) (*model.List, error) {
+	return m.mockFind()
+}
+
+func findList() (*model.List, error) {
+	return &model.List{ID: 3, WorkspaceID: actor.WorkspaceID, Name: "買い物"}, nil
+}
+
+func TestListCreate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		member repository.Member
+		err    error
+	}{
+		{
+			name:   "正常系_編集者はリストを作成できること",
+			member: memberOf(model.Editor),
+		},
+		{
+			name:   "異常系_閲覧者はリストを作成できないこと",
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewList(&mockList{}, tt.member, &mockTransaction{})
+
+			got, err := u.Create(context.Background(), actor, "買い物")
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil && (got.WorkspaceID != actor.WorkspaceID || got.Name != "買い物") {
+				t.Errorf("unexpected list %+v", got)
+			}
+		})
+	}
+}
+
+func TestListDelete(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		find        func() (*model.List, error)
+		count       int64
+		member      repository.Member
+		err         error
+		wantDeleted bool
+	}{
+		{
+			name:        "正常系_空のリストを削除できること",
+			find:        findList,
+			member:      memberOf(model.Editor),
+			wantDeleted: true,
+		},
+		{
+			name:   "異常系_タスクが残っているリストは削除できないこと",
+			find:   findList,
+			count:  2,
+			member: memberOf(model.Editor),
+			err:    fmt.Errorf("%w: the list still has 2 todos", usecase.ErrConflict),
+		},
+		{
+			name: "異常系_他のワークスペースのリストは削除できないこと",
+			find: func() (*model.List, error) {
+				return &model.List{ID: 3, WorkspaceID: 2}, nil
+			},
+			member: memberOf(model.Editor),
+			err:    fmt.Errorf("list 3: %w", usecase.ErrNotFound),
+		},
+		{
+			name:   "異常系_閲覧者はリストを削除できないこと",
+			find:   findList,
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lists := &mockList{mockFind: tt.find}
+			todos := &mockTodo{mockCount: func() (int64, error) { return tt.count, nil }}
+			tx := &mockTransaction{repository.Repositories{List: lists, Todo: todos}}
+			u := usecase.NewList(lists, tt.member, tx)
+
+			err := u.Delete(context.Background(), actor, 3)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if deleted := len(lists.deleted) == 1; deleted != tt.wantDeleted {
+				t.Errorf("want deleted = %v, got = %v", tt.wantDeleted, lists.deleted)
+			}
+		})
+	}
+}
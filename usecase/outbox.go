@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	outboxRelayBatch = 100
+	// outboxGapTimeout is how long Tail waits for a missing message ID
+	// before it moves past it. IDs are allocated when a message is stored,
+	// so a lower ID can still be committed after a higher one; a gap that
+	// stays longer belongs to a rolled back transaction.
+	outboxGapTimeout = 10 * time.Second
+)
+
+type Outbox interface {
+	// Relay hands the pending messages to the relay handler once across
+	// all instances.
+	Relay(ctx context.Context) error
+	// Tail publishes the messages stored since its last run to the
+	// subscribers of this instance, whichever instance relays them.
+	Tail(ctx context.Context) error
+	DeletePublished(ctx context.Context) error
+}
+
+// OutboxHandler handles the event of the outbox message with id. It may be
+// called again for the same message when the relay could not mark it as
+// published, so it has to be idempotent.
+type OutboxHandler func(ctx context.Context, id int, e event.Event) error
+
+type outbox struct {
+	outboxRepository repository.Outbox
+	relay            OutboxHandler
+	tail             event.Publisher
+	retention        time.Duration
+
+	mu      sync.Mutex
+	tailing bool
+	// tailedID is the last message Tail has passed and gapSince when it
+	// first found the message after it missing.
+	tailedID int
+	gapSince time.Time
+}
+
+func NewOutbox(o repository.Outbox, relay OutboxHandler, tail event.Publisher, retention time.Duration) Outbox {
+	return &outbox{outboxRepository: o, relay: relay, tail: tail, retention: retention}
+}
+
+// Relay hands the pending outbox messages in order to the relay handler
+// until the outbox is drained. A message is marked as published only after
+// the handler accepted it, so delivery is at least once. Messages that
+// cannot be decoded are skipped rather than blocking the ones after them.
+func (o *outbox) Relay(ctx context.Context) error {
+	for {
+		var skipped []error
+		n, err := o.outboxRepository.Relay(ctx, outboxRelayBatch, time.Now(), func(messages []*model.Outbox) (int, error) {
+			for i, m := range messages {
+				e, err := event.Decode(m.Name, m.Payload)
+				if err != nil {
+					skipped = append(skipped, fmt.Errorf("outbox message %d: %w", m.ID, err))
+					continue
+				}
+				if err := o.relay(ctx, m.ID, e); err != nil {
+					return i, err
+				}
+			}
+			return len(messages), nil
+		})
+		if err != nil || len(skipped) > 0 {
+			return errors.Join(append(skipped, err)...)
+		}
+		if n < outboxRelayBatch {
+			return nil
+		}
+	}
+}
+
+// Tail publishes the messages stored after the last one it passed, in
+// order and whether or not they were relayed yet. The first run only
+// starts at the end of the outbox. Delivery is at most once: a message
+// committed after Tail moved past its gap is not published.
+func (o *outbox) Tail(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.tailing {
+		id, err := o.outboxRepository.LastID(ctx)
+		if err != nil {
+			return err
+		}
+		o.tailedID, o.tailing = id, true
+		return nil
+	}
+	for {
+		messages, err := o.outboxRepository.FindAfter(ctx, o.tailedID, outboxRelayBatch)
+		if err != nil {
+			return err
+		}
+		var skipped []error
+		for _, m := range messages {
+			if m.ID != o.tailedID+1 {
+				now := time.Now()
+				if o.gapSince.IsZero() {
+					o.gapSince = now
+				}
+				if now.Sub(o.gapSince) < outboxGapTimeout {
+					return errors.Join(skipped...)
+				}
+			}
+			o.gapSince = time.Time{}
+			e, err := event.Decode(m.Name, m.Payload)
+			if err != nil {
+				skipped = append(skipped, fmt.Errorf("outbox message %d: %w", m.ID, err))
+			} else if err := o.tail.Publish(ctx, e); err != nil {
+				return errors.Join(append(skipped, err)...)
+			}
+			o.tailedID = m.ID
+		}
+		if len(skipped) > 0 || len(messages) < outboxRelayBatch {
+			return errors.Join(skipped...)
+		}
+	}
+}
+
+func (o *outbox) DeletePublished(ctx context.Context) error {
+	if err := o.outboxRepository.DeletePublishedBefore(ctx, time.Now().Add(-o.retention)); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package usecase_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mockOutbox は保存されたイベントを記録し、保持しているメッセージを発行関数に渡して発行済みになった件数を記録する
+type mockOutbox struct {
+	repository.Outbox
+	stored    []event.Event
+	messages  []*model.Outbox
+	published int
+}
+
+func (m *mockOutbox) Store(ctx context.Context, events ...event.Event) error {
+	m.stored = append(m.stored, events...)
+	return nil
+}
+
+func (m *mockOutbox) Relay(ctx context.Context, limit int, now time.Time, publish func(messages []*model.Outbox) (int, error)) (int, error) {
+	n, err := publish(m.messages)
+	m.published += n
+	return n, err
+}
+
+func (m *mockOutbox) FindAfter(ctx context.Context, id int, limit int) ([]*model.Outbox, error) {
+	var messages []*model.Outbox
+	for _, msg := range m.messages {
+		if msg.ID > id && len(messages) < limit {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func (m *mockOutbox) LastID(ctx context.Context) (int, error) {
+	if len(m.messages) == 0 {
+		return 0, nil
+	}
+	return m.messages[len(m.messages)-1].ID, nil
+}
+
+// mockOutboxHandler は渡されたメッセージのIDとイベントを記録し、指定したイベント名の処理に失敗する
+type mockOutboxHandler struct {
+	ids    []int
+	events []event.Event
+	fail   string
+}
+
+func (m *mockOutboxHandler) Handle(ctx context.Context, id int, e event.Event) error {
+	if e.Name() == m.fail {
+		return errors.New("xxxx error")
+	}
+	m.ids = append(m.ids, id)
+	m.events = append(m.events, e)
+	return nil
+}
+
+func outboxOf(t *testing.T, events ...event.Event) []*model.Outbox {
+	t.Helper()
+	var messages []*model.Outbox
+	for i, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := model.NewOutbox(e.WorkspaceID(), e.Name(), payload)
+		m.ID = i + 1
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+func TestOutboxRelay(t *testing.T) {
+	t.Parallel()
+	todo := model.Todo{ID: 1, WorkspaceID: 1, Task: "task"}
+	t.Run("正常系_保存された順にイベントがIDとともに渡されること", func(t *testing.T) {
+		o := &mockOutbox{messages: outboxOf(t, event.TodoCreated{Todo: todo}, event.TodoDeleted{Todo: todo})}
+		h := &mockOutboxHandler{}
+		p := &mockPublisher{}
+		if err := usecase.NewOutbox(o, h.Handle, p, time.Hour).Relay(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(h.events) != 2 || h.events[0].Name() != event.TodoCreatedName || h.events[1].Name() != event.TodoDeletedName {
+			t.Errorf("unexpected events: %v", h.events)
+		}
+		if !cmp.Equal(h.ids, []int{1, 2}) {
+			t.Errorf("want = %v, got = %v", []int{1, 2}, h.ids)
+		}
+		if created, ok := h.events[0].(event.TodoCreated); !ok || created.Todo != todo {
+			t.Errorf("want = %v, got = %v", todo, h.events[0])
+		}
+		if o.published != 2 {
+			t.Errorf("want = %v, got = %v", 2, o.published)
+		}
+		if len(p.events) != 0 {
+			t.Errorf("want the events only tailed to the bus, got = %v", p.events)
+		}
+	})
+	t.Run("異常系_処理に失敗したイベント以降は発行済みにならないこと", func(t *testing.T) {
+		o := &mockOutbox{messages: outboxOf(t, event.TodoCreated{Todo: todo}, event.TodoDeleted{Todo: todo}, event.TodoCreated{Todo: todo})}
+		h := &mockOutboxHandler{fail: event.TodoDeletedName}
+		if err := usecase.NewOutbox(o, h.Handle, &mockPublisher{}, time.Hour).Relay(context.Background()); err == nil {
+			t.Errorf("want error, got = %v", err)
+		}
+		if o.published != 1 || len(h.events) != 1 {
+			t.Errorf("want = %v, got = %v %v", 1, o.published, h.events)
+		}
+	})
+	t.Run("異常系_復元できないイベントは飛ばして後続が渡されること", func(t *testing.T) {
+		messages := outboxOf(t, event.TodoCreated{Todo: todo}, event.TodoDeleted{Todo: todo})
+		messages[0].Name = "todo.archived"
+		o := &mockOutbox{messages: messages}
+		h := &mockOutboxHandler{}
+		err := usecase.NewOutbox(o, h.Handle, &mockPublisher{}, time.Hour).Relay(context.Background())
+		if !errors.Is(err, event.ErrUnknownEvent) {
+			t.Errorf("want = %v, got = %v", event.ErrUnknownEvent, err)
+		}
+		if o.published != 2 || len(h.events) != 1 {
+			t.Errorf("want = %v, got = %v %v", 2, o.published, h.events)
+		}
+	})
+}
+
+func TestOutboxTail(t *testing.T) {
+	t.Parallel()
+	todo := model.Todo{ID: 1, WorkspaceID: 1, Task: "task"}
+	t.Run("正常系_最初の実行より後に保存されたイベントが発行済みかどうかにかかわらず順に発行されること", func(t *testing.T) {
+		o := &mockOutbox{messages: outboxOf(t, event.TodoCreated{Todo: todo})}
+		h := &mockOutboxHandler{}
+		p := &mockPublisher{}
+		u := usecase.NewOutbox(o, h.Handle, p, time.Hour)
+		if err := u.Tail(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(p.events) != 0 {
+			t.Fatalf("want the events before the first run skipped, got = %v", p.events)
+		}
+		o.messages = outboxOf(t, event.TodoCreated{Todo: todo}, event.TodoUpdated{After: todo}, event.TodoDeleted{Todo: todo})
+		if err := u.Relay(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if err := u.Tail(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(p.events) != 2 || p.events[0].Name() != event.TodoUpdatedName || p.events[1].Name() != event.TodoDeletedName {
+			t.Errorf("unexpected events: %v", p.events)
+		}
+		if err := u.Tail(context.Background()); err != nil || len(p.events) != 2 {
+			t.Errorf("want the events published once, got = %v %v", p.events, err)
+		}
+	})
+	t.Run("正常系_IDが抜けている場合はコミットを待ってから発行されること", func(t *testing.T) {
+		o := &mockOutbox{}
+		p := &mockPublisher{}
+		u := usecase.NewOutbox(o, (&mockOutboxHandler{}).Handle, p, time.Hour)
+		if err := u.Tail(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		all := outboxOf(t, event.TodoCreated{Todo: todo}, event.TodoUpdated{After: todo}, event.TodoDeleted{Todo: todo})
+		// 1件目のトランザクションがまだコミットされていない
+		o.messages = all[1:]
+		if err := u.Tail(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(p.events) != 0 {
+			t.Fatalf("want the events after the gap held back, got = %v", p.events)
+		}
+		o.messages = all
+		if err := u.Tail(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(p.events) != 3 || p.events[0].Name() != event.TodoCreatedName {
+			t.Errorf("unexpected events: %v", p.events)
+		}
+	})
+}
@@ -1,58 +1,443 @@
 package usecase
 
 import (
+	"app/domain/event"
 	"app/domain/model"
 	"app/domain/repository"
+	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
 type Todo interface {
-	Create(task string) error
-	Update(id int, task string, status model.TaskStatus) error
-	Delete(id int) error
-	Find(id int) (*model.Todo, error)
-	FindAll() ([]*model.Todo, error)
+	// Create adds the todo to the list with listID, or to none when it is 0.
+	Create(ctx context.Context, actor model.Actor, task string, listID int) error
+	// Update changes the task and status; the todo stays in its list.
+	Update(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus) error
+	// UpdateIf and DeleteIf only write when match accepts the todo as it is
+	// when locked, or nil when it is missing, and return
+	// ErrPreconditionFailed otherwise.
+	UpdateIf(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus, match func(current *model.Todo) bool) error
+	// Move puts the todo into the list with listID, or takes it out of its
+	// list when listID is 0.
+	Move(ctx context.Context, actor model.Actor, id int, listID int) error
+	Delete(ctx context.Context, actor model.Actor, id int) error
+	DeleteIf(ctx context.Context, actor model.Actor, id int, match func(current *model.Todo) bool) error
+	Find(ctx context.Context, actor model.Actor, id int) (*model.Todo, error)
+	FindAll(ctx context.Context, actor model.Actor) ([]*model.Todo, error)
+	FindByIDs(ctx context.Context, actor model.Actor, ids []int) ([]*model.Todo, error)
+	Search(ctx context.Context, actor model.Actor, f model.TodoFilter) ([]*model.Todo, error)
+	Count(ctx context.Context, actor model.Actor, f model.TodoFilter) (int64, error)
+	Subscribe(ctx context.Context, actor model.Actor, lastEventID uint64) ([]TodoEvent, <-chan TodoEvent, func(), error)
+	History(ctx context.Context, actor model.Actor, id int, limit int, offset int) ([]*model.TodoHistory, error)
+	Activity(ctx context.Context, actor model.Actor, f model.ActivityFilter) ([]*model.TodoHistory, error)
+	FindAsOf(ctx context.Context, actor model.Actor, id int, asOf time.Time) (*model.Todo, error)
+	Diff(ctx context.Context, actor model.Actor, id int, from int, to int) (*model.TodoDiff, error)
+	Revert(ctx context.Context, actor model.Actor, id int, revision int, baseRevision int) error
+	Bulk(ctx context.Context, actor model.Actor, ops []TodoOperation, atomic bool) ([]TodoOperationResult, error)
+	SetStatus(ctx context.Context, actor model.Actor, f model.TodoFilter, status model.TaskStatus, dryRun bool) (*StatusChange, error)
+	Import(ctx context.Context, actor model.Actor, todos []*model.Todo) error
+	ImportFrom(ctx context.Context, actor model.Actor, todos []SourcedTodo) (int, error)
+	CreateFrom(ctx context.Context, actor model.Actor, todo *model.Todo, sourceIDs []string) error
 }
 type todo struct {
-	todoRepository repository.Todo
+	todoRepository    repository.Todo
+	historyRepository repository.TodoHistory
+	memberRepository  repository.Member
+	transaction       repository.Transaction
+	stream            TodoStream
 }
 
-func NewTodo(r repository.Todo) Todo {
-	return &todo{r}
+func NewTodo(r repository.Todo, h repository.TodoHistory, m repository.Member, tx repository.Transaction, s TodoStream) Todo {
+	return &todo{r, h, m, tx, s}
 }
-func (t *todo) Create(task string) error {
-	todo := model.NewTodo(task)
-	if err := t.todoRepository.Create(todo); err != nil {
+
+func (t *todo) Create(ctx context.Context, actor model.Actor, task string, listID int) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
 		return err
 	}
-	return nil
+	todo := model.NewTodo(actor.WorkspaceID, listID, task)
+	return t.transaction.Do(ctx, func(r repository.Repositories) error {
+		if err := lockList(ctx, r.List, actor, listID); err != nil {
+			return err
+		}
+		return t.create(ctx, r, actor, todo)
+	})
 }
 
-func (t *todo) Update(id int, task string, status model.TaskStatus) error {
-	todo := model.NewUpdateTodo(id, task, status)
-	if err := t.todoRepository.Update(todo); err != nil {
+func (t *todo) Update(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus) error {
+	return t.UpdateIf(ctx, actor, id, task, status, nil)
+}
+
+func (t *todo) UpdateIf(ctx context.Context, actor model.Actor, id int, task string, status model.TaskStatus, match func(current *model.Todo) bool) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
 		return err
 	}
-	return nil
+	return t.transaction.Do(ctx, func(r repository.Repositories) error {
+		before, err := lockTodoIf(ctx, r.Todo, actor, id, match)
+		if err != nil {
+			return err
+		}
+		_, err = t.update(ctx, r, actor, before, task, status)
+		return err
+	})
 }
-func (t *todo) Delete(id int) error {
-	if err := t.todoRepository.Delete(id); err != nil {
+func (t *todo) Move(ctx context.Context, actor model.Actor, id int, listID int) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
 		return err
 	}
-	return nil
+	return t.transaction.Do(ctx, func(r repository.Repositories) error {
+		before, err := lockTodo(ctx, r.Todo, actor, id)
+		if err != nil {
+			return err
+		}
+		if before.ListID == listID {
+			return nil
+		}
+		if err := lockList(ctx, r.List, actor, listID); err != nil {
+			return err
+		}
+		todo := *before
+		todo.ListID = listID
+		return t.save(ctx, r, actor, before, &todo)
+	})
+}
+
+func (t *todo) Delete(ctx context.Context, actor model.Actor, id int) error {
+	return t.DeleteIf(ctx, actor, id, nil)
+}
+
+func (t *todo) DeleteIf(ctx context.Context, actor model.Actor, id int, match func(current *model.Todo) bool) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return err
+	}
+	return t.transaction.Do(ctx, func(r repository.Repositories) error {
+		todo, err := lockTodoIf(ctx, r.Todo, actor, id, match)
+		if err != nil {
+			return err
+		}
+		return t.delete(ctx, r, actor, todo)
+	})
+}
+
+// The todo writes record the history and store their domain events in the
+// outbox in the transaction of r; the outbox relay publishes the events
+// afterwards. Writes to an existing todo lock it first, so its history is
+// appended in order and a change is never based on a stale read.
+
+func (t *todo) create(ctx context.Context, r repository.Repositories, actor model.Actor, todos ...*model.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	if err := r.Todo.CreateBatch(ctx, todos); err != nil {
+		return err
+	}
+	now := time.Now()
+	histories := make([]*model.TodoHistory, 0, len(todos))
+	events := make([]event.Event, 0, len(todos))
+	for _, todo := range todos {
+		histories = append(histories, model.NewTodoHistory(actor, model.TodoActionCreated, nil, todo, now))
+		events = append(events, event.TodoCreated{Actor: actor, Todo: *todo, At: now})
+	}
+	if err := r.TodoHistory.Create(ctx, histories...); err != nil {
+		return err
+	}
+	return r.Outbox.Store(ctx, events...)
+}
+
+func (t *todo) update(ctx context.Context, r repository.Repositories, actor model.Actor, before *model.Todo, task string, status model.TaskStatus) (*model.Todo, error) {
+	todo := model.NewUpdateTodo(before.ID, actor.WorkspaceID, task, status)
+	todo.ListID = before.ListID
+	if err := t.save(ctx, r, actor, before, todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+func (t *todo) save(ctx context.Context, r repository.Repositories, actor model.Actor, before *model.Todo, todo *model.Todo) error {
+	if err := r.Todo.Update(ctx, todo); err != nil {
+		return err
+	}
+	todo.CreatedAt = before.CreatedAt
+	now := time.Now()
+	if err := r.TodoHistory.Create(ctx, model.NewTodoHistory(actor, model.TodoActionUpdated, before, todo, now)); err != nil {
+		return err
+	}
+	events := []event.Event{event.TodoUpdated{Actor: actor, Before: *before, After: *todo, At: now}}
+	if before.Status != todo.Status {
+		events = append(events, event.TodoStatusChanged{Actor: actor, Todo: *todo, From: before.Status, To: todo.Status, At: now})
+	}
+	return r.Outbox.Store(ctx, events...)
+}
+
+func (t *todo) delete(ctx context.Context, r repository.Repositories, actor model.Actor, todo *model.Todo) error {
+	if err := r.Todo.Delete(ctx, todo.ID); err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := r.TodoHistory.Create(ctx, model.NewTodoHistory(actor, model.TodoActionDeleted, todo, nil, now)); err != nil {
+		return err
+	}
+	return r.Outbox.Store(ctx, event.TodoDeleted{Actor: actor, Todo: *todo, At: now})
+}
+
+func (t *todo) Find(ctx context.Context, actor model.Actor, id int) (*model.Todo, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	todo, err := t.todoRepository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if todo == nil || todo.WorkspaceID != actor.WorkspaceID {
+		return nil, nil
+	}
+	return todo, nil
 }
 
-func (t *todo) Find(id int) (*model.Todo, error) {
-	todo, err := t.todoRepository.Find(id)
+func (t *todo) FindAll(ctx context.Context, actor model.Actor) ([]*model.Todo, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	todo, err := t.todoRepository.FindAll(ctx, actor.WorkspaceID)
 	if err != nil {
 		return nil, err
 	}
 	return todo, nil
 }
 
-func (t *todo) FindAll() ([]*model.Todo, error) {
-	todo, err := t.todoRepository.FindAll()
+// FindByIDs silently drops ids that belong to other workspaces.
+func (t *todo) FindByIDs(ctx context.Context, actor model.Actor, ids []int) ([]*model.Todo, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	todos, err := t.todoRepository.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*model.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if todo.WorkspaceID == actor.WorkspaceID {
+			res = append(res, todo)
+		}
+	}
+	return res, nil
+}
+
+func (t *todo) Search(ctx context.Context, actor model.Actor, f model.TodoFilter) ([]*model.Todo, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	todos, err := t.todoRepository.Search(ctx, actor.WorkspaceID, f)
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (t *todo) Count(ctx context.Context, actor model.Actor, f model.TodoFilter) (int64, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return 0, err
+	}
+	count, err := t.todoRepository.Count(ctx, actor.WorkspaceID, f)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *todo) Subscribe(ctx context.Context, actor model.Actor, lastEventID uint64) ([]TodoEvent, <-chan TodoEvent, func(), error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, nil, nil, err
+	}
+	replay, events, cancel := t.stream.Subscribe(actor.WorkspaceID, lastEventID)
+	return replay, events, cancel, nil
+}
+
+// History returns the changes of a todo, oldest first. It stays readable
+// after the todo has been deleted. Like revisions, the history of a todo
+// created before changes were recorded starts with a seeded created entry.
+func (t *todo) History(ctx context.Context, actor model.Actor, id int, limit int, offset int) ([]*model.TodoHistory, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	first, err := t.historyRepository.FindAll(ctx, actor.WorkspaceID, id, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(first) > 0 && first[0].Action == model.TodoActionCreated {
+		return t.historyRepository.FindAll(ctx, actor.WorkspaceID, id, limit, offset)
+	}
+	revisions, err := t.revisions(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+	if offset >= len(revisions) {
+		return []*model.TodoHistory{}, nil
+	}
+	revisions = revisions[offset:]
+	if limit > 0 && limit < len(revisions) {
+		revisions = revisions[:limit]
+	}
+	return revisions, nil
+}
+
+func (t *todo) Activity(ctx context.Context, actor model.Actor, f model.ActivityFilter) ([]*model.TodoHistory, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	histories, err := t.historyRepository.Search(ctx, actor.WorkspaceID, f)
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// FindAsOf rebuilds the todo as it was at asOf from its history. Like Find it
+// returns nil when the todo did not exist at that time.
+func (t *todo) FindAsOf(ctx context.Context, actor model.Actor, id int, asOf time.Time) (*model.Todo, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	revisions, err := t.revisions(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for n < len(revisions) && !revisions[n].OccurredAt.After(asOf) {
+		n++
+	}
+	return model.ReplayTodoHistory(revisions[:n]), nil
+}
+
+func (t *todo) Diff(ctx context.Context, actor model.Actor, id int, from int, to int) (*model.TodoDiff, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	revisions, err := t.revisions(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := replayUntil(revisions, from)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	b, ok := replayUntil(revisions, to)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &model.TodoDiff{From: from, To: to, Changes: model.DiffTodo(a, b)}, nil
+}
+
+// Revert restores the task and status of a revision as a new update. When
+// baseRevision is given it has to be the latest revision, so that a revert
+// based on a stale view does not overwrite a later change. The history is
+// read with the todo locked, so no change slips in before the update.
+func (t *todo) Revert(ctx context.Context, actor model.Actor, id int, revision int, baseRevision int) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return err
+	}
+	return t.transaction.Do(ctx, func(r repository.Repositories) error {
+		current, err := lockTodo(ctx, r.Todo, actor, id)
+		if err != nil {
+			return err
+		}
+		revisions, err := r.TodoHistory.RevisionsForUpdate(ctx, actor.WorkspaceID, id)
+		if err != nil {
+			return err
+		}
+		revisions = model.SeedTodoHistory(revisions, current)
+		target, ok := replayUntil(revisions, revision)
+		if !ok {
+			return ErrNotFound
+		}
+		if target == nil {
+			// The revision deletes the todo; there is nothing to restore.
+			return ErrConflict
+		}
+		if baseRevision != 0 && revisions[len(revisions)-1].ID != baseRevision {
+			return ErrConflict
+		}
+		_, err = t.update(ctx, r, actor, current, target.Task, target.Status)
+		return err
+	})
+}
+
+// revisions returns the whole history of a todo. The history of a todo
+// created before changes were recorded is seeded from its current state.
+func (t *todo) revisions(ctx context.Context, actor model.Actor, id int) ([]*model.TodoHistory, error) {
+	revisions, err := t.historyRepository.Revisions(ctx, actor.WorkspaceID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) > 0 && revisions[0].Action == model.TodoActionCreated {
+		return revisions, nil
+	}
+	current, err := t.todoRepository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.WorkspaceID != actor.WorkspaceID {
+		current = nil
+	}
+	return model.SeedTodoHistory(revisions, current), nil
+}
+
+// replayUntil rebuilds the todo as of the given revision. ok is false when
+// the revision does not belong to the todo.
+func replayUntil(revisions []*model.TodoHistory, revision int) (*model.Todo, bool) {
+	for i, h := range revisions {
+		if h.ID == revision {
+			return model.ReplayTodoHistory(revisions[:i+1]), true
+		}
+	}
+	return nil, false
+}
+
+// lockTodo locks the todo with id for the rest of the transaction. Todos of
+// other workspaces are treated as missing so that their existence is not
+// leaked across workspaces.
+func lockTodo(ctx context.Context, r repository.Todo, actor model.Actor, id int) (*model.Todo, error) {
+	todo, err := r.FindForUpdate(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if todo == nil || todo.WorkspaceID != actor.WorkspaceID {
+		return nil, ErrNotFound
+	}
 	return todo, nil
 }
+
+// lockTodoIf is lockTodo for a conditional write. A nil match accepts any
+// todo.
+func lockTodoIf(ctx context.Context, r repository.Todo, actor model.Actor, id int, match func(current *model.Todo) bool) (*model.Todo, error) {
+	todo, err := lockTodo(ctx, r, actor, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if match != nil && !match(todo) {
+		return nil, ErrPreconditionFailed
+	}
+	return todo, err
+}
+
+// lockList checks that the list with listID belongs to the actor's workspace
+// and locks it, so that it is not deleted before the todo is stored. A
+// listID of 0 stands for no list.
+func lockList(ctx context.Context, r repository.List, actor model.Actor, listID int) error {
+	if listID == 0 {
+		return nil
+	}
+	list, err := r.FindForUpdate(ctx, listID)
+	if err != nil {
+		return err
+	}
+	if list == nil || list.WorkspaceID != actor.WorkspaceID {
+		return fmt.Errorf("list %d: %w", listID, ErrNotFound)
+	}
+	return nil
+}
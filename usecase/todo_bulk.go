@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"errors"
+)
+
+type TodoOperationType string
+
+const (
+	TodoOperationCreate TodoOperationType = "create"
+	TodoOperationUpdate TodoOperationType = "update"
+	TodoOperationDelete TodoOperationType = "delete"
+)
+
+// TodoOperation is one item of a bulk request. ID is ignored for creates.
+type TodoOperation struct {
+	Type   TodoOperationType
+	ID     int
+	Task   string
+	Status model.TaskStatus
+}
+
+// TodoOperationResult holds the ID of the affected todo, or the error of the
+// operation. Operations that were rolled back because another operation of an
+// atomic request failed get ErrRolledBack.
+type TodoOperationResult struct {
+	ID  int
+	Err error
+}
+
+// Bulk applies the operations in order in a single transaction. When atomic
+// is set the first failing operation rolls back all of them; otherwise each
+// operation runs in its own savepoint and only the failing ones are undone.
+// The returned error is for failures outside of the operations.
+func (t *todo) Bulk(ctx context.Context, actor model.Actor, ops []TodoOperation, atomic bool) ([]TodoOperationResult, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return nil, err
+	}
+	results := make([]TodoOperationResult, len(ops))
+	errAborted := errors.New("bulk operation aborted")
+	err := t.transaction.Do(ctx, func(r repository.Repositories) error {
+		targets, err := bulkTargets(ctx, r.Todo, actor, ops)
+		if err != nil {
+			return err
+		}
+		// Creates of an atomic request are inserted together at the end.
+		var created []*model.Todo
+		var createdAt []int
+		for i, op := range ops {
+			if atomic && op.Type == TodoOperationCreate {
+				created = append(created, model.NewTodo(actor.WorkspaceID, 0, op.Task))
+				createdAt = append(createdAt, i)
+				continue
+			}
+			apply := func(r repository.Repositories) error {
+				id, err := t.apply(ctx, r, actor, op, targets)
+				results[i].ID = id
+				return err
+			}
+			if atomic {
+				err = apply(r)
+			} else {
+				err = r.Transaction.Do(ctx, apply)
+			}
+			if err != nil {
+				results[i] = TodoOperationResult{ID: op.ID, Err: err}
+				if atomic {
+					return errAborted
+				}
+			}
+		}
+		if err := t.create(ctx, r, actor, created...); err != nil {
+			return err
+		}
+		for j, i := range createdAt {
+			results[i].ID = created[j].ID
+		}
+		return nil
+	})
+	if errors.Is(err, errAborted) {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = TodoOperationResult{ID: ops[i].ID, Err: ErrRolledBack}
+			}
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// bulkTargets loads and locks the todos the updates and deletes refer to in
+// one query.
+func bulkTargets(ctx context.Context, r repository.Todo, actor model.Actor, ops []TodoOperation) (map[int]*model.Todo, error) {
+	var ids []int
+	for _, op := range ops {
+		if op.Type != TodoOperationCreate {
+			ids = append(ids, op.ID)
+		}
+	}
+	targets := map[int]*model.Todo{}
+	if len(ids) == 0 {
+		return targets, nil
+	}
+	todos, err := r.FindByIDsForUpdate(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range todos {
+		if todo.WorkspaceID == actor.WorkspaceID {
+			targets[todo.ID] = todo
+		}
+	}
+	return targets, nil
+}
+
+// apply runs a single operation and keeps targets up to date, so that later
+// operations on the same todo see its changes.
+func (t *todo) apply(ctx context.Context, r repository.Repositories, actor model.Actor, op TodoOperation, targets map[int]*model.Todo) (int, error) {
+	if op.Type == TodoOperationCreate {
+		todo := model.NewTodo(actor.WorkspaceID, 0, op.Task)
+		if err := t.create(ctx, r, actor, todo); err != nil {
+			return 0, err
+		}
+		return todo.ID, nil
+	}
+	before, ok := targets[op.ID]
+	if !ok {
+		return op.ID, ErrNotFound
+	}
+	if op.Type == TodoOperationDelete {
+		if err := t.delete(ctx, r, actor, before); err != nil {
+			return op.ID, err
+		}
+		delete(targets, op.ID)
+		return op.ID, nil
+	}
+	after, err := t.update(ctx, r, actor, before, op.Task, op.Status)
+	if err != nil {
+		return op.ID, err
+	}
+	targets[op.ID] = after
+	return op.ID, nil
+}
+
+// StatusChange reports the todos matched by SetStatus. Skipped todos
+// already had the target status and are left untouched.
+type StatusChange struct {
+	Matched []*model.Todo
+	Changed []int
+	Skipped []int
+}
+
+// MaxStatusChanges is the most todos SetStatus changes at once, the same
+// as the operations of a bulk request.
+const MaxStatusChanges = 500
+
+// SetStatus moves every todo matching f to status in a single transaction,
+// recording each change like Update. The matching todos are locked before
+// they are changed. With dryRun nothing is written, but the result tells
+// which todos would change. ErrTooManyMatches means more than
+// MaxStatusChanges todos match.
+func (t *todo) SetStatus(ctx context.Context, actor model.Actor, f model.TodoFilter, status model.TaskStatus, dryRun bool) (*StatusChange, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return nil, err
+	}
+	f.Limit, f.Offset = MaxStatusChanges+1, 0
+	res := &StatusChange{}
+	err := t.transaction.Do(ctx, func(r repository.Repositories) error {
+		todos, err := r.Todo.SearchForUpdate(ctx, actor.WorkspaceID, f)
+		if err != nil {
+			return err
+		}
+		if len(todos) > MaxStatusChanges {
+			return ErrTooManyMatches
+		}
+		res.Matched = todos
+		for _, todo := range todos {
+			if todo.Status == status {
+				res.Skipped = append(res.Skipped, todo.ID)
+				continue
+			}
+			if !dryRun {
+				if _, err := t.update(ctx, r, actor, todo, todo.Task, status); err != nil {
+					return err
+				}
+			}
+			res.Changed = append(res.Changed, todo.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Import creates the todos with their task and status in the actor's
+// workspace with batched inserts, all or nothing. It sets the IDs of the
+// todos.
+func (t *todo) Import(ctx context.Context, actor model.Actor, todos []*model.Todo) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		todo.WorkspaceID = actor.WorkspaceID
+	}
+	return t.transaction.Do(ctx, func(r repository.Repositories) error {
+		return t.create(ctx, r, actor, todos...)
+	})
+}
+
+// SourcedTodo is a todo read from the export of another app, with the ID it
+// had there and the name of the list it goes into, if any.
+type SourcedTodo struct {
+	SourceID string
+	List     string
+	Todo     *model.Todo
+}
+
+// ImportFrom creates the todos whose source ID was not imported into the
+// actor's workspace before, all or nothing, and returns how many were
+// created. A source ID repeated in todos is only created once. A todo goes
+// into the workspace's list with its List name, which is created when there
+// is none. ErrConflict means another import created some of them at the
+// same time.
+func (t *todo) ImportFrom(ctx context.Context, actor model.Actor, todos []SourcedTodo) (int, error) {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return 0, err
+	}
+	ids := make([]string, len(todos))
+	for i, s := range todos {
+		ids[i] = s.SourceID
+	}
+	created := 0
+	err := t.transaction.Do(ctx, func(r repository.Repositories) error {
+		sources, err := r.TodoSource.FindAll(ctx, actor.WorkspaceID, ids)
+		if err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, source := range sources {
+			seen[source.SourceID] = true
+		}
+		var news []SourcedTodo
+		var batch []*model.Todo
+		for _, s := range todos {
+			if seen[s.SourceID] {
+				continue
+			}
+			seen[s.SourceID] = true
+			s.Todo.WorkspaceID = actor.WorkspaceID
+			news = append(news, s)
+			batch = append(batch, s.Todo)
+		}
+		if err := importLists(ctx, r.List, actor, news); err != nil {
+			return err
+		}
+		if err := t.create(ctx, r, actor, batch...); err != nil {
+			return err
+		}
+		links := make([]*model.TodoSource, len(news))
+		for i, s := range news {
+			links[i] = &model.TodoSource{WorkspaceID: actor.WorkspaceID, SourceID: s.SourceID, TodoID: s.Todo.ID}
+		}
+		if err := r.TodoSource.Create(ctx, links...); err != nil {
+			return err
+		}
+		created = len(batch)
+		return nil
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return 0, ErrConflict
+	}
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// importLists sets the list of each todo to the workspace's list with its
+// List name. Existing lists are locked like the list of a created todo, and
+// missing ones are created.
+func importLists(ctx context.Context, r repository.List, actor model.Actor, todos []SourcedTodo) error {
+	ids := map[string]int{}
+	for _, s := range todos {
+		if s.List != "" {
+			ids[s.List] = 0
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	lists, err := r.FindAll(ctx, actor.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	for _, l := range lists {
+		if id, ok := ids[l.Name]; ok && id == 0 {
+			if err := lockList(ctx, r, actor, l.ID); err != nil {
+				return err
+			}
+			ids[l.Name] = l.ID
+		}
+	}
+	for _, s := range todos {
+		if s.List == "" {
+			continue
+		}
+		if ids[s.List] == 0 {
+			l := model.NewList(actor.WorkspaceID, s.List)
+			if err := r.Create(ctx, l); err != nil {
+				return err
+			}
+			ids[s.List] = l.ID
+		}
+		s.Todo.ListID = ids[s.List]
+	}
+	return nil
+}
@@ -0,0 +1,438 @@
+package usecase_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// batchTodo は一括登録されたタスクに100からの連番のIDを振る
+type batchTodo struct {
+	*mockTodo
+	nextID int
+}
+
+func (m *batchTodo) CreateBatch(ctx context.Context, todos []*model.Todo) error {
+	for _, todo := range todos {
+		todo.ID = 100 + m.nextID
+		m.nextID++
+	}
+	return nil
+}
+
+func TestTodoBulk(t *testing.T) {
+	t.Parallel()
+	create := usecase.TodoOperation{Type: usecase.TodoOperationCreate, Task: "task"}
+	update := func(id int) usecase.TodoOperation {
+		return usecase.TodoOperation{Type: usecase.TodoOperationUpdate, ID: id, Task: "task", Status: model.Done}
+	}
+	remove := func(id int) usecase.TodoOperation {
+		return usecase.TodoOperation{Type: usecase.TodoOperationDelete, ID: id}
+	}
+	tests := []struct {
+		name     string
+		ops      []usecase.TodoOperation
+		atomic   bool
+		member   repository.Member
+		expected []usecase.TodoOperationResult
+		events   []string
+		err      error
+	}{
+		{
+			name:   "正常系_一括モードで全ての操作が実行され登録がまとめて行われること",
+			ops:    []usecase.TodoOperation{create, update(1), remove(2), create},
+			atomic: true,
+			member: memberOf(model.Editor),
+			expected: []usecase.TodoOperationResult{
+				{ID: 100}, {ID: 1}, {ID: 2}, {ID: 101},
+			},
+			events: []string{
+				event.TodoUpdatedName, event.TodoStatusChangedName, event.TodoDeletedName,
+				event.TodoCreatedName, event.TodoCreatedName,
+			},
+		},
+		{
+			name:   "異常系_一括モードで操作が失敗した場合他の操作がロールバック扱いになること",
+			ops:    []usecase.TodoOperation{create, update(3), remove(1)},
+			atomic: true,
+			member: memberOf(model.Editor),
+			expected: []usecase.TodoOperationResult{
+				{Err: usecase.ErrRolledBack}, {ID: 3, Err: usecase.ErrNotFound}, {ID: 1, Err: usecase.ErrRolledBack},
+			},
+		},
+		{
+			name:   "正常系_ベストエフォートの場合失敗した操作以外が実行されること",
+			ops:    []usecase.TodoOperation{create, update(3), remove(1)},
+			member: memberOf(model.Editor),
+			expected: []usecase.TodoOperationResult{
+				{ID: 100}, {ID: 3, Err: usecase.ErrNotFound}, {ID: 1},
+			},
+			events: []string{event.TodoCreatedName, event.TodoDeletedName},
+		},
+		{
+			name:   "異常系_削除したタスクへの後続の操作は見つからないこと",
+			ops:    []usecase.TodoOperation{remove(1), update(1)},
+			member: memberOf(model.Editor),
+			expected: []usecase.TodoOperationResult{
+				{ID: 1}, {ID: 1, Err: usecase.ErrNotFound},
+			},
+			events: []string{event.TodoDeletedName},
+		},
+		{
+			name:   "異常系_閲覧者は一括操作ができないこと",
+			ops:    []usecase.TodoOperation{create},
+			atomic: true,
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := &batchTodo{mockTodo: &mockTodo{
+				mockCreate: func() error { return nil },
+				mockUpdate: func() error { return nil },
+				mockDelete: func() error { return nil },
+				mockFindByIDs: func() ([]*model.Todo, error) {
+					return []*model.Todo{
+						{ID: 1, WorkspaceID: actor.WorkspaceID, Status: model.Created},
+						{ID: 2, WorkspaceID: actor.WorkspaceID, Status: model.Created},
+						{ID: 3, WorkspaceID: actor.WorkspaceID + 1, Status: model.Created},
+					}, nil
+				},
+			}}
+			tx := transactionOf(todos)
+			u := usecase.NewTodo(todos, &mockTodoHistory{}, tt.member, tx, usecase.NewTodoStream(0))
+
+			got, err := u.Bulk(context.Background(), actor, tt.ops, tt.atomic)
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("want = %v, got = %v", tt.expected, got)
+			}
+			for i, want := range tt.expected {
+				if got[i].ID != want.ID || !equalError(got[i].Err, want.Err) {
+					t.Errorf("results[%d]: want = %+v, got = %+v", i, want, got[i])
+				}
+			}
+			if tt.events == nil {
+				return
+			}
+			stored := tx.repositories.Outbox.(*mockOutbox).stored
+			if len(stored) != len(tt.events) {
+				t.Fatalf("want = %v, got = %v", tt.events, stored)
+			}
+			for i, name := range tt.events {
+				if stored[i].Name() != name {
+					t.Errorf("events[%d]: want = %v, got = %v", i, name, stored[i].Name())
+				}
+			}
+		})
+	}
+}
+
+func TestTodoSetStatus(t *testing.T) {
+	t.Parallel()
+	matched := func() ([]*model.Todo, error) {
+		return []*model.Todo{
+			{ID: 1, WorkspaceID: actor.WorkspaceID, Task: "task1", Status: model.Processing},
+			{ID: 2, WorkspaceID: actor.WorkspaceID, Task: "task2", Status: model.Done},
+			{ID: 3, WorkspaceID: actor.WorkspaceID, Task: "task3", Status: model.Created},
+		}, nil
+	}
+	tests := []struct {
+		name    string
+		dryRun  bool
+		member  repository.Member
+		search  func() ([]*model.Todo, error)
+		changed []int
+		skipped []int
+		events  int
+		err     error
+	}{
+		{
+			name:    "正常系_対象のタスクのステータスが変更され既に同じステータスのタスクはスキップされること",
+			member:  memberOf(model.Editor),
+			search:  matched,
+			changed: []int{1, 3},
+			skipped: []int{2},
+			// 更新ごとにTodoUpdatedとTodoStatusChangedが保存される
+			events: 4,
+		},
+		{
+			name:    "正常系_ドライランの場合は変更されないこと",
+			dryRun:  true,
+			member:  memberOf(model.Editor),
+			search:  matched,
+			changed: []int{1, 3},
+			skipped: []int{2},
+			events:  0,
+		},
+		{
+			name:   "異常系_検索に失敗した場合エラーが返ること",
+			member: memberOf(model.Editor),
+			search: func() ([]*model.Todo, error) { return nil, errors.New("xxxx error") },
+			err:    errors.New("xxxx error"),
+		},
+		{
+			name:   "異常系_上限を超えるタスクが該当する場合エラーが返ること",
+			member: memberOf(model.Editor),
+			search: func() ([]*model.Todo, error) {
+				todos := make([]*model.Todo, usecase.MaxStatusChanges+1)
+				for i := range todos {
+					todos[i] = &model.Todo{ID: i + 1, WorkspaceID: actor.WorkspaceID, Task: "task", Status: model.Created}
+				}
+				return todos, nil
+			},
+			err: usecase.ErrTooManyMatches,
+		},
+		{
+			name:   "異常系_閲覧者はステータスを変更できないこと",
+			member: memberOf(model.Viewer),
+			search: matched,
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var updated *model.Todo
+			todos := &mockTodo{mockSearchForUpdate: tt.search}
+			tx := transactionOf(&recordingTodo{mockTodo: todos, updated: &updated})
+			u := usecase.NewTodo(todos, &mockTodoHistory{}, tt.member, tx, usecase.NewTodoStream(0))
+
+			got, err := u.SetStatus(context.Background(), actor, model.TodoFilter{}, model.Done, tt.dryRun)
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if !cmp.Equal(got.Changed, tt.changed) || !cmp.Equal(got.Skipped, tt.skipped) {
+				t.Errorf("want = %v/%v, got = %v/%v", tt.changed, tt.skipped, got.Changed, got.Skipped)
+			}
+			if tt.dryRun && updated != nil {
+				t.Errorf("dry run updated %v", updated)
+			}
+			if !tt.dryRun && (updated == nil || updated.Status != model.Done || updated.Task != "task3") {
+				t.Errorf("unexpected update: %v", updated)
+			}
+			if stored := tx.repositories.Outbox.(*mockOutbox).stored; len(stored) != tt.events {
+				t.Errorf("want = %v, got = %v", tt.events, len(stored))
+			}
+		})
+	}
+}
+
+func TestTodoImport(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		member repository.Member
+		events int
+		err    error
+	}{
+		{
+			name:   "正常系_ステータスを保ったままワークスペースに登録されること",
+			member: memberOf(model.Editor),
+			events: 2,
+		},
+		{
+			name:   "異常系_閲覧者はインポートできないこと",
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := &batchTodo{mockTodo: &mockTodo{}}
+			tx := transactionOf(todos)
+			u := usecase.NewTodo(todos, &mockTodoHistory{}, tt.member, tx, usecase.NewTodoStream(0))
+			imported := []*model.Todo{
+				{Task: "task1", Status: model.Done},
+				{Task: "task2", Status: model.Created},
+			}
+
+			err := u.Import(context.Background(), actor, imported)
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			expected := []*model.Todo{
+				{ID: 100, WorkspaceID: actor.WorkspaceID, Task: "task1", Status: model.Done},
+				{ID: 101, WorkspaceID: actor.WorkspaceID, Task: "task2", Status: model.Created},
+			}
+			if !cmp.Equal(imported, expected) {
+				t.Errorf("diff %s", cmp.Diff(imported, expected))
+			}
+			if stored := tx.repositories.Outbox.(*mockOutbox).stored; len(stored) != tt.events {
+				t.Errorf("want = %v, got = %v", tt.events, len(stored))
+			}
+			if created := tx.repositories.TodoHistory.(*mockTodoHistory).created; len(created) != tt.events {
+				t.Errorf("want = %v, got = %v", tt.events, len(created))
+			}
+		})
+	}
+}
+
+// mockTodoSource は登録済みのインポート元IDを保持する
+type mockTodoSource struct {
+	repository.TodoSource
+	sources   []*model.TodoSource
+	createErr error
+	// stale は付け替える前に他のリクエストがリンクを付け替えたことを表す
+	stale bool
+}
+
+func (m *mockTodoSource) Create(ctx context.Context, sources ...*model.TodoSource) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.sources = append(m.sources, sources...)
+	return nil
+}
+func (m *mockTodoSource) Relink(ctx context.Context, s *model.TodoSource, from int) (bool, error) {
+	return !m.stale, nil
+}
+func (m *mockTodoSource) FindByTodoIDs(ctx context.Context, workspaceID int, todoIDs []int) ([]*model.TodoSource, error) {
+	var res []*model.TodoSource
+	for _, s := range m.sources {
+		for _, id := range todoIDs {
+			if s.WorkspaceID == workspaceID && s.TodoID == id {
+				res = append(res, s)
+			}
+		}
+	}
+	return res, nil
+}
+func (m *mockTodoSource) FindAll(ctx context.Context, workspaceID int, sourceIDs []string) ([]*model.TodoSource, error) {
+	var res []*model.TodoSource
+	for _, s := range m.sources {
+		for _, id := range sourceIDs {
+			if s.WorkspaceID == workspaceID && s.SourceID == id {
+				res = append(res, s)
+			}
+		}
+	}
+	return res, nil
+}
+
+func TestTodoImportFrom(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		member    repository.Member
+		createErr error
+		created   int
+		sources   []*model.TodoSource
+		err       error
+	}{
+		{
+			name:    "正常系_インポート済みと重複したIDを除いて登録されること",
+			member:  memberOf(model.Editor),
+			created: 2,
+			sources: []*model.TodoSource{
+				{WorkspaceID: 1, SourceID: "trello:a", TodoID: 1},
+				{WorkspaceID: 1, SourceID: "trello:b", TodoID: 100},
+				{WorkspaceID: 1, SourceID: "trello:c", TodoID: 101},
+			},
+		},
+		{
+			name:      "異常系_同時にインポートされた場合競合エラーになること",
+			member:    memberOf(model.Editor),
+			createErr: repository.ErrDuplicate,
+			err:       usecase.ErrConflict,
+		},
+		{
+			name:   "異常系_閲覧者はインポートできないこと",
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := &batchTodo{mockTodo: &mockTodo{}}
+			tx := transactionOf(todos)
+			sources := &mockTodoSource{
+				// 別のワークスペースで同じIDがインポートされていても影響しない
+				sources:   []*model.TodoSource{{WorkspaceID: 1, SourceID: "trello:a", TodoID: 1}, {WorkspaceID: 2, SourceID: "trello:b", TodoID: 2}},
+				createErr: tt.createErr,
+			}
+			tx.repositories.TodoSource = sources
+			u := usecase.NewTodo(todos, &mockTodoHistory{}, tt.member, tx, usecase.NewTodoStream(0))
+
+			created, err := u.ImportFrom(context.Background(), actor, []usecase.SourcedTodo{
+				{SourceID: "trello:a", Todo: &model.Todo{Task: "imported", Status: model.Done}},
+				{SourceID: "trello:b", Todo: &model.Todo{Task: "task1", Status: model.Done}},
+				{SourceID: "trello:c", Todo: &model.Todo{Task: "task2", Status: model.Created}},
+				{SourceID: "trello:c", Todo: &model.Todo{Task: "again", Status: model.Created}},
+			})
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if created != tt.created {
+				t.Errorf("want = %v, got = %v", tt.created, created)
+			}
+			var got []*model.TodoSource
+			for _, s := range sources.sources {
+				if s.WorkspaceID == actor.WorkspaceID {
+					got = append(got, s)
+				}
+			}
+			if !cmp.Equal(got, tt.sources) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.sources))
+			}
+		})
+	}
+}
+
+func TestTodoImportFromLists(t *testing.T) {
+	t.Parallel()
+	todos := &batchTodo{mockTodo: &mockTodo{}}
+	tx := transactionOf(todos)
+	lists := &mockList{mockFind: findList, lists: []*model.List{{ID: 3, WorkspaceID: actor.WorkspaceID, Name: "買い物"}}}
+	tx.repositories.List = lists
+	tx.repositories.TodoSource = &mockTodoSource{}
+	u := usecase.NewTodo(todos, &mockTodoHistory{}, memberOf(model.Editor), tx, usecase.NewTodoStream(0))
+
+	imported := []usecase.SourcedTodo{
+		{SourceID: "todoist:1", List: "買い物", Todo: &model.Todo{Task: "milk", Status: model.Created}},
+		{SourceID: "todoist:2", List: "仕事", Todo: &model.Todo{Task: "report", Status: model.Created}},
+		{SourceID: "todoist:3", List: "仕事", Todo: &model.Todo{Task: "meeting", Status: model.Done}},
+		{SourceID: "todoist:4", Todo: &model.Todo{Task: "inbox", Status: model.Created}},
+	}
+	if _, err := u.ImportFrom(context.Background(), actor, imported); err != nil {
+		t.Fatal(err)
+	}
+	// 既存のリストは名前で再利用され、ないリストは一度だけ作成される
+	want := []int{3, 1, 1, 0}
+	var got []int
+	for _, s := range imported {
+		got = append(got, s.Todo.ListID)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("diff %s", cmp.Diff(got, want))
+	}
+	wantLists := []*model.List{{ID: 1, WorkspaceID: actor.WorkspaceID, Name: "仕事"}}
+	if !cmp.Equal(lists.created, wantLists) {
+		t.Errorf("diff %s", cmp.Diff(lists.created, wantLists))
+	}
+}
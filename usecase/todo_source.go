@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TodoSource reads the links of todos to the IDs they have in other apps.
+type TodoSource interface {
+	// Find returns the links of the source IDs in the actor's workspace.
+	Find(ctx context.Context, actor model.Actor, sourceIDs []string) ([]*model.TodoSource, error)
+	// FindByTodoIDs returns the links of the todos, also of deleted ones.
+	FindByTodoIDs(ctx context.Context, actor model.Actor, todoIDs []int) ([]*model.TodoSource, error)
+}
+
+type todoSource struct {
+	sourceRepository repository.TodoSource
+	memberRepository repository.Member
+}
+
+func NewTodoSource(s repository.TodoSource, m repository.Member) TodoSource {
+	return &todoSource{s, m}
+}
+
+func (s *todoSource) Find(ctx context.Context, actor model.Actor, sourceIDs []string) ([]*model.TodoSource, error) {
+	if err := authorize(ctx, s.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	return s.sourceRepository.FindAll(ctx, actor.WorkspaceID, sourceIDs)
+}
+
+func (s *todoSource) FindByTodoIDs(ctx context.Context, actor model.Actor, todoIDs []int) ([]*model.TodoSource, error) {
+	if err := authorize(ctx, s.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	return s.sourceRepository.FindByTodoIDs(ctx, actor.WorkspaceID, todoIDs)
+}
+
+// CreateFrom creates the todo in the actor's workspace and its list, and
+// links it to the source IDs. A link of a deleted todo is taken over;
+// ErrConflict means a source ID is linked to a todo that still exists.
+func (t *todo) CreateFrom(ctx context.Context, actor model.Actor, todo *model.Todo, sourceIDs []string) error {
+	if err := authorize(ctx, t.memberRepository, actor, model.WriteTodo); err != nil {
+		return err
+	}
+	todo.WorkspaceID = actor.WorkspaceID
+	err := t.transaction.Do(ctx, func(r repository.Repositories) error {
+		if err := lockList(ctx, r.List, actor, todo.ListID); err != nil {
+			return err
+		}
+		sources, err := r.TodoSource.FindAll(ctx, actor.WorkspaceID, sourceIDs)
+		if err != nil {
+			return err
+		}
+		linked := map[string]*model.TodoSource{}
+		for _, s := range sources {
+			current, err := r.Todo.Find(ctx, s.TodoID)
+			if err != nil {
+				return err
+			}
+			if current != nil {
+				return fmt.Errorf("%w: %s is linked to todo %d", ErrConflict, s.SourceID, s.TodoID)
+			}
+			linked[s.SourceID] = s
+		}
+		if err := t.create(ctx, r, actor, todo); err != nil {
+			return err
+		}
+		var news []*model.TodoSource
+		for _, id := range sourceIDs {
+			s, ok := linked[id]
+			if !ok {
+				news = append(news, &model.TodoSource{WorkspaceID: actor.WorkspaceID, SourceID: id, TodoID: todo.ID})
+				continue
+			}
+			from := s.TodoID
+			s.TodoID = todo.ID
+			relinked, err := r.TodoSource.Relink(ctx, s, from)
+			if err != nil {
+				return err
+			}
+			if !relinked {
+				return fmt.Errorf("%w: %s was linked to another todo at the same time", ErrConflict, s.SourceID)
+			}
+		}
+		return r.TodoSource.Create(ctx, news...)
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTodoCreateFrom(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		member   repository.Member
+		linked   *model.TodoSource
+		existing *model.Todo
+		stale    bool
+		sources  []*model.TodoSource
+		err      error
+	}{
+		{
+			name:   "正常系_タスクが登録されインポート元のIDがリンクされること",
+			member: memberOf(model.Editor),
+			sources: []*model.TodoSource{
+				{WorkspaceID: 1, SourceID: "caldav:a.ics", TodoID: 100},
+				{WorkspaceID: 1, SourceID: "ical:x@example.com", TodoID: 100},
+			},
+		},
+		{
+			name:   "正常系_削除されたタスクのリンクが付け替えられること",
+			member: memberOf(model.Editor),
+			linked: &model.TodoSource{ID: 1, WorkspaceID: 1, SourceID: "caldav:a.ics", TodoID: 5},
+			sources: []*model.TodoSource{
+				{ID: 1, WorkspaceID: 1, SourceID: "caldav:a.ics", TodoID: 100},
+				{WorkspaceID: 1, SourceID: "ical:x@example.com", TodoID: 100},
+			},
+		},
+		{
+			name:     "異常系_存在するタスクにリンクされている場合競合エラーになること",
+			member:   memberOf(model.Editor),
+			linked:   &model.TodoSource{ID: 1, WorkspaceID: 1, SourceID: "caldav:a.ics", TodoID: 5},
+			existing: &model.Todo{ID: 5, WorkspaceID: 1},
+			err:      fmt.Errorf("%w: caldav:a.ics is linked to todo 5", usecase.ErrConflict),
+		},
+		{
+			name:   "異常系_同時にリンクが付け替えられた場合競合エラーになること",
+			member: memberOf(model.Editor),
+			linked: &model.TodoSource{ID: 1, WorkspaceID: 1, SourceID: "caldav:a.ics", TodoID: 5},
+			stale:  true,
+			err:    fmt.Errorf("%w: caldav:a.ics was linked to another todo at the same time", usecase.ErrConflict),
+		},
+		{
+			name:   "異常系_閲覧者は登録できないこと",
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := &batchTodo{mockTodo: &mockTodo{
+				mockFind: func() (*model.Todo, error) { return tt.existing, nil },
+			}}
+			tx := transactionOf(todos)
+			sources := &mockTodoSource{stale: tt.stale}
+			if tt.linked != nil {
+				sources.sources = []*model.TodoSource{tt.linked}
+			}
+			tx.repositories.TodoSource = sources
+			u := usecase.NewTodo(todos, &mockTodoHistory{}, tt.member, tx, usecase.NewTodoStream(0))
+
+			todo := &model.Todo{Task: "task", Status: model.Created}
+			err := u.CreateFrom(context.Background(), actor, todo, []string{"caldav:a.ics", "ical:x@example.com"})
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if todo.ID != 100 || todo.WorkspaceID != actor.WorkspaceID {
+				t.Errorf("unexpected todo: %+v", todo)
+			}
+			if !cmp.Equal(sources.sources, tt.sources) {
+				t.Errorf("diff %s", cmp.Diff(sources.sources, tt.sources))
+			}
+		})
+	}
+}
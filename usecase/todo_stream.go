@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"context"
+	"sync"
+)
+
+type TodoEventType string
+
+const (
+	TodoCreated TodoEventType = "created"
+	TodoUpdated TodoEventType = "updated"
+	TodoDeleted TodoEventType = "deleted"
+)
+
+type TodoEvent struct {
+	ID   uint64
+	Type TodoEventType
+	Todo model.Todo
+	// FromListID is the list the todo was in before an update, so that a
+	// subscriber of that list learns that the todo left it.
+	FromListID int
+}
+
+// TodoStream fans todo changes out to subscribers and keeps the latest events
+// so that a reconnecting subscriber can catch up from the last id it saw.
+type TodoStream interface {
+	Publish(t TodoEventType, todo model.Todo)
+	Subscribe(workspaceID int, lastEventID uint64) (replay []TodoEvent, events <-chan TodoEvent, cancel func())
+	// Handle publishes the todo domain events; it is subscribed to the event bus.
+	Handle(ctx context.Context, e event.Event) error
+}
+
+const todoSubscriberBuffer = 64
+
+type todoSubscriber struct {
+	workspaceID int
+	events      chan TodoEvent
+}
+
+type todoStream struct {
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []TodoEvent
+	size        int
+	subscribers map[*todoSubscriber]struct{}
+}
+
+func NewTodoStream(size int) TodoStream {
+	return &todoStream{
+		size:        size,
+		subscribers: map[*todoSubscriber]struct{}{},
+	}
+}
+
+func (s *todoStream) Publish(t TodoEventType, todo model.Todo) {
+	s.publish(TodoEvent{Type: t, Todo: todo, FromListID: todo.ListID})
+}
+
+func (s *todoStream) publish(e TodoEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	e.ID = s.lastID
+	if s.size > 0 {
+		if len(s.buffer) == s.size {
+			s.buffer = s.buffer[1:]
+		}
+		s.buffer = append(s.buffer, e)
+	}
+	for sub := range s.subscribers {
+		if sub.workspaceID != e.Todo.WorkspaceID {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			// A subscriber that cannot keep up is dropped; it reconnects with
+			// Last-Event-ID and catches up from the buffer.
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (s *todoStream) Handle(ctx context.Context, e event.Event) error {
+	switch e := e.(type) {
+	case event.TodoCreated:
+		s.Publish(TodoCreated, e.Todo)
+	case event.TodoUpdated:
+		s.publish(TodoEvent{Type: TodoUpdated, Todo: e.After, FromListID: e.Before.ListID})
+	case event.TodoDeleted:
+		s.Publish(TodoDeleted, e.Todo)
+	}
+	return nil
+}
+
+func (s *todoStream) Subscribe(workspaceID int, lastEventID uint64) ([]TodoEvent, <-chan TodoEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var replay []TodoEvent
+	if lastEventID > 0 {
+		for _, e := range s.buffer {
+			if e.ID > lastEventID && e.Todo.WorkspaceID == workspaceID {
+				replay = append(replay, e)
+			}
+		}
+	}
+	sub := &todoSubscriber{workspaceID: workspaceID, events: make(chan TodoEvent, todoSubscriberBuffer)}
+	s.subscribers[sub] = struct{}{}
+	cancel := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[sub]; ok {
+			delete(s.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return replay, sub.events, cancel
+}
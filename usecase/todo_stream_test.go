@@ -0,0 +1,113 @@
+package usecase_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/usecase"
+	"context"
+	"testing"
+)
+
+func TestTodoStream(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_同じワークスペースの変更のみ配信されること", func(t *testing.T) {
+		s := usecase.NewTodoStream(10)
+		_, events, cancel := s.Subscribe(1, 0)
+		defer cancel()
+
+		s.Publish(usecase.TodoCreated, model.Todo{ID: 1, WorkspaceID: 2})
+		s.Publish(usecase.TodoCreated, model.Todo{ID: 2, WorkspaceID: 1})
+
+		e := <-events
+		if e.Todo.ID != 2 || e.ID != 2 || e.Type != usecase.TodoCreated {
+			t.Errorf("unexpected event: %+v", e)
+		}
+		select {
+		case e := <-events:
+			t.Errorf("unexpected event: %+v", e)
+		default:
+		}
+	})
+	t.Run("正常系_Last-Event-ID以降のイベントが再送されること", func(t *testing.T) {
+		s := usecase.NewTodoStream(2)
+		s.Publish(usecase.TodoCreated, model.Todo{ID: 1, WorkspaceID: 1})
+		s.Publish(usecase.TodoUpdated, model.Todo{ID: 1, WorkspaceID: 1})
+		s.Publish(usecase.TodoDeleted, model.Todo{ID: 1, WorkspaceID: 1})
+
+		replay, _, cancel := s.Subscribe(1, 1)
+		defer cancel()
+		if len(replay) != 2 || replay[0].ID != 2 || replay[1].ID != 3 {
+			t.Errorf("unexpected replay: %+v", replay)
+		}
+
+		replay, _, cancel = s.Subscribe(1, 0)
+		defer cancel()
+		if len(replay) != 0 {
+			t.Errorf("want no replay without Last-Event-ID, got = %+v", replay)
+		}
+	})
+	t.Run("異常系_受信が追いつかない購読者は切断されること", func(t *testing.T) {
+		s := usecase.NewTodoStream(0)
+		_, events, cancel := s.Subscribe(1, 0)
+		defer cancel()
+
+		for i := 0; i < 100; i++ {
+			s.Publish(usecase.TodoCreated, model.Todo{ID: i, WorkspaceID: 1})
+		}
+		n := 0
+		for range events {
+			n++
+		}
+		if n == 0 || n >= 100 {
+			t.Errorf("want the subscriber dropped after its buffer filled, got %d events", n)
+		}
+	})
+}
+
+func TestTodoStreamHandle(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_ドメインイベントが変更イベントとして配信されること", func(t *testing.T) {
+		s := usecase.NewTodoStream(10)
+		_, events, cancel := s.Subscribe(1, 0)
+		defer cancel()
+		todo := model.Todo{ID: 1, WorkspaceID: 1, Status: model.Done}
+
+		for _, e := range []event.Event{
+			event.TodoCreated{Todo: todo},
+			event.TodoUpdated{After: todo},
+			event.TodoStatusChanged{Todo: todo, From: model.Created, To: model.Done},
+			event.TodoDeleted{Todo: todo},
+		} {
+			if err := s.Handle(context.Background(), e); err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+		}
+		for _, want := range []usecase.TodoEventType{usecase.TodoCreated, usecase.TodoUpdated, usecase.TodoDeleted} {
+			if e := <-events; e.Type != want {
+				t.Errorf("want = %v, got = %v", want, e.Type)
+			}
+		}
+		select {
+		case e := <-events:
+			t.Errorf("unexpected event: %+v", e)
+		default:
+		}
+	})
+	t.Run("正常系_更新イベントに移動前のリストが含まれること", func(t *testing.T) {
+		s := usecase.NewTodoStream(10)
+		_, events, cancel := s.Subscribe(1, 0)
+		defer cancel()
+
+		e := event.TodoUpdated{
+			Before: model.Todo{ID: 1, WorkspaceID: 1, ListID: 2},
+			After:  model.Todo{ID: 1, WorkspaceID: 1, ListID: 3},
+		}
+		if err := s.Handle(context.Background(), e); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		got := <-events
+		if got.FromListID != 2 || got.Todo.ListID != 3 {
+			t.Errorf("want list 2 -> 3, got = %d -> %d", got.FromListID, got.Todo.ListID)
+		}
+	})
+}
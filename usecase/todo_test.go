@@ -1,46 +1,164 @@
 package usecase_test
 
 import (
+	"app/domain/event"
 	"app/domain/model"
 	"app/domain/repository"
 	"app/usecase"
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
 
 type mockTodo struct {
 	repository.Todo
-	mockCreate  func() error
-	mockDelete  func() error
-	mockUpdate  func() error
-	mockFind    func() (*model.Todo, error)
-	mockFindAll func() ([]*model.Todo, error)
+	mockCreate          func() error
+	mockDelete          func() error
+	mockUpdate          func() error
+	mockFind            func() (*model.Todo, error)
+	mockFindAll         func() ([]*model.Todo, error)
+	mockFindByIDs       func() ([]*model.Todo, error)
+	mockSearch          func() ([]*model.Todo, error)
+	mockSearchForUpdate func() ([]*model.Todo, error)
+	mockCount           func() (int64, error)
 }
 
-func (m *mockTodo) Create(t *model.Todo) error {
+func (m *mockTodo) Create(ctx context.Context, t *model.Todo) error {
 	return m.mockCreate()
 }
-func (m *mockTodo) Delete(id int) error {
+func (m *mockTodo) CreateBatch(ctx context.Context, todos []*model.Todo) error {
+	return m.mockCreate()
+}
+func (m *mockTodo) Delete(ctx context.Context, id int) error {
 	return m.mockDelete()
 }
-func (m *mockTodo) Update(t *model.Todo) error {
+func (m *mockTodo) Update(ctx context.Context, t *model.Todo) error {
+	return m.mockUpdate()
+}
+func (m *mockTodo) Find(ctx context.Context, id int) (*model.Todo, error) {
+	return m.mockFind()
+}
+func (m *mockTodo) FindForUpdate(ctx context.Context, id int) (*model.Todo, error) {
+	return m.mockFind()
+}
+func (m *mockTodo) FindAll(ctx context.Context, workspaceID int) ([]*model.Todo, error) {
+	return m.mockFindAll()
+}
+func (m *mockTodo) FindByIDs(ctx context.Context, ids []int) ([]*model.Todo, error) {
+	return m.mockFindByIDs()
+}
+func (m *mockTodo) FindByIDsForUpdate(ctx context.Context, ids []int) ([]*model.Todo, error) {
+	return m.mockFindByIDs()
+}
+func (m *mockTodo) Search(ctx context.Context, workspaceID int, f model.TodoFilter) ([]*model.Todo, error) {
+	return m.mockSearch()
+}
+func (m *mockTodo) SearchForUpdate(ctx context.Context, workspaceID int, f model.TodoFilter) ([]*model.Todo, error) {
+	return m.mockSearchForUpdate()
+}
+func (m *mockTodo) Count(ctx context.Context, workspaceID int, f model.TodoFilter) (int64, error) {
+	return m.mockCount()
+}
+
+type mockMember struct {
+	repository.Member
+	mockCreate  func() error
+	mockUpdate  func() error
+	mockDelete  func() error
+	mockFind    func() (*model.Member, error)
+	mockFindAll func() ([]*model.Member, error)
+}
+
+func (m *mockMember) Create(ctx context.Context, mb *model.Member) error {
+	return m.mockCreate()
+}
+func (m *mockMember) Update(ctx context.Context, mb *model.Member) error {
 	return m.mockUpdate()
 }
-func (m *mockTodo) Find(id int) (*model.Todo, error) {
+func (m *mockMember) Delete(ctx context.Context, workspaceID int, userID int) error {
+	return m.mockDelete()
+}
+func (m *mockMember) Find(ctx context.Context, workspaceID int, userID int) (*model.Member, error) {
 	return m.mockFind()
 }
-func (m *mockTodo) FindAll() ([]*model.Todo, error) {
+func (m *mockMember) FindAll(ctx context.Context, workspaceID int) ([]*model.Member, error) {
 	return m.mockFindAll()
 }
 
+func memberOf(role model.Role) *mockMember {
+	return &mockMember{
+		mockFind: func() (*model.Member, error) {
+			return model.NewMember(1, 1, role), nil
+		},
+	}
+}
+
+// mockTransaction はトランザクションを張らずに渡されたリポジトリで関数を実行する
+type mockTransaction struct {
+	repositories repository.Repositories
+}
+
+func (m *mockTransaction) Do(ctx context.Context, fn func(r repository.Repositories) error) error {
+	return fn(m.repositories)
+}
+
+func transactionOf(r repository.Todo) *mockTransaction {
+	tx := &mockTransaction{repository.Repositories{Todo: r, List: &mockList{mockFind: findList}, TodoHistory: &mockTodoHistory{}, Outbox: &mockOutbox{}}}
+	tx.repositories.Transaction = tx
+	return tx
+}
+
+// mockTodoHistory は記録された変更履歴を保持する
+type mockTodoHistory struct {
+	repository.TodoHistory
+	created     []*model.TodoHistory
+	revisions   []*model.TodoHistory
+	mockFindAll func(limit int, offset int) ([]*model.TodoHistory, error)
+}
+
+func (m *mockTodoHistory) Revisions(ctx context.Context, workspaceID int, todoID int) ([]*model.TodoHistory, error) {
+	return m.revisions, nil
+}
+func (m *mockTodoHistory) RevisionsForUpdate(ctx context.Context, workspaceID int, todoID int) ([]*model.TodoHistory, error) {
+	return m.revisions, nil
+}
+
+func (m *mockTodoHistory) Create(ctx context.Context, histories ...*model.TodoHistory) error {
+	m.created = append(m.created, histories...)
+	return nil
+}
+func (m *mockTodoHistory) FindAll(ctx context.Context, workspaceID int, todoID int, limit int, offset int) ([]*model.TodoHistory, error) {
+	return m.mockFindAll(limit, offset)
+}
+
+// mockPublisher は発行されたドメインイベントを記録する
+type mockPublisher struct {
+	events []event.Event
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, events ...event.Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+var actor = model.NewActor(1, 1)
+
+func findInWorkspace() (*model.Todo, error) {
+	return &model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID}, nil
+}
+
 func TestCreate(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		name       string
 		task       string
+		listID     int
 		repository repository.Todo
+		member     repository.Member
 		err        error
 	}{
 		{
@@ -51,7 +169,20 @@ func TestCreate(t *testing.T) {
 					return nil
 				},
 			},
-			err: nil,
+			member: memberOf(model.Editor),
+			err:    nil,
+		},
+		{
+			name:   "正常系_リストを指定してタスクの登録ができること",
+			task:   "task",
+			listID: 3,
+			repository: &mockTodo{
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			member: memberOf(model.Editor),
+			err:    nil,
 		},
 		{
 			name: "異常系_タスクの登録に失敗した場合エラーが返ること",
@@ -61,16 +192,43 @@ func TestCreate(t *testing.T) {
 					return errors.New("xxxx error")
 				},
 			},
-			err: errors.New("xxxx error"),
+			member: memberOf(model.Editor),
+			err:    errors.New("xxxx error"),
+		},
+		{
+			name: "異常系_閲覧者はタスクの登録ができないこと",
+			task: "task",
+			repository: &mockTodo{
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+		{
+			name: "異常系_ワークスペースのメンバーでない場合タスクの登録ができないこと",
+			task: "task",
+			repository: &mockTodo{
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return nil, nil
+				},
+			},
+			err: usecase.ErrForbidden,
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			u := usecase.NewTodo(tt.repository)
+			u := usecase.NewTodo(tt.repository, &mockTodoHistory{}, tt.member, transactionOf(tt.repository), usecase.NewTodoStream(0))
 
-			got := u.Create(tt.task)
+			got := u.Create(context.Background(), actor, tt.task, tt.listID)
 			if !equalError(got, tt.err) {
 				t.Errorf("different than expected...")
 			}
@@ -86,6 +244,7 @@ func TestUpdate(t *testing.T) {
 		task       string
 		status     model.TaskStatus
 		repository repository.Todo
+		member     repository.Member
 		err        error
 	}{
 		{
@@ -94,11 +253,13 @@ func TestUpdate(t *testing.T) {
 			task:   "task",
 			status: model.Created,
 			repository: &mockTodo{
+				mockFind: findInWorkspace,
 				mockUpdate: func() error {
 					return nil
 				},
 			},
-			err: nil,
+			member: memberOf(model.Editor),
+			err:    nil,
 		},
 		{
 			name:   "異常系_タスクの更新に失敗した場合エラーが返ること",
@@ -106,20 +267,52 @@ func TestUpdate(t *testing.T) {
 			task:   "task",
 			status: model.Created,
 			repository: &mockTodo{
+				mockFind: findInWorkspace,
 				mockUpdate: func() error {
 					return errors.New("xxxx error")
 				},
 			},
-			err: errors.New("xxxx error"),
+			member: memberOf(model.Editor),
+			err:    errors.New("xxxx error"),
+		},
+		{
+			name:   "異常系_閲覧者はタスクの更新ができないこと",
+			id:     1,
+			task:   "task",
+			status: model.Done,
+			repository: &mockTodo{
+				mockFind: findInWorkspace,
+				mockUpdate: func() error {
+					return nil
+				},
+			},
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+		{
+			name:   "異常系_他のワークスペースのタスクは更新できないこと",
+			id:     1,
+			task:   "task",
+			status: model.Done,
+			repository: &mockTodo{
+				mockFind: func() (*model.Todo, error) {
+					return &model.Todo{ID: 1, WorkspaceID: 2}, nil
+				},
+				mockUpdate: func() error {
+					return nil
+				},
+			},
+			member: memberOf(model.Editor),
+			err:    usecase.ErrNotFound,
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			u := usecase.NewTodo(tt.repository)
+			u := usecase.NewTodo(tt.repository, &mockTodoHistory{}, tt.member, transactionOf(tt.repository), usecase.NewTodoStream(0))
 
-			got := u.Update(tt.id, tt.task, tt.status)
+			got := u.Update(context.Background(), actor, tt.id, tt.task, tt.status)
 			if !equalError(got, tt.err) {
 				t.Errorf("different than expected...")
 			}
@@ -133,36 +326,53 @@ func TestDelete(t *testing.T) {
 		name       string
 		id         int
 		repository repository.Todo
+		member     repository.Member
 		err        error
 	}{
 		{
 			name: "正常系_タスクの削除ができること",
 			id:   1,
 			repository: &mockTodo{
+				mockFind: findInWorkspace,
 				mockDelete: func() error {
 					return nil
 				},
 			},
-			err: nil,
+			member: memberOf(model.Editor),
+			err:    nil,
 		},
 		{
 			name: "異常系_タスクの削除に失敗した場合エラーが返ること",
 			id:   1,
 			repository: &mockTodo{
+				mockFind: findInWorkspace,
 				mockDelete: func() error {
 					return errors.New("xxxx error")
 				},
 			},
-			err: errors.New("xxxx error"),
+			member: memberOf(model.Editor),
+			err:    errors.New("xxxx error"),
+		},
+		{
+			name: "異常系_閲覧者はタスクの削除ができないこと",
+			id:   1,
+			repository: &mockTodo{
+				mockFind: findInWorkspace,
+				mockDelete: func() error {
+					return nil
+				},
+			},
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
 		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			u := usecase.NewTodo(tt.repository)
+			u := usecase.NewTodo(tt.repository, &mockTodoHistory{}, tt.member, transactionOf(tt.repository), usecase.NewTodoStream(0))
 
-			got := u.Delete(tt.id)
+			got := u.Delete(context.Background(), actor, tt.id)
 			if !equalError(got, tt.err) {
 				t.Errorf("different than expected...")
 			}
@@ -170,17 +380,85 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestUpdateIfAndDeleteIf(t *testing.T) {
+	t.Parallel()
+	unchanged := func(current *model.Todo) bool { return current != nil && current.Task == "task" }
+	tests := []struct {
+		name  string
+		find  func() (*model.Todo, error)
+		match func(current *model.Todo) bool
+		err   error
+	}{
+		{
+			name: "正常系_ロックしたタスクが条件に合う場合書き込まれること",
+			find: func() (*model.Todo, error) {
+				return &model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID, Task: "task"}, nil
+			},
+			match: unchanged,
+		},
+		{
+			name: "異常系_ロックしたタスクが条件に合わない場合ErrPreconditionFailedになること",
+			find: func() (*model.Todo, error) {
+				return &model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID, Task: "changed"}, nil
+			},
+			match: unchanged,
+			err:   usecase.ErrPreconditionFailed,
+		},
+		{
+			name:  "異常系_タスクが削除されていて条件に合わない場合ErrPreconditionFailedになること",
+			find:  func() (*model.Todo, error) { return nil, nil },
+			match: unchanged,
+			err:   usecase.ErrPreconditionFailed,
+		},
+		{
+			name:  "異常系_タスクが削除されていて条件がない場合ErrNotFoundになること",
+			find:  func() (*model.Todo, error) { return nil, nil },
+			match: func(current *model.Todo) bool { return true },
+			err:   usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var written []string
+			r := &mockTodo{
+				mockFind:   tt.find,
+				mockUpdate: func() error { written = append(written, "update"); return nil },
+				mockDelete: func() error { written = append(written, "delete"); return nil },
+			}
+			u := usecase.NewTodo(r, &mockTodoHistory{}, memberOf(model.Editor), transactionOf(r), usecase.NewTodoStream(0))
+
+			if err := u.UpdateIf(context.Background(), actor, 1, "new", model.Done, tt.match); !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err := u.DeleteIf(context.Background(), actor, 1, tt.match); !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			want := []string{"update", "delete"}
+			if tt.err != nil {
+				want = nil
+			}
+			if !cmp.Equal(written, want) {
+				t.Errorf("want = %v, got = %v", want, written)
+			}
+		})
+	}
+}
+
 func TestFind(t *testing.T) {
 	t.Parallel()
 	td := model.Todo{
-		ID:     1,
-		Task:   "task",
-		Status: model.Created,
+		ID:          1,
+		WorkspaceID: actor.WorkspaceID,
+		Task:        "task",
+		Status:      model.Created,
 	}
 	tests := []struct {
 		name       string
 		id         int
 		repository repository.Todo
+		member     repository.Member
 		expected   *model.Todo
 		err        error
 	}{
@@ -190,16 +468,19 @@ func TestFind(t *testing.T) {
 			repository: &mockTodo{
 				mockFind: func() (*model.Todo, error) {
 					return &model.Todo{
-						ID:     td.ID,
-						Task:   td.Task,
-						Status: td.Status,
+						ID:          td.ID,
+						WorkspaceID: td.WorkspaceID,
+						Task:        td.Task,
+						Status:      td.Status,
 					}, nil
 				},
 			},
+			member: memberOf(model.Editor),
 			expected: &model.Todo{
-				ID:     td.ID,
-				Task:   td.Task,
-				Status: td.Status,
+				ID:          td.ID,
+				WorkspaceID: td.WorkspaceID,
+				Task:        td.Task,
+				Status:      td.Status,
 			},
 			err: nil,
 		},
@@ -211,17 +492,30 @@ func TestFind(t *testing.T) {
 					return nil, errors.New("xxxx error")
 				},
 			},
+			member:   memberOf(model.Editor),
 			expected: nil,
 			err:      errors.New("xxxx error"),
 		},
+		{
+			name: "正常系_他のワークスペースのタスクは検索結果に含まれないこと",
+			id:   1,
+			repository: &mockTodo{
+				mockFind: func() (*model.Todo, error) {
+					return &model.Todo{ID: 1, WorkspaceID: 2}, nil
+				},
+			},
+			member:   memberOf(model.Viewer),
+			expected: nil,
+			err:      nil,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			u := usecase.NewTodo(tt.repository)
+			u := usecase.NewTodo(tt.repository, &mockTodoHistory{}, tt.member, transactionOf(tt.repository), usecase.NewTodoStream(0))
 
-			got, err := u.Find(tt.id)
+			got, err := u.Find(context.Background(), actor, tt.id)
 			if !cmp.Equal(got, tt.expected) {
 				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
 			}
@@ -235,13 +529,15 @@ func TestFind(t *testing.T) {
 func TestFindAll(t *testing.T) {
 	t.Parallel()
 	td := model.Todo{
-		ID:     1,
-		Task:   "task",
-		Status: model.Created,
+		ID:          1,
+		WorkspaceID: actor.WorkspaceID,
+		Task:        "task",
+		Status:      model.Created,
 	}
 	tests := []struct {
 		name       string
 		repository repository.Todo
+		member     repository.Member
 		expected   []*model.Todo
 		err        error
 	}{
@@ -254,6 +550,7 @@ func TestFindAll(t *testing.T) {
 					}, nil
 				},
 			},
+			member:   memberOf(model.Editor),
 			expected: []*model.Todo{&td},
 			err:      nil,
 		},
@@ -264,6 +561,7 @@ func TestFindAll(t *testing.T) {
 					return nil, errors.New("xxxx error")
 				},
 			},
+			member:   memberOf(model.Editor),
 			expected: nil,
 			err:      errors.New("xxxx error"),
 		},
@@ -272,9 +570,75 @@ func TestFindAll(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			u := usecase.NewTodo(tt.repository)
+			u := usecase.NewTodo(tt.repository, &mockTodoHistory{}, tt.member, transactionOf(tt.repository), usecase.NewTodoStream(0))
 
-			got, err := u.FindAll()
+			got, err := u.FindAll(context.Background(), actor)
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+			if !equalError(err, tt.err) {
+				t.Errorf("different than expected...")
+			}
+		})
+	}
+}
+
+func TestFindByIDs(t *testing.T) {
+	t.Parallel()
+	td := model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID, Task: "task", Status: model.Created}
+	other := model.Todo{ID: 2, WorkspaceID: 2, Task: "other", Status: model.Created}
+	tests := []struct {
+		name       string
+		repository repository.Todo
+		member     repository.Member
+		expected   []*model.Todo
+		err        error
+	}{
+		{
+			name: "正常系_他のワークスペースのタスクが除外されること",
+			repository: &mockTodo{
+				mockFindByIDs: func() ([]*model.Todo, error) {
+					return []*model.Todo{&td, &other}, nil
+				},
+			},
+			member:   memberOf(model.Viewer),
+			expected: []*model.Todo{&td},
+			err:      nil,
+		},
+		{
+			name: "異常系_タスクの検索に失敗した場合エラーが返ること",
+			repository: &mockTodo{
+				mockFindByIDs: func() ([]*model.Todo, error) {
+					return nil, errors.New("xxxx error")
+				},
+			},
+			member:   memberOf(model.Viewer),
+			expected: nil,
+			err:      errors.New("xxxx error"),
+		},
+		{
+			name: "異常系_ワークスペースのメンバーでない場合検索できないこと",
+			repository: &mockTodo{
+				mockFindByIDs: func() ([]*model.Todo, error) {
+					return []*model.Todo{&td}, nil
+				},
+			},
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return nil, nil
+				},
+			},
+			expected: nil,
+			err:      usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewTodo(tt.repository, &mockTodoHistory{}, tt.member, transactionOf(tt.repository), usecase.NewTodoStream(0))
+
+			got, err := u.FindByIDs(context.Background(), actor, []int{1, 2})
 			if !cmp.Equal(got, tt.expected) {
 				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
 			}
@@ -288,3 +652,529 @@ func TestFindAll(t *testing.T) {
 func equalError(a, b error) bool {
 	return a == nil && b == nil || a != nil && b != nil && a.Error() == b.Error()
 }
+
+func TestTodoEvents(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		run        func(u usecase.Todo) error
+		repository repository.Todo
+		expected   []string
+	}{
+		{
+			name: "正常系_登録と同じトランザクションでTodoCreatedが保存されること",
+			run:  func(u usecase.Todo) error { return u.Create(context.Background(), actor, "task", 0) },
+			repository: &mockTodo{
+				mockCreate: func() error { return nil },
+			},
+			expected: []string{event.TodoCreatedName},
+		},
+		{
+			name: "正常系_ステータスが変わった場合TodoStatusChangedも保存されること",
+			run:  func(u usecase.Todo) error { return u.Update(context.Background(), actor, 1, "task", model.Done) },
+			repository: &mockTodo{
+				mockFind:   findInWorkspace,
+				mockUpdate: func() error { return nil },
+			},
+			expected: []string{event.TodoUpdatedName, event.TodoStatusChangedName},
+		},
+		{
+			name: "正常系_ステータスが変わらない場合TodoUpdatedのみ保存されること",
+			run:  func(u usecase.Todo) error { return u.Update(context.Background(), actor, 1, "task", "") },
+			repository: &mockTodo{
+				mockFind:   findInWorkspace,
+				mockUpdate: func() error { return nil },
+			},
+			expected: []string{event.TodoUpdatedName},
+		},
+		{
+			name: "正常系_削除と同じトランザクションでTodoDeletedが保存されること",
+			run:  func(u usecase.Todo) error { return u.Delete(context.Background(), actor, 1) },
+			repository: &mockTodo{
+				mockFind:   findInWorkspace,
+				mockDelete: func() error { return nil },
+			},
+			expected: []string{event.TodoDeletedName},
+		},
+		{
+			name: "異常系_更新に失敗した場合イベントが保存されないこと",
+			run:  func(u usecase.Todo) error { return u.Update(context.Background(), actor, 1, "task", model.Done) },
+			repository: &mockTodo{
+				mockFind:   findInWorkspace,
+				mockUpdate: func() error { return errors.New("xxxx error") },
+			},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			outbox := &mockOutbox{}
+			history := &mockTodoHistory{}
+			tx := &mockTransaction{repository.Repositories{Todo: tt.repository, TodoHistory: history, Outbox: outbox}}
+			u := usecase.NewTodo(tt.repository, history, memberOf(model.Editor), tx, usecase.NewTodoStream(0))
+
+			_ = tt.run(u)
+			var got []string
+			for _, e := range outbox.stored {
+				got = append(got, e.Name())
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+			if want := len(tt.expected) > 0; (len(history.created) == 1) != want {
+				t.Errorf("want history recorded = %v, got = %v", want, history.created)
+			}
+		})
+	}
+}
+
+func TestMove(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		listID      int
+		find        func() (*model.Todo, error)
+		findList    func() (*model.List, error)
+		err         error
+		wantChanges model.FieldChanges
+	}{
+		{
+			name:        "正常系_タスクをリストに移動すると変更履歴とイベントが保存されること",
+			listID:      3,
+			find:        findInWorkspace,
+			findList:    findList,
+			wantChanges: model.FieldChanges{{Field: "list_id", Old: "", New: "3"}},
+		},
+		{
+			name:   "正常系_リストから外せること",
+			listID: 0,
+			find: func() (*model.Todo, error) {
+				return &model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID, ListID: 3}, nil
+			},
+			wantChanges: model.FieldChanges{{Field: "list_id", Old: "3", New: ""}},
+		},
+		{
+			name:   "正常系_同じリストへの移動では何も保存されないこと",
+			listID: 3,
+			find: func() (*model.Todo, error) {
+				return &model.Todo{ID: 1, WorkspaceID: actor.WorkspaceID, ListID: 3}, nil
+			},
+		},
+		{
+			name:   "異常系_他のワークスペースのリストには移動できないこと",
+			listID: 3,
+			find:   findInWorkspace,
+			findList: func() (*model.List, error) {
+				return &model.List{ID: 3, WorkspaceID: 2}, nil
+			},
+			err: fmt.Errorf("list 3: %w", usecase.ErrNotFound),
+		},
+		{
+			name:   "異常系_他のワークスペースのタスクは移動できないこと",
+			listID: 3,
+			find: func() (*model.Todo, error) {
+				return &model.Todo{ID: 1, WorkspaceID: 2}, nil
+			},
+			findList: findList,
+			err:      usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := &mockTodo{mockFind: tt.find, mockUpdate: func() error { return nil }}
+			outbox := &mockOutbox{}
+			history := &mockTodoHistory{}
+			tx := &mockTransaction{repository.Repositories{Todo: todos, List: &mockList{mockFind: tt.findList}, TodoHistory: history, Outbox: outbox}}
+			u := usecase.NewTodo(&mockTodo{}, history, memberOf(model.Editor), tx, usecase.NewTodoStream(0))
+
+			err := u.Move(context.Background(), actor, 1, tt.listID)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if tt.wantChanges == nil {
+				if len(history.created) != 0 || len(outbox.stored) != 0 {
+					t.Errorf("want nothing stored, got = %v %v", history.created, outbox.stored)
+				}
+				return
+			}
+			if len(history.created) != 1 || !cmp.Equal(history.created[0].Changes, tt.wantChanges) {
+				t.Fatalf("unexpected history %+v", history.created)
+			}
+			if len(outbox.stored) != 1 {
+				t.Fatalf("want = %v, got = %v", 1, outbox.stored)
+			}
+			if got := outbox.stored[0].(event.TodoUpdated).After.ListID; got != tt.listID {
+				t.Errorf("want = %v, got = %v", tt.listID, got)
+			}
+		})
+	}
+}
+
+func TestTodoHistory(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	// historyOf はページングされた histories を返す
+	historyOf := func(histories []*model.TodoHistory) *mockTodoHistory {
+		return &mockTodoHistory{
+			revisions: histories,
+			mockFindAll: func(limit int, offset int) ([]*model.TodoHistory, error) {
+				if offset >= len(histories) {
+					return nil, nil
+				}
+				end := offset + limit
+				if end > len(histories) {
+					end = len(histories)
+				}
+				return histories[offset:end], nil
+			},
+		}
+	}
+	current := func() (*model.Todo, error) {
+		return &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Done, CreatedAt: start}, nil
+	}
+	missing := func() (*model.Todo, error) { return nil, nil }
+	tests := []struct {
+		name     string
+		limit    int
+		offset   int
+		history  repository.TodoHistory
+		find     func() (*model.Todo, error)
+		member   repository.Member
+		expected []string
+		err      error
+	}{
+		{
+			name:     "正常系_変更履歴が古い順に返ること",
+			limit:    50,
+			history:  historyOf(revisionsAt(start)),
+			member:   memberOf(model.Viewer),
+			expected: []string{"created", "updated", "updated", "deleted"},
+		},
+		{
+			name:     "正常系_削除済みのタスクでも変更履歴を取得できること",
+			limit:    50,
+			history:  historyOf(revisionsAt(start)[3:]),
+			find:     missing,
+			member:   memberOf(model.Viewer),
+			expected: []string{"created", "deleted"},
+		},
+		{
+			name:     "正常系_範囲外のオフセットの場合空で返ること",
+			limit:    50,
+			offset:   50,
+			history:  historyOf(revisionsAt(start)),
+			member:   memberOf(model.Viewer),
+			expected: []string{},
+		},
+		{
+			name:     "正常系_履歴のないタスクは作成の履歴が補われること",
+			limit:    50,
+			history:  historyOf(nil),
+			find:     current,
+			member:   memberOf(model.Viewer),
+			expected: []string{"created"},
+		},
+		{
+			name:     "正常系_作成の履歴がないタスクは補われた履歴がページングされること",
+			limit:    1,
+			offset:   1,
+			history:  historyOf(revisionsAt(start)[2:3]),
+			find:     current,
+			member:   memberOf(model.Viewer),
+			expected: []string{"updated"},
+		},
+		{
+			name:    "異常系_変更履歴がなくタスクも存在しない場合NotFoundになること",
+			limit:   50,
+			history: historyOf(nil),
+			find:    missing,
+			member:  memberOf(model.Viewer),
+			err:     usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewTodo(&mockTodo{mockFind: tt.find}, tt.history, tt.member, &mockTransaction{}, usecase.NewTodoStream(0))
+
+			got, err := u.History(context.Background(), actor, 1, tt.limit, tt.offset)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			actions := []string{}
+			for _, h := range got {
+				actions = append(actions, string(h.Action))
+			}
+			if !cmp.Equal(actions, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(actions, tt.expected))
+			}
+		})
+	}
+}
+
+// revisionsAt は作成・タスク名変更・完了・削除の順に1時間ずつ変更された履歴を返す
+func revisionsAt(start time.Time) []*model.TodoHistory {
+	created := &model.Todo{ID: 1, WorkspaceID: 1, Task: "task", Status: model.Created}
+	renamed := &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Created}
+	done := &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Done}
+	histories := []*model.TodoHistory{
+		model.NewTodoHistory(actor, model.TodoActionCreated, nil, created, start),
+		model.NewTodoHistory(actor, model.TodoActionUpdated, created, renamed, start.Add(time.Hour)),
+		model.NewTodoHistory(actor, model.TodoActionUpdated, renamed, done, start.Add(2*time.Hour)),
+		model.NewTodoHistory(actor, model.TodoActionDeleted, done, nil, start.Add(3*time.Hour)),
+	}
+	for i, h := range histories {
+		h.ID = i + 1
+	}
+	return histories
+}
+
+func TestTodoFindAsOf(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		asOf     time.Time
+		expected *model.Todo
+	}{
+		{
+			name:     "正常系_作成前の場合nilが返ること",
+			asOf:     start.Add(-time.Second),
+			expected: nil,
+		},
+		{
+			name:     "正常系_変更時刻ちょうどの場合その変更が反映されること",
+			asOf:     start.Add(time.Hour),
+			expected: &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Created, CreatedAt: start, UpdatedAt: start.Add(time.Hour)},
+		},
+		{
+			name:     "正常系_変更の間の時刻の場合直前の状態が返ること",
+			asOf:     start.Add(150 * time.Minute),
+			expected: &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Done, CreatedAt: start, UpdatedAt: start.Add(2 * time.Hour)},
+		},
+		{
+			name:     "正常系_削除後の場合nilが返ること",
+			asOf:     start.Add(4 * time.Hour),
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			history := &mockTodoHistory{revisions: revisionsAt(start)}
+			u := usecase.NewTodo(&mockTodo{}, history, memberOf(model.Viewer), &mockTransaction{}, usecase.NewTodoStream(0))
+
+			got, err := u.FindAsOf(context.Background(), actor, 1, tt.asOf)
+			if err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+		})
+	}
+}
+
+func TestTodoFindAsOfWithoutCreated(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		revisions []*model.TodoHistory
+		asOf      time.Time
+		expected  *model.Todo
+	}{
+		{
+			name:     "正常系_履歴のないタスクは作成日時から現在の状態が返ること",
+			asOf:     start.Add(time.Minute),
+			expected: &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Done, CreatedAt: start, UpdatedAt: start},
+		},
+		{
+			name:     "正常系_履歴のないタスクの作成前の場合nilが返ること",
+			asOf:     start.Add(-time.Second),
+			expected: nil,
+		},
+		{
+			name:      "正常系_作成の履歴がない場合変更前の状態が現在から復元されること",
+			revisions: revisionsAt(start)[2:3],
+			asOf:      start.Add(time.Hour),
+			expected:  &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Created, CreatedAt: start, UpdatedAt: start},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			todos := &mockTodo{mockFind: func() (*model.Todo, error) {
+				return &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Done, CreatedAt: start}, nil
+			}}
+			history := &mockTodoHistory{revisions: tt.revisions}
+			u := usecase.NewTodo(todos, history, memberOf(model.Viewer), &mockTransaction{}, usecase.NewTodoStream(0))
+
+			got, err := u.FindAsOf(context.Background(), actor, 1, tt.asOf)
+			if err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+			if !cmp.Equal(got, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got, tt.expected))
+			}
+		})
+	}
+}
+
+func TestTodoDiff(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from     int
+		to       int
+		expected model.FieldChanges
+		err      error
+	}{
+		{
+			name: "正常系_離れたリビジョン間の差分がまとめて返ること",
+			from: 1,
+			to:   3,
+			expected: model.FieldChanges{
+				{Field: "task", Old: "task", New: "renamed"},
+				{Field: "status", Old: "created", New: "done"},
+			},
+		},
+		{
+			name: "正常系_逆順の場合差分も逆になること",
+			from: 3,
+			to:   2,
+			expected: model.FieldChanges{
+				{Field: "status", Old: "done", New: "created"},
+			},
+		},
+		{
+			name: "異常系_タスクのものでないリビジョンの場合NotFoundになること",
+			from: 1,
+			to:   99,
+			err:  usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			history := &mockTodoHistory{revisions: revisionsAt(start)}
+			u := usecase.NewTodo(&mockTodo{}, history, memberOf(model.Viewer), &mockTransaction{}, usecase.NewTodoStream(0))
+
+			got, err := u.Diff(context.Background(), actor, 1, tt.from, tt.to)
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil && !cmp.Equal(got.Changes, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(got.Changes, tt.expected))
+			}
+		})
+	}
+}
+
+func TestTodoRevert(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 削除前の3リビジョンまでの履歴
+	live := func() []*model.TodoHistory { return revisionsAt(start)[:3] }
+	tests := []struct {
+		name         string
+		revisions    []*model.TodoHistory
+		revision     int
+		baseRevision int
+		member       repository.Member
+		expected     *model.Todo
+		err          error
+	}{
+		{
+			name:      "正常系_指定したリビジョンの内容で更新されること",
+			revisions: live(),
+			revision:  1,
+			member:    memberOf(model.Editor),
+			expected:  &model.Todo{ID: 1, WorkspaceID: 1, Task: "task", Status: model.Created},
+		},
+		{
+			name:         "正常系_最新リビジョンを基準にした場合更新されること",
+			revisions:    live(),
+			revision:     2,
+			baseRevision: 3,
+			member:       memberOf(model.Editor),
+			expected:     &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Created},
+		},
+		{
+			name:         "異常系_基準のリビジョンが古い場合Conflictになること",
+			revisions:    live(),
+			revision:     1,
+			baseRevision: 2,
+			member:       memberOf(model.Editor),
+			err:          usecase.ErrConflict,
+		},
+		{
+			name:      "異常系_削除のリビジョンには戻せないこと",
+			revisions: revisionsAt(start),
+			revision:  4,
+			member:    memberOf(model.Editor),
+			err:       usecase.ErrConflict,
+		},
+		{
+			name:      "正常系_作成の履歴がない場合も更新のリビジョンに戻せること",
+			revisions: revisionsAt(start)[1:3],
+			revision:  2,
+			member:    memberOf(model.Editor),
+			expected:  &model.Todo{ID: 1, WorkspaceID: 1, Task: "renamed", Status: model.Created},
+		},
+		{
+			name:      "異常系_閲覧者は戻せないこと",
+			revisions: live(),
+			revision:  1,
+			member:    memberOf(model.Viewer),
+			err:       usecase.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var updated *model.Todo
+			todos := &mockTodo{
+				mockFind: findInWorkspace,
+			}
+			tx := &mockTransaction{repository.Repositories{
+				Todo:        &recordingTodo{mockTodo: todos, updated: &updated},
+				TodoHistory: &mockTodoHistory{revisions: tt.revisions},
+				Outbox:      &mockOutbox{},
+			}}
+			u := usecase.NewTodo(todos, &mockTodoHistory{}, tt.member, tx, usecase.NewTodoStream(0))
+
+			err := u.Revert(context.Background(), actor, 1, tt.revision, tt.baseRevision)
+			if !equalError(err, tt.err) {
+				t.Fatalf("want = %v, got = %v", tt.err, err)
+			}
+			if !cmp.Equal(updated, tt.expected) {
+				t.Errorf("diff %s", cmp.Diff(updated, tt.expected))
+			}
+		})
+	}
+}
+
+// recordingTodo は更新された内容を記録する
+type recordingTodo struct {
+	*mockTodo
+	updated **model.Todo
+}
+
+func (m *recordingTodo) Update(ctx context.Context, t *model.Todo) error {
+	*m.updated = t
+	return nil
+}
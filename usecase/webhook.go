@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	webhookDeliveryBatch = 100
+	// webhookDeliveryLease is how long a claimed delivery is hidden from
+	// other instances. It has to be longer than WEBHOOK_TIMEOUT.
+	webhookDeliveryLease = 10 * time.Minute
+	webhookRetryBase     = 30 * time.Second
+	webhookRetryMax      = time.Hour
+)
+
+type Webhook interface {
+	Create(ctx context.Context, actor model.Actor, url string, events model.WebhookEvents, secret string) (*model.Webhook, error)
+	Update(ctx context.Context, actor model.Actor, id int, url string, events model.WebhookEvents, active bool) error
+	Delete(ctx context.Context, actor model.Actor, id int) error
+	Find(ctx context.Context, actor model.Actor, id int) (*model.Webhook, error)
+	FindAll(ctx context.Context, actor model.Actor) ([]*model.Webhook, error)
+	FindDeliveries(ctx context.Context, actor model.Actor, id int, limit int, offset int) ([]*model.WebhookDelivery, error)
+	SendTest(ctx context.Context, actor model.Actor, id int) (*model.WebhookDelivery, error)
+	// Handle queues deliveries for the todo domain event of the outbox
+	// message with id; it is the handler of the outbox relay.
+	Handle(ctx context.Context, id int, e event.Event) error
+	DeliverDue(ctx context.Context) error
+}
+
+// WebhookPayload is the JSON body posted to webhook receivers.
+type WebhookPayload struct {
+	Event      model.WebhookEvent
+	OccurredAt time.Time
+	Data       interface{}
+}
+
+type webhook struct {
+	webhookRepository  repository.Webhook
+	deliveryRepository repository.WebhookDelivery
+	memberRepository   repository.Member
+	sender             repository.WebhookSender
+	maxAttempts        int
+}
+
+func NewWebhook(w repository.Webhook, d repository.WebhookDelivery, m repository.Member, s repository.WebhookSender, maxAttempts int) Webhook {
+	return &webhook{w, d, m, s, maxAttempts}
+}
+
+func (wh *webhook) Create(ctx context.Context, actor model.Actor, url string, events model.WebhookEvents, secret string) (*model.Webhook, error) {
+	if err := authorize(ctx, wh.memberRepository, actor, model.ManageHooks); err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		s, err := newToken()
+		if err != nil {
+			return nil, err
+		}
+		secret = s
+	}
+	if err := wh.checkURL(ctx, url); err != nil {
+		return nil, err
+	}
+	webhook := model.NewWebhook(actor.WorkspaceID, url, events, secret)
+	if err := wh.webhookRepository.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (wh *webhook) Update(ctx context.Context, actor model.Actor, id int, url string, events model.WebhookEvents, active bool) error {
+	webhook, err := wh.findInWorkspace(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	if err := wh.checkURL(ctx, url); err != nil {
+		return err
+	}
+	webhook.URL = url
+	webhook.Events = events
+	webhook.Active = active
+	if err := wh.webhookRepository.Update(ctx, webhook); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wh *webhook) Delete(ctx context.Context, actor model.Actor, id int) error {
+	if _, err := wh.findInWorkspace(ctx, actor, id); err != nil {
+		return err
+	}
+	if err := wh.webhookRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (wh *webhook) Find(ctx context.Context, actor model.Actor, id int) (*model.Webhook, error) {
+	return wh.findInWorkspace(ctx, actor, id)
+}
+
+func (wh *webhook) FindAll(ctx context.Context, actor model.Actor) ([]*model.Webhook, error) {
+	if err := authorize(ctx, wh.memberRepository, actor, model.ManageHooks); err != nil {
+		return nil, err
+	}
+	webhooks, err := wh.webhookRepository.FindAll(ctx, actor.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (wh *webhook) FindDeliveries(ctx context.Context, actor model.Actor, id int, limit int, offset int) ([]*model.WebhookDelivery, error) {
+	if _, err := wh.findInWorkspace(ctx, actor, id); err != nil {
+		return nil, err
+	}
+	deliveries, err := wh.deliveryRepository.FindAll(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (wh *webhook) SendTest(ctx context.Context, actor model.Actor, id int) (*model.WebhookDelivery, error) {
+	webhook, err := wh.findInWorkspace(ctx, actor, id)
+	if err != nil {
+		return nil, err
+	}
+	return wh.enqueue(ctx, webhook, model.Ping, nil, map[string]int{"WebhookID": webhook.ID}, time.Now())
+}
+
+var webhookEvents = map[string]model.WebhookEvent{
+	event.TodoCreatedName:       model.TodoCreatedEvent,
+	event.TodoUpdatedName:       model.TodoUpdatedEvent,
+	event.TodoStatusChangedName: model.TodoStatusChangedEvent,
+	event.TodoDeletedName:       model.TodoDeletedEvent,
+}
+
+// Handle stores a pending delivery for every active webhook of the event's
+// workspace that subscribes to it. The event itself is sent as the data.
+// A webhook that already has a delivery of the message is skipped, so a
+// message relayed again is not sent twice.
+func (wh *webhook) Handle(ctx context.Context, id int, e event.Event) error {
+	webhookEvent, ok := webhookEvents[e.Name()]
+	if !ok {
+		return nil
+	}
+	webhooks, err := wh.webhookRepository.FindAll(ctx, e.WorkspaceID())
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(webhookEvent) {
+			continue
+		}
+		_, err := wh.enqueue(ctx, webhook, webhookEvent, &id, e, e.OccurredAt())
+		if err != nil && !errors.Is(err, repository.ErrDuplicate) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue sends the deliveries whose next attempt is due. Failed attempts
+// are retried with exponential backoff until maxAttempts, after which the
+// delivery is dead-lettered. Deliveries are claimed one at a time so that
+// each is signed with the time it is actually sent.
+func (wh *webhook) DeliverDue(ctx context.Context) error {
+	for i := 0; i < webhookDeliveryBatch; i++ {
+		now := time.Now()
+		d, err := wh.deliveryRepository.ClaimDue(ctx, now, now.Add(webhookDeliveryLease))
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return nil
+		}
+		// The webhook is read again for every attempt, so that a delivery
+		// queued before it was deactivated or deleted is not sent.
+		webhook, err := wh.webhookRepository.Find(ctx, d.WebhookID)
+		if err != nil {
+			return err
+		}
+		wh.attempt(ctx, webhook, d)
+		if err := wh.deliveryRepository.Update(ctx, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wh *webhook) attempt(ctx context.Context, webhook *model.Webhook, d *model.WebhookDelivery) {
+	d.Attempts++
+	if webhook == nil {
+		d.Status = model.DeliveryDead
+		d.LastError = "webhook was deleted"
+		return
+	}
+	if !webhook.Active {
+		d.Status = model.DeliveryDead
+		d.LastError = "webhook is inactive"
+		return
+	}
+	code, err := wh.sender.Send(ctx, webhook, d, time.Now())
+	d.LastStatusCode = code
+	d.LastError = ""
+	switch {
+	case err != nil:
+		d.LastError = err.Error()
+	case code < 200 || code >= 300:
+		d.LastError = fmt.Sprintf("unexpected status code %d", code)
+	default:
+		d.Status = model.DeliverySucceeded
+		return
+	}
+	if d.Attempts >= wh.maxAttempts {
+		d.Status = model.DeliveryDead
+		return
+	}
+	d.NextAttemptAt = time.Now().Add(retryDelay(d.Attempts))
+}
+
+func (wh *webhook) enqueue(ctx context.Context, webhook *model.Webhook, event model.WebhookEvent, eventID *int, data interface{}, now time.Time) (*model.WebhookDelivery, error) {
+	payload, err := json.Marshal(WebhookPayload{Event: event, OccurredAt: now, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	delivery := model.NewWebhookDelivery(webhook.ID, event, payload, now)
+	delivery.EventID = eventID
+	if err := wh.deliveryRepository.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (wh *webhook) checkURL(ctx context.Context, url string) error {
+	if err := wh.sender.Check(ctx, url); err != nil {
+		return fmt.Errorf("%w: %s", ErrWebhookURL, err.Error())
+	}
+	return nil
+}
+
+func (wh *webhook) findInWorkspace(ctx context.Context, actor model.Actor, id int) (*model.Webhook, error) {
+	if err := authorize(ctx, wh.memberRepository, actor, model.ManageHooks); err != nil {
+		return nil, err
+	}
+	webhook, err := wh.webhookRepository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil || webhook.WorkspaceID != actor.WorkspaceID {
+		return nil, ErrNotFound
+	}
+	return webhook, nil
+}
+
+func retryDelay(attempts int) time.Duration {
+	d := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return d
+}
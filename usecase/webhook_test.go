@@ -0,0 +1,351 @@
+package usecase_test
+
+import (
+	"app/domain/event"
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type mockWebhook struct {
+	repository.Webhook
+	mockFind    func() (*model.Webhook, error)
+	mockFindAll func() ([]*model.Webhook, error)
+	mockCreate  func() error
+}
+
+func (m *mockWebhook) Create(ctx context.Context, w *model.Webhook) error {
+	return m.mockCreate()
+}
+func (m *mockWebhook) Find(ctx context.Context, id int) (*model.Webhook, error) {
+	return m.mockFind()
+}
+func (m *mockWebhook) FindAll(ctx context.Context, workspaceID int) ([]*model.Webhook, error) {
+	return m.mockFindAll()
+}
+
+// mockWebhookDelivery は登録・更新された配信を記録し、同じWebhookとイベントIDの配信の登録をErrDuplicateにする
+type mockWebhookDelivery struct {
+	repository.WebhookDelivery
+	due     []*model.WebhookDelivery
+	created []*model.WebhookDelivery
+	updated []*model.WebhookDelivery
+}
+
+func (m *mockWebhookDelivery) Create(ctx context.Context, d *model.WebhookDelivery) error {
+	for _, c := range m.created {
+		if c.WebhookID == d.WebhookID && c.EventID != nil && d.EventID != nil && *c.EventID == *d.EventID {
+			return repository.ErrDuplicate
+		}
+	}
+	m.created = append(m.created, d)
+	return nil
+}
+func (m *mockWebhookDelivery) Update(ctx context.Context, d *model.WebhookDelivery) error {
+	m.updated = append(m.updated, d)
+	return nil
+}
+func (m *mockWebhookDelivery) ClaimDue(ctx context.Context, now time.Time, until time.Time) (*model.WebhookDelivery, error) {
+	if len(m.due) == 0 {
+		return nil, nil
+	}
+	d := m.due[0]
+	m.due = m.due[1:]
+	return d, nil
+}
+
+// mockWebhookSender は送信時刻を記録する
+type mockWebhookSender struct {
+	code     int
+	err      error
+	checkErr error
+	delay    time.Duration
+	sentAt   []time.Time
+}
+
+func (m *mockWebhookSender) Check(ctx context.Context, url string) error {
+	return m.checkErr
+}
+func (m *mockWebhookSender) Send(ctx context.Context, w *model.Webhook, d *model.WebhookDelivery, now time.Time) (int, error) {
+	m.sentAt = append(m.sentAt, now)
+	time.Sleep(m.delay)
+	return m.code, m.err
+}
+
+func findWebhook() (*model.Webhook, error) {
+	return model.NewWebhook(actor.WorkspaceID, "https://example.com/hook", model.WebhookEvents{model.TodoCreatedEvent}, "secret"), nil
+}
+
+func TestWebhookCreate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		secret   string
+		member   repository.Member
+		checkErr error
+		err      error
+	}{
+		{
+			name:   "正常系_シークレットを省略した場合生成されること",
+			member: memberOf(model.Admin),
+			err:    nil,
+		},
+		{
+			name:   "正常系_指定したシークレットが使われること",
+			secret: "0123456789abcdef",
+			member: memberOf(model.Owner),
+			err:    nil,
+		},
+		{
+			name:   "異常系_編集者はWebhookを登録できないこと",
+			member: memberOf(model.Editor),
+			err:    usecase.ErrForbidden,
+		},
+		{
+			name:     "異常系_送信先として許可されないURLは登録できないこと",
+			member:   memberOf(model.Admin),
+			checkErr: errors.New("resolves to 127.0.0.1"),
+			err:      fmt.Errorf("%w: resolves to 127.0.0.1", usecase.ErrWebhookURL),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewWebhook(&mockWebhook{
+				mockCreate: func() error { return nil },
+			}, &mockWebhookDelivery{}, tt.member, &mockWebhookSender{checkErr: tt.checkErr}, 3)
+
+			got, err := u.Create(context.Background(), actor, "https://example.com/hook", model.WebhookEvents{model.TodoCreatedEvent}, tt.secret)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.Secret == "" || tt.secret != "" && got.Secret != tt.secret {
+				t.Errorf("unexpected secret: %q", got.Secret)
+			}
+		})
+	}
+}
+
+func TestWebhookHandle(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_購読している有効なWebhookにのみ配信が登録されること", func(t *testing.T) {
+		inactive := model.NewWebhook(1, "https://example.com/c", model.WebhookEvents{model.TodoCreatedEvent}, "secret")
+		inactive.Active = false
+		deliveries := &mockWebhookDelivery{}
+		u := usecase.NewWebhook(&mockWebhook{
+			mockFindAll: func() ([]*model.Webhook, error) {
+				return []*model.Webhook{
+					{ID: 1, WorkspaceID: 1, Events: model.WebhookEvents{model.TodoCreatedEvent}, Active: true},
+					{ID: 2, WorkspaceID: 1, Events: model.WebhookEvents{model.TodoDeletedEvent}, Active: true},
+					inactive,
+				}, nil
+			},
+		}, deliveries, memberOf(model.Owner), &mockWebhookSender{}, 3)
+
+		if err := u.Handle(context.Background(), 7, event.TodoCreated{Todo: model.Todo{ID: 1, WorkspaceID: 1, Task: "task"}}); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(deliveries.created) != 1 || deliveries.created[0].WebhookID != 1 || *deliveries.created[0].EventID != 7 {
+			t.Errorf("unexpected deliveries: %+v", deliveries.created)
+		}
+	})
+	t.Run("正常系_同じイベントを再度処理しても配信が重複して登録されないこと", func(t *testing.T) {
+		deliveries := &mockWebhookDelivery{}
+		u := usecase.NewWebhook(&mockWebhook{
+			mockFindAll: func() ([]*model.Webhook, error) {
+				return []*model.Webhook{
+					{ID: 1, WorkspaceID: 1, Events: model.WebhookEvents{model.TodoCreatedEvent}, Active: true},
+					{ID: 2, WorkspaceID: 1, Events: model.WebhookEvents{model.TodoCreatedEvent}, Active: true},
+				}, nil
+			},
+		}, deliveries, memberOf(model.Owner), &mockWebhookSender{}, 3)
+
+		e := event.TodoCreated{Todo: model.Todo{ID: 1, WorkspaceID: 1, Task: "task"}}
+		for i := 0; i < 2; i++ {
+			if err := u.Handle(context.Background(), 7, e); err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+		}
+		if len(deliveries.created) != 2 {
+			t.Errorf("want = %v, got = %+v", 2, deliveries.created)
+		}
+	})
+}
+
+func TestWebhookDeliverDue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		attempts      int
+		sender        *mockWebhookSender
+		find          func() (*model.Webhook, error)
+		want_status   model.DeliveryStatus
+		want_attempts int
+		want_retry    bool
+	}{
+		{
+			name:          "正常系_2xxの場合配信済みになること",
+			sender:        &mockWebhookSender{code: http.StatusOK},
+			find:          findWebhook,
+			want_status:   model.DeliverySucceeded,
+			want_attempts: 1,
+		},
+		{
+			name:          "異常系_2xx以外の場合再送が予約されること",
+			sender:        &mockWebhookSender{code: http.StatusInternalServerError},
+			find:          findWebhook,
+			want_status:   model.DeliveryPending,
+			want_attempts: 1,
+			want_retry:    true,
+		},
+		{
+			name:          "異常系_送信エラーで上限に達した場合デッドレターになること",
+			attempts:      2,
+			sender:        &mockWebhookSender{err: errors.New("connection refused")},
+			find:          findWebhook,
+			want_status:   model.DeliveryDead,
+			want_attempts: 3,
+		},
+		{
+			name:          "異常系_Webhookが削除されている場合デッドレターになること",
+			sender:        &mockWebhookSender{code: http.StatusOK},
+			find:          func() (*model.Webhook, error) { return nil, nil },
+			want_status:   model.DeliveryDead,
+			want_attempts: 1,
+		},
+		{
+			name:   "異常系_Webhookが無効化されている場合送信せずデッドレターになること",
+			sender: &mockWebhookSender{code: http.StatusOK},
+			find: func() (*model.Webhook, error) {
+				w, _ := findWebhook()
+				w.Active = false
+				return w, nil
+			},
+			want_status:   model.DeliveryDead,
+			want_attempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			before := time.Now()
+			d := model.NewWebhookDelivery(1, model.TodoCreatedEvent, []byte(`{}`), before)
+			d.Attempts = tt.attempts
+			deliveries := &mockWebhookDelivery{due: []*model.WebhookDelivery{d}}
+			u := usecase.NewWebhook(&mockWebhook{mockFind: tt.find}, deliveries, memberOf(model.Owner), tt.sender, 3)
+
+			if err := u.DeliverDue(context.Background()); err != nil {
+				t.Fatalf("want = %v, got = %v", nil, err)
+			}
+			if len(deliveries.updated) != 1 {
+				t.Fatalf("want the delivery updated, got = %+v", deliveries.updated)
+			}
+			if d.Status != tt.want_status || d.Attempts != tt.want_attempts {
+				t.Errorf("want = %v %v, got = %v %v", tt.want_status, tt.want_attempts, d.Status, d.Attempts)
+			}
+			if retried := d.NextAttemptAt.After(before.Add(time.Second)); retried != tt.want_retry {
+				t.Errorf("want retry = %v, got next attempt at %v", tt.want_retry, d.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliverDueDeactivated(t *testing.T) {
+	t.Parallel()
+	t.Run("異常系_配信の途中で無効化されたWebhookには以降の配信が送信されないこと", func(t *testing.T) {
+		first := model.NewWebhookDelivery(1, model.TodoCreatedEvent, []byte(`{}`), time.Now())
+		second := model.NewWebhookDelivery(1, model.TodoCreatedEvent, []byte(`{}`), time.Now())
+		deliveries := &mockWebhookDelivery{due: []*model.WebhookDelivery{first, second}}
+		// 1件目の配信の後にWebhookが無効化される
+		finds := 0
+		find := func() (*model.Webhook, error) {
+			finds++
+			w, _ := findWebhook()
+			w.Active = finds == 1
+			return w, nil
+		}
+		sender := &mockWebhookSender{code: http.StatusOK}
+		u := usecase.NewWebhook(&mockWebhook{mockFind: find}, deliveries, memberOf(model.Owner), sender, 3)
+
+		if err := u.DeliverDue(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(sender.sentAt) != 1 {
+			t.Errorf("want = %v, got = %v", 1, len(sender.sentAt))
+		}
+		if first.Status != model.DeliverySucceeded || second.Status != model.DeliveryDead {
+			t.Errorf("want = %v %v, got = %v %v", model.DeliverySucceeded, model.DeliveryDead, first.Status, second.Status)
+		}
+	})
+}
+
+func TestWebhookDeliverDueTimestamp(t *testing.T) {
+	t.Parallel()
+	t.Run("正常系_配信ごとに送信時の時刻で署名されること", func(t *testing.T) {
+		deliveries := &mockWebhookDelivery{due: []*model.WebhookDelivery{
+			model.NewWebhookDelivery(1, model.TodoCreatedEvent, []byte(`{}`), time.Now()),
+			model.NewWebhookDelivery(1, model.TodoCreatedEvent, []byte(`{}`), time.Now()),
+		}}
+		sender := &mockWebhookSender{code: http.StatusOK, delay: 20 * time.Millisecond}
+		u := usecase.NewWebhook(&mockWebhook{mockFind: findWebhook}, deliveries, memberOf(model.Owner), sender, 3)
+
+		if err := u.DeliverDue(context.Background()); err != nil {
+			t.Fatalf("want = %v, got = %v", nil, err)
+		}
+		if len(sender.sentAt) != 2 {
+			t.Fatalf("want = %v, got = %v", 2, len(sender.sentAt))
+		}
+		if d := sender.sentAt[1].Sub(sender.sentAt[0]); d < sender.delay {
+			t.Errorf("want the second delivery signed after the first was sent, got %v apart", d)
+		}
+	})
+}
+
+func TestWebhookSendTest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		find func() (*model.Webhook, error)
+		err  error
+	}{
+		{
+			name: "正常系_テストイベントの配信が登録されること",
+			find: findWebhook,
+			err:  nil,
+		},
+		{
+			name: "異常系_他のワークスペースのWebhookには送信できないこと",
+			find: func() (*model.Webhook, error) {
+				return model.NewWebhook(2, "https://example.com/hook", nil, "secret"), nil
+			},
+			err: usecase.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			deliveries := &mockWebhookDelivery{}
+			u := usecase.NewWebhook(&mockWebhook{mockFind: tt.find}, deliveries, memberOf(model.Admin), &mockWebhookSender{}, 3)
+
+			got, err := u.SendTest(context.Background(), actor, 1)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+			if err == nil && got.Event != model.Ping {
+				t.Errorf("want = %v, got = %v", model.Ping, got.Event)
+			}
+		})
+	}
+}
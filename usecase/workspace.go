@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"context"
+)
+
+type Workspace interface {
+	Create(ctx context.Context, userID int, name string) (*model.Workspace, error)
+	FindMembers(ctx context.Context, actor model.Actor) ([]*model.Member, error)
+	UpdateMember(ctx context.Context, actor model.Actor, userID int, role model.Role) error
+	DeleteMember(ctx context.Context, actor model.Actor, userID int) error
+}
+
+type workspace struct {
+	workspaceRepository repository.Workspace
+	memberRepository    repository.Member
+	transaction         repository.Transaction
+}
+
+func NewWorkspace(w repository.Workspace, m repository.Member, tx repository.Transaction) Workspace {
+	return &workspace{w, m, tx}
+}
+
+// Create adds the workspace and its owner atomically so that a workspace
+// never exists without an owner.
+func (w *workspace) Create(ctx context.Context, userID int, name string) (*model.Workspace, error) {
+	workspace := model.NewWorkspace(name)
+	err := w.transaction.Do(ctx, func(r repository.Repositories) error {
+		if err := r.Workspace.Create(ctx, workspace); err != nil {
+			return err
+		}
+		return r.Member.Create(ctx, model.NewMember(workspace.ID, userID, model.Owner))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func (w *workspace) FindMembers(ctx context.Context, actor model.Actor) ([]*model.Member, error) {
+	if err := authorize(ctx, w.memberRepository, actor, model.ReadTodo); err != nil {
+		return nil, err
+	}
+	members, err := w.memberRepository.FindAll(ctx, actor.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (w *workspace) UpdateMember(ctx context.Context, actor model.Actor, userID int, role model.Role) error {
+	operator, err := authorizeMember(ctx, w.memberRepository, actor, model.ManageMembers)
+	if err != nil {
+		return err
+	}
+	target, err := w.memberRepository.Find(ctx, actor.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotFound
+	}
+	if (target.Role == model.Owner || role == model.Owner) && !operator.Role.Can(model.ManageOwners) {
+		return ErrForbidden
+	}
+	if target.Role == model.Owner && role != model.Owner {
+		if err := w.ensureAnotherOwner(ctx, actor.WorkspaceID, userID); err != nil {
+			return err
+		}
+	}
+	target.Role = role
+	if err := w.memberRepository.Update(ctx, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *workspace) DeleteMember(ctx context.Context, actor model.Actor, userID int) error {
+	operator, err := authorizeMember(ctx, w.memberRepository, actor, model.ReadTodo)
+	if err != nil {
+		return err
+	}
+	target, err := w.memberRepository.Find(ctx, actor.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return ErrNotFound
+	}
+	// 自分自身の脱退はロールに関係なく許可する
+	if userID != actor.UserID {
+		if !operator.Role.Can(model.ManageMembers) {
+			return ErrForbidden
+		}
+		if target.Role == model.Owner && !operator.Role.Can(model.ManageOwners) {
+			return ErrForbidden
+		}
+	}
+	if target.Role == model.Owner {
+		if err := w.ensureAnotherOwner(ctx, actor.WorkspaceID, userID); err != nil {
+			return err
+		}
+	}
+	if err := w.memberRepository.Delete(ctx, actor.WorkspaceID, userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ensureAnotherOwner keeps every workspace with at least one owner.
+func (w *workspace) ensureAnotherOwner(ctx context.Context, workspaceID int, userID int) error {
+	members, err := w.memberRepository.FindAll(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if m.Role == model.Owner && m.UserID != userID {
+			return nil
+		}
+	}
+	return ErrConflict
+}
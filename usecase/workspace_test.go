@@ -0,0 +1,225 @@
+package usecase_test
+
+import (
+	"app/domain/model"
+	"app/domain/repository"
+	"app/usecase"
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockWorkspace struct {
+	repository.Workspace
+	mockCreate func(w *model.Workspace) error
+}
+
+func (m *mockWorkspace) Create(ctx context.Context, w *model.Workspace) error {
+	return m.mockCreate(w)
+}
+
+func TestWorkspaceCreate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		repository repository.Workspace
+		member     repository.Member
+		err        error
+	}{
+		{
+			name: "正常系_ワークスペースの登録ができ作成者がオーナーになること",
+			repository: &mockWorkspace{
+				mockCreate: func(w *model.Workspace) error {
+					w.ID = 1
+					return nil
+				},
+			},
+			member: &mockMember{
+				mockCreate: func() error {
+					return nil
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "異常系_ワークスペースの登録に失敗した場合エラーが返ること",
+			repository: &mockWorkspace{
+				mockCreate: func(w *model.Workspace) error {
+					return errors.New("xxxx error")
+				},
+			},
+			err: errors.New("xxxx error"),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewWorkspace(tt.repository, tt.member, &mockTransaction{repository.Repositories{Workspace: tt.repository, Member: tt.member}})
+
+			_, err := u.Create(context.Background(), 1, "workspace")
+			if !equalError(err, tt.err) {
+				t.Errorf("different than expected...")
+			}
+		})
+	}
+}
+
+func TestWorkspaceUpdateMember(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		actor  model.Actor
+		userID int
+		role   model.Role
+		member repository.Member
+		err    error
+	}{
+		{
+			name:   "異常系_閲覧者はメンバーのロールを変更できないこと",
+			actor:  model.NewActor(3, 1),
+			userID: 2,
+			role:   model.Viewer,
+			member: memberOf(model.Viewer),
+			err:    usecase.ErrForbidden,
+		},
+		{
+			name:   "異常系_管理者はオーナーのロールを付与できないこと",
+			actor:  model.NewActor(2, 1),
+			userID: 2,
+			role:   model.Owner,
+			member: memberOf(model.Admin),
+			err:    usecase.ErrForbidden,
+		},
+		{
+			name:   "異常系_最後のオーナーは降格できないこと",
+			actor:  model.NewActor(1, 1),
+			userID: 1,
+			role:   model.Admin,
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return model.NewMember(1, 1, model.Owner), nil
+				},
+				mockFindAll: func() ([]*model.Member, error) {
+					return []*model.Member{model.NewMember(1, 1, model.Owner)}, nil
+				},
+			},
+			err: usecase.ErrConflict,
+		},
+		{
+			name:   "異常系_存在しないメンバーは更新できないこと",
+			actor:  model.NewActor(1, 1),
+			userID: 9,
+			role:   model.Editor,
+			member: &mockMember{
+				mockFind: func() func() (*model.Member, error) {
+					calls := 0
+					return func() (*model.Member, error) {
+						calls++
+						if calls == 1 {
+							return model.NewMember(1, 1, model.Owner), nil
+						}
+						return nil, nil
+					}
+				}(),
+			},
+			err: usecase.ErrNotFound,
+		},
+		{
+			name:   "正常系_オーナーはメンバーのロールを変更できること",
+			actor:  model.NewActor(1, 1),
+			userID: 3,
+			role:   model.Editor,
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return model.NewMember(1, 1, model.Owner), nil
+				},
+				mockUpdate: func() error {
+					return nil
+				},
+				mockFindAll: func() ([]*model.Member, error) {
+					return []*model.Member{
+						model.NewMember(1, 1, model.Owner),
+						model.NewMember(1, 4, model.Owner),
+					}, nil
+				},
+			},
+			err: nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewWorkspace(&mockWorkspace{}, tt.member, &mockTransaction{})
+
+			err := u.UpdateMember(context.Background(), tt.actor, tt.userID, tt.role)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestWorkspaceDeleteMember(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		actor  model.Actor
+		userID int
+		member repository.Member
+		err    error
+	}{
+		{
+			name:   "正常系_自分自身はロールに関係なく脱退できること",
+			actor:  model.NewActor(3, 1),
+			userID: 3,
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return model.NewMember(1, 3, model.Viewer), nil
+				},
+				mockDelete: func() error {
+					return nil
+				},
+			},
+			err: nil,
+		},
+		{
+			name:   "異常系_編集者は他のメンバーを削除できないこと",
+			actor:  model.NewActor(3, 1),
+			userID: 4,
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return model.NewMember(1, 3, model.Editor), nil
+				},
+			},
+			err: usecase.ErrForbidden,
+		},
+		{
+			name:   "異常系_最後のオーナーは脱退できないこと",
+			actor:  model.NewActor(1, 1),
+			userID: 1,
+			member: &mockMember{
+				mockFind: func() (*model.Member, error) {
+					return model.NewMember(1, 1, model.Owner), nil
+				},
+				mockFindAll: func() ([]*model.Member, error) {
+					return []*model.Member{model.NewMember(1, 1, model.Owner)}, nil
+				},
+			},
+			err: usecase.ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			u := usecase.NewWorkspace(&mockWorkspace{}, tt.member, &mockTransaction{})
+
+			err := u.DeleteMember(context.Background(), tt.actor, tt.userID)
+			if !equalError(err, tt.err) {
+				t.Errorf("want = %v, got = %v", tt.err, err)
+			}
+		})
+	}
+}